@@ -0,0 +1,45 @@
+package activity
+
+import (
+	"sync"
+	"time"
+)
+
+// Activity remembers when each user last said anything in each channel
+type Activity interface {
+	Seen(channel, id string, at time.Time)
+	LastSeen(channel, id string) time.Time
+}
+
+// activity contains a mutex controlled time of last activity keyed on channel and then user
+type activity struct {
+	Mux     sync.Mutex
+	Channel map[string]map[string]time.Time
+}
+
+// New creates an instance of Activity that has not seen anybody yet
+func New() Activity {
+	return &activity{Channel: map[string]map[string]time.Time{}}
+}
+
+// Seen records that a user said something in a channel at a time, unless they have been seen more recently
+func (a *activity) Seen(channel, id string, at time.Time) {
+	a.Mux.Lock()
+	users, ok := a.Channel[channel]
+	if !ok {
+		users = map[string]time.Time{}
+		a.Channel[channel] = users
+	}
+	if at.After(users[id]) {
+		users[id] = at
+	}
+	a.Mux.Unlock()
+}
+
+// LastSeen returns when a user last said something in a channel, or the zero time if they have not been seen
+func (a *activity) LastSeen(channel, id string) (at time.Time) {
+	a.Mux.Lock()
+	at = a.Channel[channel][id]
+	a.Mux.Unlock()
+	return
+}
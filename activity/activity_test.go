@@ -0,0 +1,40 @@
+package activity_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/doozr/qbot/activity"
+)
+
+var now = time.Date(2017, 3, 14, 12, 0, 0, 0, time.UTC)
+
+func TestLastSeenIsZeroForUnknownUser(t *testing.T) {
+	a := New()
+	if !a.LastSeen("C1234", "U1234").IsZero() {
+		t.Fatal("Expected zero time, got ", a.LastSeen("C1234", "U1234"))
+	}
+}
+
+func TestSeenIsPerChannel(t *testing.T) {
+	a := New()
+	a.Seen("C1234", "U1234", now)
+
+	if !a.LastSeen("C1234", "U1234").Equal(now) {
+		t.Fatal("Expected user to be seen, got ", a.LastSeen("C1234", "U1234"))
+	}
+
+	if !a.LastSeen("C5678", "U1234").IsZero() {
+		t.Fatal("Expected user not to be seen in other channel, got ", a.LastSeen("C5678", "U1234"))
+	}
+}
+
+func TestSeenKeepsMostRecent(t *testing.T) {
+	a := New()
+	a.Seen("C1234", "U1234", now)
+	a.Seen("C1234", "U1234", now.Add(-time.Minute))
+
+	if !a.LastSeen("C1234", "U1234").Equal(now) {
+		t.Fatal("Expected most recent time, got ", a.LastSeen("C1234", "U1234"))
+	}
+}
@@ -0,0 +1,289 @@
+package qbot
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/doozr/guac"
+	"github.com/doozr/qbot/queue"
+	"github.com/doozr/qbot/usercache"
+)
+
+// apiEntry is an entry in a queue as the API gives it.
+type apiEntry struct {
+	Position    int        `json:"position"`
+	Key         string     `json:"key"`
+	User        string     `json:"user"`
+	Name        string     `json:"name"`
+	Reason      string     `json:"reason"`
+	Holding     bool       `json:"holding"`
+	JoinedAt    *time.Time `json:"joined_at,omitempty"`
+	ActiveSince *time.Time `json:"active_since,omitempty"`
+}
+
+// apiToken is a token and its queue as the API gives it.
+type apiToken struct {
+	Name     string     `json:"name"`
+	Capacity int        `json:"capacity"`
+	Entries  []apiEntry `json:"entries"`
+}
+
+// apiChannel is the tokens of a channel as the API gives them.
+type apiChannel struct {
+	Channel string     `json:"channel"`
+	Tokens  []apiToken `json:"tokens"`
+}
+
+// apiJoin is a request to join a queue.
+type apiJoin struct {
+	User   string `json:"user"`
+	Reason string `json:"reason"`
+	Token  string `json:"token"`
+}
+
+// apiJoined is the result of joining a queue.
+type apiJoined struct {
+	Channel string   `json:"channel"`
+	Entry   apiEntry `json:"entry"`
+	Token   apiToken `json:"token"`
+}
+
+// apiError is an error as the API gives it.
+type apiError struct {
+	Error string `json:"error"`
+}
+
+// timeOrNil gives nil for an unknown time, so that it is left out.
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// describeEntry turns an entry into what the API gives.
+func describeEntry(userCache usercache.UserCache, i queue.Item, position int, holding bool) apiEntry {
+	return apiEntry{
+		Position:    position,
+		Key:         i.Key,
+		User:        i.ID,
+		Name:        userCache.GetUserName(i.ID),
+		Reason:      i.Reason,
+		Holding:     holding,
+		JoinedAt:    timeOrNil(i.JoinedAt),
+		ActiveSince: timeOrNil(i.ActiveSince),
+	}
+}
+
+// describeToken turns a token and its queue into what the API gives.
+func describeToken(userCache usercache.UserCache, t queue.Tokens, name string) apiToken {
+	capacity := t.Capacity(name)
+	q := t.Get(name)
+	entries := []apiEntry{}
+	for ix, i := range q {
+		entries = append(entries, describeEntry(userCache, i, ix+1, q.Holds(i, capacity)))
+	}
+	return apiToken{Name: name, Capacity: capacity, Entries: entries}
+}
+
+// describeChannel turns the tokens of a channel into what the API gives.
+func describeChannel(userCache usercache.UserCache, ch string, t queue.Tokens) apiChannel {
+	tokens := []apiToken{}
+	for _, name := range t.Names() {
+		tokens = append(tokens, describeToken(userCache, t, name))
+	}
+	return apiChannel{Channel: ch, Tokens: tokens}
+}
+
+// writeJSON answers a request with a value as JSON.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Error writing API response: %s", err)
+	}
+}
+
+// writeError answers a request with an error as JSON.
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, apiError{message})
+}
+
+// runJob hands a job to the dispatcher and waits for it to be done.
+//
+// The request is answered with an error if the job cannot be done, in which case false is returned.
+func runJob(w http.ResponseWriter, r *http.Request, jobs chan<- Job, done DoneChan, job Job) bool {
+	finished := make(chan struct{})
+	wrapped := func(qs queue.Channels) (queue.Channels, error) {
+		defer close(finished)
+		return job(qs)
+	}
+
+	select {
+	case jobs <- wrapped:
+	case <-done:
+		writeError(w, http.StatusServiceUnavailable, "shutting down")
+		return false
+	case <-r.Context().Done():
+		return false
+	}
+
+	select {
+	case <-finished:
+		return true
+	case <-done:
+		writeError(w, http.StatusServiceUnavailable, "shutting down")
+		return false
+	}
+}
+
+// authorised finds the name of the client that an API token in the request belongs to.
+func authorised(tokens map[string]string, r *http.Request) (client string, ok bool) {
+	given := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if given == "" {
+		return
+	}
+	for name, token := range tokens {
+		if subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1 {
+			return name, true
+		}
+	}
+	return
+}
+
+// CreateAPIHandler creates an HTTP handler that gives the queues as JSON and lets clients join them.
+//
+// `GET /queues` gives every channel and `GET /queues/{channel}` gives one. `POST /queues/{channel}/join` joins a user
+// to the queue exactly as if they had said `join` in the channel, and needs one of the API tokens, keyed on the name
+// of the client, as a bearer token. A channel with nobody in its queue has an empty default token, like any other.
+// Every call is handed to the dispatcher as a job, so that it sees and changes the queues in turn with messages from
+// Slack.
+func CreateAPIHandler(tokens map[string]string, userCache usercache.UserCache, handleMessage MessageHandler,
+	jobs chan<- Job, done DoneChan) http.Handler {
+
+	list := func(w http.ResponseWriter, r *http.Request) {
+		var channels []apiChannel
+		ok := runJob(w, r, jobs, done, func(qs queue.Channels) (queue.Channels, error) {
+			channels = []apiChannel{}
+			for ch, t := range qs {
+				channels = append(channels, describeChannel(userCache, ch, t))
+			}
+			return qs, nil
+		})
+		if ok {
+			sort.Slice(channels, func(a, b int) bool { return channels[a].Channel < channels[b].Channel })
+			writeJSON(w, http.StatusOK, channels)
+		}
+	}
+
+	show := func(w http.ResponseWriter, r *http.Request, ch string) {
+		var channel apiChannel
+		ok := runJob(w, r, jobs, done, func(qs queue.Channels) (queue.Channels, error) {
+			channel = describeChannel(userCache, ch, qs.Get(ch))
+			return qs, nil
+		})
+		if ok {
+			writeJSON(w, http.StatusOK, channel)
+		}
+	}
+
+	join := func(w http.ResponseWriter, r *http.Request, ch string) {
+		client, ok := authorised(tokens, r)
+		if !ok {
+			writeError(w, http.StatusUnauthorized, "a valid API token is needed")
+			return
+		}
+
+		body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+		var req apiJoin
+		if err == nil {
+			err = json.Unmarshal(body, &req)
+		}
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "could not read request")
+			return
+		}
+
+		text, err := Webhook{Event: "join", Token: req.Token, Reason: req.Reason}.command()
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		user := findUser(userCache, req.User)
+		if user == "" {
+			writeError(w, http.StatusBadRequest, "no such user "+req.User)
+			return
+		}
+
+		name := strings.ToLower(req.Token)
+		if name == "" {
+			name = queue.DefaultToken
+		}
+
+		var result apiJoined
+		status := http.StatusOK
+		ok = runJob(w, r, jobs, done, func(qs queue.Channels) (queue.Channels, error) {
+			if !qs.Get(ch).Exists(name) {
+				status = http.StatusNotFound
+				return qs, nil
+			}
+
+			log.Printf("API join in %s by %s for %s: %s", ch, client, user, text)
+			nqs, err := handleMessage(qs, guac.MessageEvent{Type: "message", Channel: ch, User: user, Text: text})
+			result = apiJoined{Channel: ch, Token: describeToken(userCache, nqs.Get(ch), name)}
+			status = http.StatusConflict
+			before := qs.Get(ch).Get(name)
+			for ix, i := range nqs.Get(ch).Get(name) {
+				if i.ID == user && !before.Contains(i) {
+					result.Entry = result.Token.Entries[ix]
+					status = http.StatusOK
+				}
+			}
+			return nqs, err
+		})
+		if !ok {
+			return
+		}
+
+		switch status {
+		case http.StatusNotFound:
+			writeError(w, status, "no "+name+" token in "+ch)
+		case http.StatusConflict:
+			writeError(w, status, "could not join the queue")
+		default:
+			writeJSON(w, status, result)
+		}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		if parts[0] != "queues" || len(parts) > 3 || (len(parts) == 3 && parts[2] != "join") {
+			writeError(w, http.StatusNotFound, "nothing at "+r.URL.Path)
+			return
+		}
+
+		allowed := http.MethodGet
+		if len(parts) == 3 {
+			allowed = http.MethodPost
+		}
+		if r.Method != allowed {
+			writeError(w, http.StatusMethodNotAllowed, r.Method+" is not allowed on "+r.URL.Path)
+			return
+		}
+
+		switch len(parts) {
+		case 1:
+			list(w, r)
+		case 2:
+			show(w, r, parts[1])
+		default:
+			join(w, r, parts[1])
+		}
+	})
+}
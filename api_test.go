@@ -0,0 +1,212 @@
+package qbot_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/doozr/guac"
+	. "github.com/doozr/qbot"
+	"github.com/doozr/qbot/command"
+	"github.com/doozr/qbot/queue"
+)
+
+var apiTokens = map[string]string{"dashboard": "t0ken"}
+
+// startAPI runs a dispatcher for the API handler to hand its jobs to, until the returned function is called.
+func startAPI(qs queue.Channels) (http.Handler, *[]command.Notification, func()) {
+	notifications := []command.Notification{}
+	notify := func(n command.Notification) error {
+		notifications = append(notifications, n)
+		return nil
+	}
+	handleMessage := CreateMessageHandler(PublicCommands(command.New("U999", "qbot", webhookUsers)), notify)
+
+	done := make(DoneChan)
+	jobs := make(chan Job)
+	dispatcher := CreateDispatcher(qs, time.Minute, handleMessage, func(guac.UserInfo) {}, nil, nil, jobs)
+	finished := make(chan struct{})
+	go func() {
+		dispatcher(make(guac.EventChan), done)
+		close(finished)
+	}()
+
+	handler := CreateAPIHandler(apiTokens, webhookUsers, handleMessage, jobs, done)
+	return handler, &notifications, func() {
+		close(done)
+		<-finished
+	}
+}
+
+func callAPI(handler http.Handler, method, path, token, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w
+}
+
+func getAPIQueue() queue.Channels {
+	return queue.Channels{
+		"C1234": queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{
+			{ID: "U123", Reason: "Tomato", Key: "jox", ActiveSince: acquired},
+			{ID: "U999", Reason: "Potato", Key: "lqy"},
+		}}},
+		"C5678": queue.Tokens{queue.DefaultToken: {}, "staging": {Capacity: 2}},
+	}
+}
+
+func TestAPIListsQueues(t *testing.T) {
+	handler, _, stop := startAPI(getAPIQueue())
+	defer stop()
+
+	w := callAPI(handler, http.MethodGet, "/queues", "", "")
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/json" {
+		t.Fatal("Expected JSON, got ", w.Code, w.Header())
+	}
+
+	var channels []struct {
+		Channel string
+		Tokens  []struct {
+			Name     string
+			Capacity int
+			Entries  []map[string]interface{}
+		}
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &channels); err != nil {
+		t.Fatal("Unexpected error ", err)
+	}
+	if len(channels) != 2 || channels[0].Channel != "C1234" || channels[1].Channel != "C5678" {
+		t.Fatal("Expected both channels in order, got ", w.Body.String())
+	}
+	if len(channels[1].Tokens) != 2 || channels[1].Tokens[1].Name != "staging" || channels[1].Tokens[1].Capacity != 2 {
+		t.Fatal("Expected named token, got ", w.Body.String())
+	}
+}
+
+func TestAPIShowsChannel(t *testing.T) {
+	handler, _, stop := startAPI(getAPIQueue())
+	defer stop()
+
+	w := callAPI(handler, http.MethodGet, "/queues/C1234", "", "")
+	expected := `{"channel":"C1234","tokens":[{"name":"default","capacity":1,"entries":[` +
+		`{"position":1,"key":"jox","user":"U123","name":"craig","reason":"Tomato","holding":true,"active_since":"2017-03-14T09:00:00Z"},` +
+		`{"position":2,"key":"lqy","user":"U999","name":"qbot","reason":"Potato","holding":false}]}]}` + "\n"
+	if w.Code != http.StatusOK || w.Body.String() != expected {
+		t.Fatalf("Expected %s, got %d %s", expected, w.Code, w.Body.String())
+	}
+
+	w = callAPI(handler, http.MethodGet, "/queues/C9999", "", "")
+	expected = `{"channel":"C9999","tokens":[{"name":"default","capacity":1,"entries":[]}]}` + "\n"
+	if w.Code != http.StatusOK || w.Body.String() != expected {
+		t.Fatalf("Expected empty channel %s, got %d %s", expected, w.Code, w.Body.String())
+	}
+}
+
+func TestAPIRefusesBadRequests(t *testing.T) {
+	handler, notifications, stop := startAPI(getAPIQueue())
+	defer stop()
+
+	for _, tt := range []struct {
+		test   string
+		method string
+		path   string
+		token  string
+		body   string
+		status int
+	}{
+		{"unknown path", http.MethodGet, "/queue", "", "", http.StatusNotFound},
+		{"unknown action", http.MethodPost, "/queues/C1234/leave", "t0ken", "", http.StatusNotFound},
+		{"join by get", http.MethodGet, "/queues/C1234/join", "t0ken", "", http.StatusMethodNotAllowed},
+		{"change list", http.MethodPost, "/queues", "t0ken", "", http.StatusMethodNotAllowed},
+		{"join without token", http.MethodPost, "/queues/C1234/join", "", `{"user":"craig","reason":"Banana"}`, http.StatusUnauthorized},
+		{"join with wrong token", http.MethodPost, "/queues/C1234/join", "t0ke", `{"user":"craig","reason":"Banana"}`, http.StatusUnauthorized},
+		{"join without reason", http.MethodPost, "/queues/C1234/join", "t0ken", `{"user":"craig"}`, http.StatusBadRequest},
+		{"join unknown user", http.MethodPost, "/queues/C1234/join", "t0ken", `{"user":"nobody","reason":"Banana"}`, http.StatusBadRequest},
+		{"join unknown token in empty channel", http.MethodPost, "/queues/C9999/join", "t0ken",
+			`{"user":"craig","reason":"Banana","token":"live"}`, http.StatusNotFound},
+		{"join unknown token", http.MethodPost, "/queues/C1234/join", "t0ken", `{"user":"craig","reason":"Banana","token":"live"}`, http.StatusNotFound},
+	} {
+		w := callAPI(handler, tt.method, tt.path, tt.token, tt.body)
+		if w.Code != tt.status {
+			t.Fatalf("%s: expected status %d, got %d %s", tt.test, tt.status, w.Code, w.Body.String())
+		}
+	}
+	if len(*notifications) != 0 {
+		t.Fatal("Expected nothing to be said, got ", *notifications)
+	}
+}
+
+func TestAPIJoinsQueue(t *testing.T) {
+	handler, notifications, stop := startAPI(getAPIQueue())
+	defer stop()
+
+	w := callAPI(handler, http.MethodPost, "/queues/C5678/join", "t0ken",
+		`{"user":"craig@corp.com","reason":"release 1.2","token":"staging"}`)
+	var joined struct {
+		Channel string
+		Entry   map[string]interface{}
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &joined); err != nil {
+		t.Fatal("Unexpected error ", err)
+	}
+	if w.Code != http.StatusOK || joined.Channel != "C5678" || joined.Entry["user"] != "U123" ||
+		joined.Entry["reason"] != "release 1.2" || joined.Entry["position"] != 1.0 || joined.Entry["holding"] != true {
+		t.Fatal("Expected craig to join, got ", w.Code, w.Body.String())
+	}
+	if len(*notifications) != 1 || (*notifications)[0].Channel != "C5678" {
+		t.Fatal("Expected the channel to be told, got ", *notifications)
+	}
+
+	w = callAPI(handler, http.MethodGet, "/queues/C5678", "", "")
+	if !strings.Contains(w.Body.String(), `"reason":"release 1.2"`) {
+		t.Fatal("Expected the change to be kept, got ", w.Body.String())
+	}
+}
+
+func TestAPIJoinsEmptyQueue(t *testing.T) {
+	handler, notifications, stop := startAPI(queue.Channels{})
+	defer stop()
+
+	w := callAPI(handler, http.MethodPost, "/queues/C1234/join", "t0ken", `{"user":"craig","reason":"release"}`)
+	expected := `{"channel":"C1234","entry":{"position":1,"key":"","user":"U123","name":"craig","reason":"release",` +
+		`"holding":true},"token":{"name":"default","capacity":1,"entries":[{"position":1,"key":"","user":"U123",` +
+		`"name":"craig","reason":"release","holding":true}]}}` + "\n"
+	if w.Code != http.StatusOK || w.Body.String() != expected {
+		t.Fatalf("Expected %s, got %d %s", expected, w.Code, w.Body.String())
+	}
+	if len(*notifications) != 1 || (*notifications)[0].Channel != "C1234" {
+		t.Fatal("Expected the channel to be told, got ", *notifications)
+	}
+
+	w = callAPI(handler, http.MethodGet, "/queues/C1234", "", "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"reason":"release"`) {
+		t.Fatal("Expected the change to be kept, got ", w.Code, w.Body.String())
+	}
+}
+
+func TestAPIJoinFindsTheNewEntry(t *testing.T) {
+	handler, _, stop := startAPI(getAPIQueue())
+	defer stop()
+
+	w := callAPI(handler, http.MethodPost, "/queues/C1234/join", "t0ken", `{"user":"craig","reason":"fix \"the\" build"}`)
+	var joined struct {
+		Entry map[string]interface{}
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &joined); err != nil {
+		t.Fatal("Unexpected error ", err)
+	}
+	if w.Code != http.StatusOK || joined.Entry["reason"] != `fix "the" build` || joined.Entry["position"] != 3.0 {
+		t.Fatal("Expected craig to join with quotes in the reason, got ", w.Code, w.Body.String())
+	}
+
+	w = callAPI(handler, http.MethodPost, "/queues/C1234/join", "t0ken", `{"user":"craig","reason":"Tomato"}`)
+	if w.Code != http.StatusConflict {
+		t.Fatal("Expected joining again with the same reason to be refused, got ", w.Code, w.Body.String())
+	}
+}
@@ -5,8 +5,12 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
@@ -14,7 +18,11 @@ import (
 	"github.com/doozr/guac"
 	"github.com/doozr/jot"
 	"github.com/doozr/qbot"
+	"github.com/doozr/qbot/activity"
 	"github.com/doozr/qbot/command"
+	"github.com/doozr/qbot/history"
+	"github.com/doozr/qbot/journal"
+	"github.com/doozr/qbot/preferences"
 	"github.com/doozr/qbot/queue"
 	"github.com/doozr/qbot/usercache"
 )
@@ -33,30 +41,110 @@ func main() {
 	waitGroup := sync.WaitGroup{}
 	done := make(qbot.DoneChan)
 
-	q := loadQueueOrDie(filename)
+	journalFilename := os.Getenv("QBOT_JOURNAL")
+	qs, pastEvents := loadQueuesOrDie(filename, journalFilename)
+	qs = qs.Stamp(time.Now())
+	eventLog := journal.NewLog(pastEvents, 31*24*time.Hour)
 
 	client := connectToSlackOrDie(token)
 
 	userCache := getUserListOrDie(client)
 	userChangeHandler := qbot.CreateUserChangeHandler(userCache)
-	commands := command.New(client.ID(), client.Name(), userCache)
+	admins := parseAdmins()
+	votes, window := parseOustVotesOrDie()
+	restricted := parseRestricted(admins, votes)
+	prefs := loadPreferencesOrDie(preferencesFilename(filename))
+	changes := history.New(50)
+	seen := activity.New()
+	commands := command.New(client.ID(), client.Name(), userCache).
+		WithAdmins(admins).
+		WithHistory(changes).
+		WithEvents(eventLog).
+		WithRequestTimeout(parseRequestTimeoutOrDie()).
+		WithPreferences(prefs).
+		WithOustVotes(votes, window).
+		WithActivity(seen).
+		WithThemes(loadThemesOrDie()).
+		WithRestricted(restricted)
 	notify := qbot.CreateNotifier(client.IMOpen, client.PostMessage)
+	watch := qbot.ChainWatchers(
+		qbot.CreateHookWatcher(userCache, time.Now, startHookSendersOrDie(done, &waitGroup)...),
+		qbot.CreatePositionWatcher(commands, notify),
+		qbot.CreateOustVoteWatcher(commands, notify))
+	publicCommands := qbot.RestrictCommands(qbot.PublicCommands(commands), restricted, commands)
 
-	handlePublicMessage := qbot.CreatePersistedMessageHandler(
-		qbot.CreateMessageHandler(qbot.PublicCommands(commands), notify),
-		qbot.CreatePersister(writeFile, filename, q))
+	persist := qbot.CreatePersister(writeFile, filename, qs)
+	record := qbot.ChainJournals(qbot.CreateLogJournal(eventLog), createJournal(journalFilename))
 
-	handlePrivateMessage := qbot.CreateMessageHandler(qbot.PrivateCommands(commands), notify)
+	handlePublicMessage := qbot.CreateSuggestingMessageHandler(
+		qbot.CreateWatchedMessageHandler(
+			qbot.CreatePersistedMessageHandler(
+				qbot.CreateJournaledMessageHandler(
+					qbot.CreateRecordedMessageHandler(
+						qbot.CreateTimestampedMessageHandler(
+							qbot.CreateMessageHandler(publicCommands, notify),
+							time.Now),
+						changes, time.Now),
+					record, time.Now),
+				persist),
+			watch),
+		publicCommands.Names(), commands.Unknown, notify)
 
-	handleMessage := qbot.CreateMessageDirector(client.ID(), client.Name(), handlePublicMessage, handlePrivateMessage)
+	privateCommands := qbot.PrivateCommands(commands)
+	handlePrivateMessage := qbot.CreateSuggestingMessageHandler(
+		qbot.CreatePrivateMessageHandler(privateCommands, notify),
+		privateCommands.Names(), commands.Unknown, notify)
+
+	handleMessage := qbot.CreateActivityMessageHandler(
+		qbot.CreateMessageDirector(client.ID(), client.Name(), handlePublicMessage, handlePrivateMessage),
+		seen, time.Now)
+
+	jobs := make(chan qbot.Job)
+	mux := http.NewServeMux()
+	if secret, channel := parseWebhookOrDie(); secret != "" {
+		mux.Handle("/webhook", qbot.CreateWebhookHandler(
+			[]byte(secret), channel, client.ID(), userCache, handlePublicMessage, time.Now, jobs, done))
+		log.Printf("Accepting webhooks for %s", channel)
+	}
+	api := qbot.CreateAPIHandler(parseAPITokensOrDie(), userCache, handlePublicMessage, jobs, done)
+	mux.Handle("/queues", api)
+	mux.Handle("/queues/", api)
+	if addr := os.Getenv("QBOT_HTTP_ADDR"); addr != "" {
+		qbot.StartServer(&http.Server{Addr: addr, Handler: mux}, done, &waitGroup)
+		log.Printf("Listening for HTTP on %s", addr)
+	}
 
 	receiver := qbot.CreateEventReceiver(client)
 	events := qbot.Receive(receiver, done, &waitGroup)
 
 	qbot.StartKeepAlive(client.Ping, time.After, done, &waitGroup)
 
+	handleTick := qbot.CreatePersistedTickHandler(
+		qbot.ChainTickHandlers(
+			qbot.CreateWatchedTickHandler(
+				qbot.CreateRecordedTickHandler(
+					qbot.CreateJournaledTickHandler(
+						qbot.CreateReservationTimer(commands, notify), client.ID(), "reserve", record),
+					client.ID(), "reserve", changes),
+				"reserve", watch),
+			qbot.CreateWatchedTickHandler(
+				qbot.CreateRecordedTickHandler(
+					qbot.CreateJournaledTickHandler(
+						qbot.CreateRequestTimer(commands, notify), client.ID(), "expire", record),
+					client.ID(), "expire", changes),
+				"expire", watch),
+			qbot.CreateWatchedTickHandler(
+				qbot.CreateRecordedTickHandler(
+					qbot.CreateJournaledTickHandler(
+						qbot.CreateHoldTimer(parseHoldLimitsOrDie(), commands, notify), client.ID(), "hold", record),
+					client.ID(), "hold", changes),
+				"hold", watch)),
+		persist)
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+
 	log.Print("Ready")
-	dispatcher := qbot.CreateDispatcher(q, 1*time.Minute, handleMessage, userChangeHandler)
+	dispatcher := qbot.CreateDispatcher(qs, 1*time.Minute, handleMessage, userChangeHandler, ticker.C, handleTick, jobs)
 	abort := qbot.Dispatch(dispatcher, events, done, &waitGroup)
 	sig := addSignalHandler()
 	wait(sig, abort)
@@ -78,6 +166,239 @@ func parseCLI() (token, filename string) {
 	return
 }
 
+func parseList(value string) (items []string) {
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return
+}
+
+func parseAdmins() []string {
+	return parseList(os.Getenv("QBOT_ADMINS"))
+}
+
+// defaultRestricted are the commands only admins may use unless QBOT_RESTRICTED says otherwise
+const defaultRestricted = "boot,oust,move"
+
+// parseRestricted reads the commands that only admins may use
+//
+// `oust` is never restricted when ousting is put to a vote, as that would stop anybody but admins from voting. Admins
+// still oust straight away.
+func parseRestricted(admins []string, votes int) (restricted []string) {
+	value, ok := os.LookupEnv("QBOT_RESTRICTED")
+	if !ok {
+		value = defaultRestricted
+	}
+
+	for _, cmd := range parseList(strings.ToLower(value)) {
+		if cmd == "oust" && votes > 1 {
+			log.Print("Ousting is put to a vote, so anybody waiting may use oust")
+			continue
+		}
+		restricted = append(restricted, cmd)
+	}
+
+	if len(restricted) > 0 && len(admins) == 0 {
+		log.Printf("No admins set in QBOT_ADMINS, so anybody may use %s", strings.Join(restricted, ", "))
+		return nil
+	}
+	return restricted
+}
+
+func parseDurationOrDie(env string) (d time.Duration) {
+	value := os.Getenv(env)
+	if value == "" {
+		return
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Fatalf("Error parsing %s: %s", env, err)
+	}
+	return
+}
+
+func parseHoldLimitsOrDie() (limits qbot.HoldLimits) {
+	limits = qbot.HoldLimits{
+		Hold:     parseDurationOrDie("QBOT_HOLD_TIME"),
+		Escalate: parseDurationOrDie("QBOT_HOLD_ESCALATE"),
+		Grace:    parseDurationOrDie("QBOT_HOLD_GRACE"),
+	}
+	if limits.Hold <= 0 {
+		return
+	}
+
+	if limits.Escalate >= limits.GracePeriod() {
+		log.Fatalf("Error parsing QBOT_HOLD_ESCALATE: %s is not before the holder is ousted after %s",
+			limits.Escalate, limits.GracePeriod())
+	}
+	log.Printf("Token holders will be reminded after %s and ousted after a further %s", limits.Hold, limits.GracePeriod())
+	return
+}
+
+func parseRequestTimeoutOrDie() (timeout time.Duration) {
+	timeout = parseDurationOrDie("QBOT_REQUEST_TIMEOUT")
+	if timeout <= 0 {
+		timeout = command.DefaultRequestTimeout
+	}
+	return
+}
+
+func parseOustVotesOrDie() (votes int, window time.Duration) {
+	if value := os.Getenv("QBOT_OUST_VOTES"); value != "" {
+		var err error
+		votes, err = strconv.Atoi(value)
+		if err != nil {
+			log.Fatalf("Error parsing QBOT_OUST_VOTES: %s", err)
+		}
+	}
+
+	window = parseDurationOrDie("QBOT_OUST_WINDOW")
+	if window <= 0 {
+		window = command.DefaultVoteWindow
+	}
+
+	if votes > 1 {
+		log.Printf("Token holders will be ousted once %d people vote within %s", votes, window)
+	}
+	return
+}
+
+// parseWebhookOrDie reads the secret that webhooks are signed with and the channel they act on
+//
+// Webhooks are turned off if QBOT_WEBHOOK_SECRET is not set.
+func parseWebhookOrDie() (secret, channel string) {
+	secret = os.Getenv("QBOT_WEBHOOK_SECRET")
+	channel = os.Getenv("QBOT_WEBHOOK_CHANNEL")
+	if secret == "" {
+		return
+	}
+	if channel == "" {
+		log.Fatal("Error setting up webhooks: QBOT_WEBHOOK_CHANNEL must be set along with QBOT_WEBHOOK_SECRET")
+	}
+	if os.Getenv("QBOT_HTTP_ADDR") == "" {
+		log.Print("QBOT_HTTP_ADDR is not set, so no webhooks will be received")
+	}
+	return
+}
+
+// parseAPITokensOrDie reads the tokens that API clients must give to change the queues
+//
+// QBOT_API_TOKENS is a comma separated list of client names and their tokens (e.g. `dashboard:s3cret`).
+func parseAPITokensOrDie() map[string]string {
+	tokens := map[string]string{}
+	for _, item := range parseList(os.Getenv("QBOT_API_TOKENS")) {
+		parts := strings.SplitN(item, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			log.Fatal("Error parsing QBOT_API_TOKENS: each entry must be a client name and token separated by a colon")
+		}
+		tokens[parts[0]] = parts[1]
+	}
+	return tokens
+}
+
+// hookTimeout is how long an outgoing webhook may take before it counts as failed
+const hookTimeout = 10 * time.Second
+
+// startHookSendersOrDie starts sending outgoing webhooks to each of QBOT_HOOK_URLS
+//
+// QBOT_HOOK_EVENTS limits which events are sent and QBOT_HOOK_SECRET signs them. QBOT_HOOK_ATTEMPTS and
+// QBOT_HOOK_BACKOFF say how many times a webhook is tried and how long to wait after it first fails.
+func startHookSendersOrDie(done qbot.DoneChan, waitGroup *sync.WaitGroup) (senders []qbot.HookSender) {
+	urls := parseList(os.Getenv("QBOT_HOOK_URLS"))
+	if len(urls) == 0 {
+		return
+	}
+
+	events := parseList(strings.ToLower(os.Getenv("QBOT_HOOK_EVENTS")))
+	for _, e := range events {
+		switch e {
+		case qbot.HookAcquired, qbot.HookReleased, qbot.HookJoined, qbot.HookLeft, qbot.HookOusted, qbot.HookBooted:
+		default:
+			log.Fatalf("Error parsing QBOT_HOOK_EVENTS: unknown event %s", e)
+		}
+	}
+
+	retry := qbot.Retry{Attempts: 5, Backoff: parseDurationOrDie("QBOT_HOOK_BACKOFF")}
+	if value := os.Getenv("QBOT_HOOK_ATTEMPTS"); value != "" {
+		var err error
+		retry.Attempts, err = strconv.Atoi(value)
+		if err != nil {
+			log.Fatalf("Error parsing QBOT_HOOK_ATTEMPTS: %s", err)
+		}
+	}
+	if retry.Backoff <= 0 {
+		retry.Backoff = time.Second
+	}
+
+	post := qbot.CreateHookPoster(&http.Client{Timeout: hookTimeout}, []byte(os.Getenv("QBOT_HOOK_SECRET")), time.Now)
+	for _, url := range urls {
+		hook := qbot.OutgoingHook{URL: url, Events: events}
+		senders = append(senders, qbot.StartHookSender(hook, post, retry, time.After, done, waitGroup))
+		log.Printf("Sending webhooks to %s", url)
+	}
+	return
+}
+
+// defaultTheme is the name of the built in English wording, which is always available
+const defaultTheme = "en"
+
+// loadThemesOrDie reads the wording of responses from QBOT_CATALOGUE and picks the theme for each channel
+//
+// The catalogue file maps theme names to a template for every response. QBOT_THEME names the theme used unless
+// QBOT_CHANNEL_THEMES gives another for a channel (e.g. `C1234:plain,C5678:fr`).
+func loadThemesOrDie() map[string]command.Theme {
+	catalogues := map[string]command.Catalogue{}
+	if filename := os.Getenv("QBOT_CATALOGUE"); filename != "" {
+		dat, err := ioutil.ReadFile(filename)
+		if err != nil {
+			log.Fatalf("Error loading catalogue: %s", err)
+		}
+		err = json.Unmarshal(dat, &catalogues)
+		if err != nil {
+			log.Fatalf("Error parsing catalogue: %s", err)
+		}
+		log.Printf("Loaded %d themes from %s", len(catalogues), filename)
+	}
+
+	available := map[string]command.Theme{}
+	for name, catalogue := range catalogues {
+		theme, err := command.NewTheme(catalogue)
+		if err != nil {
+			log.Fatalf("Error in the %s theme: %s", name, err)
+		}
+		available[name] = theme
+	}
+	if _, ok := available[defaultTheme]; !ok {
+		available[defaultTheme], _ = command.NewTheme(command.English)
+	}
+
+	pick := func(name string) command.Theme {
+		theme, ok := available[name]
+		if !ok {
+			log.Fatalf("Error picking theme: there is no %s theme", name)
+		}
+		return theme
+	}
+
+	name := os.Getenv("QBOT_THEME")
+	if name == "" {
+		name = defaultTheme
+	}
+	themes := map[string]command.Theme{"": pick(name)}
+	for _, channelTheme := range parseList(os.Getenv("QBOT_CHANNEL_THEMES")) {
+		parts := strings.SplitN(channelTheme, ":", 2)
+		if len(parts) != 2 {
+			log.Fatalf("Error parsing QBOT_CHANNEL_THEMES: %s is not <channel ID>:<theme>", channelTheme)
+		}
+		themes[parts[0]] = pick(parts[1])
+		log.Printf("Responses in <#%s> will use the %s theme", parts[0], parts[1])
+	}
+	return themes
+}
+
 func connectToSlackOrDie(token string) guac.RealTimeClient {
 	client, err := guac.New(token).RealTime()
 	if err != nil {
@@ -101,27 +422,128 @@ func writeFile(filename string, content []byte, mode os.FileMode) (err error) {
 	return
 }
 
-func loadQueueOrDie(filename string) (q queue.Queue) {
-	q = queue.Queue{}
+func appendFile(filename string, content []byte) (err error) {
+	f, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return
+	}
+
+	_, err = f.Write(content)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return
+}
+
+func createJournal(journalFilename string) qbot.Journal {
+	if journalFilename == "" {
+		return func([]journal.Event) error { return nil }
+	}
+	log.Printf("Recording changes to %s", journalFilename)
+	return qbot.CreateJournal(appendFile, journalFilename)
+}
+
+func readJournalOrDie(journalFilename string) (events []journal.Event, ok bool) {
+	events = []journal.Event{}
+	f, err := os.Open(journalFilename)
+	if os.IsNotExist(err) {
+		return
+	}
+	if err != nil {
+		log.Fatalf("Error opening journal: %s", err)
+	}
+	defer f.Close()
+
+	events, err = journal.Read(f)
+	if err != nil {
+		log.Fatalf("Error reading journal: %s", err)
+	}
+
+	log.Printf("Read %d events from %s", len(events), journalFilename)
+	return events, true
+}
+
+// loadQueuesOrDie reads the queues from the snapshot file, falling back to the journal if there is no snapshot
+//
+// If the snapshot and the journal disagree then the snapshot wins, and the journal is brought up to date with it so
+// that replaying it still gives the current state.
+func loadQueuesOrDie(filename, journalFilename string) (qs queue.Channels, events []journal.Event) {
+	if journalFilename == "" {
+		return loadSnapshotOrDie(filename), []journal.Event{}
+	}
+
+	events, ok := readJournalOrDie(journalFilename)
+	replayed := journal.Replay(queue.Channels{}, events)
+	if _, err := os.Stat(filename); err != nil {
+		log.Printf("Loaded %d queues from %s", len(replayed), journalFilename)
+		return replayed, events
+	}
+
+	qs = loadSnapshotOrDie(filename).Stamp(time.Now())
+	if ok && replayed.Equal(qs) {
+		return
+	}
+
+	log.Printf("Journal %s does not match %s; recording the difference", journalFilename, filename)
+	synced := journal.Diff(replayed, qs, "", "sync", time.Now())
+	err := createJournal(journalFilename)(synced)
+	if err != nil {
+		log.Fatalf("Error syncing journal: %s", err)
+	}
+	return qs, append(events, synced...)
+}
+
+// loadSnapshotOrDie reads the queues from the data file
+//
+// A data file from an older version with a single queue is moved to the channel named by QBOT_LEGACY_CHANNEL, and
+// saved in the new form after the next change.
+func loadSnapshotOrDie(filename string) (qs queue.Channels) {
+	qs = queue.Channels{}
 	if _, err := os.Stat(filename); err != nil {
 		return
 	}
 
 	dat, err := ioutil.ReadFile(filename)
 	if err != nil {
-		log.Fatalf("Error loading queue: %s", err)
+		log.Fatalf("Error loading queues: %s", err)
 	}
 
-	err = json.Unmarshal(dat, &q)
+	qs, err = qbot.ReadQueues(dat, os.Getenv("QBOT_LEGACY_CHANNEL"))
 	if err != nil {
-		log.Fatalf("Error parsing queue: %s", err)
+		log.Fatalf("Error parsing queues: %s (set QBOT_LEGACY_CHANNEL to the channel ID to move an old queue to)", err)
 	}
 
-	jot.Printf("loadQueue: read queue from %s: %v", filename, q)
-	log.Printf("Loaded queue from %s", filename)
+	jot.Printf("loadQueues: read queues from %s: %v", filename, qs)
+	log.Printf("Loaded %d queues from %s", len(qs), filename)
 	return
 }
 
+// preferencesFilename names the file that user preferences are saved in alongside the data file
+func preferencesFilename(filename string) string {
+	return strings.TrimSuffix(filename, filepath.Ext(filename)) + ".users.json"
+}
+
+func loadPreferencesOrDie(filename string) preferences.Preferences {
+	users := map[string]preferences.User{}
+	if dat, err := ioutil.ReadFile(filename); err == nil {
+		err = json.Unmarshal(dat, &users)
+		if err != nil {
+			log.Fatalf("Error parsing preferences: %s", err)
+		}
+		log.Printf("Loaded preferences for %d users from %s", len(users), filename)
+	} else if !os.IsNotExist(err) {
+		log.Fatalf("Error loading preferences: %s", err)
+	}
+
+	return preferences.New(users, func(users map[string]preferences.User) error {
+		j, err := json.Marshal(users)
+		if err != nil {
+			return err
+		}
+		return writeFile(filename, j, 0644)
+	})
+}
+
 func getUserListOrDie(client guac.WebClient) (userCache usercache.UserCache) {
 	log.Println("Getting user list")
 	users, err := client.UsersList()
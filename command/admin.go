@@ -0,0 +1,17 @@
+package command
+
+import "github.com/doozr/qbot/queue"
+
+// AdminOnly wraps a command so that only admins of the channel it is used in may use it
+//
+// Anybody else is told that they are not allowed, and the queue is left alone. In a channel without any admins
+// everybody may use it.
+func (c QueueCommands) AdminOnly(cmd string, fn ChannelCommand) ChannelCommand {
+	return func(t queue.Tokens, ch, id, args string) (queue.Tokens, Notification) {
+		if c.hasAdmins(ch) && !c.isAdmin(ch, id) {
+			c.logActivity(id, cmd, "refused as not an admin")
+			return t, Notification{ch, c.forChannel(ch).response.AdminOnly(id, cmd)}
+		}
+		return fn(t, ch, id, args)
+	}
+}
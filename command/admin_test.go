@@ -0,0 +1,82 @@
+package command_test
+
+import (
+	"testing"
+
+	"github.com/doozr/qbot/command"
+	"github.com/doozr/qbot/queue"
+)
+
+func TestAdminOnly(t *testing.T) {
+	cmd := command.New(id, name, userCache).WithAdmins([]string{"U789", "C4D5E6F:U456"})
+	boot := cmd.AdminOnly("boot", cmd.Named(command.QueueCommands.Boot))
+
+	start := queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{
+		{ID: "U123", Reason: "Active"},
+		{ID: "U456", Reason: "First"}}}}
+	booted := queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{
+		{ID: "U123", Reason: "Active"}}}}
+
+	testChannelCommand(t, boot, []TokenTest{
+		{
+			test:             "let admins use the command",
+			startTokens:      start,
+			channel:          "C1A2B3C",
+			user:             "U789",
+			args:             "edward",
+			expectedTokens:   booted,
+			expectedResponse: "<@U789|andrew> booted <@U456|edward> (First) from the list",
+		},
+		{
+			test:             "refuse anybody else",
+			startTokens:      start,
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "edward",
+			expectedTokens:   start,
+			expectedResponse: "<@U123|craig> Sorry, only admins may use `boot` in this channel",
+		},
+		{
+			test:             "let channel admins use the command in their channel",
+			startTokens:      start,
+			channel:          "C4D5E6F",
+			user:             "U456",
+			args:             "edward",
+			expectedTokens:   booted,
+			expectedResponse: "<@U456|edward> booted <@U456|edward> (First) from the list",
+		},
+		{
+			test:             "refuse channel admins in other channels",
+			startTokens:      start,
+			channel:          "C1A2B3C",
+			user:             "U456",
+			args:             "edward",
+			expectedTokens:   start,
+			expectedResponse: "<@U456|edward> Sorry, only admins may use `boot` in this channel",
+		},
+	})
+
+	channelAdmins := command.New(id, name, userCache).WithAdmins([]string{"C4D5E6F:U456"})
+	boot = channelAdmins.AdminOnly("boot", channelAdmins.Named(command.QueueCommands.Boot))
+
+	testChannelCommand(t, boot, []TokenTest{
+		{
+			test:             "let anybody use the command where only another channel has admins",
+			startTokens:      start,
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "edward",
+			expectedTokens:   booted,
+			expectedResponse: "<@U123|craig> booted <@U456|edward> (First) from the list",
+		},
+		{
+			test:             "refuse anybody else in the channel with admins",
+			startTokens:      start,
+			channel:          "C4D5E6F",
+			user:             "U123",
+			args:             "edward",
+			expectedTokens:   start,
+			expectedResponse: "<@U123|craig> Sorry, only admins may use `boot` in this channel",
+		},
+	})
+}
@@ -0,0 +1,234 @@
+package command
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// word is one argument of a command, along with where it was found in the arguments
+type word struct {
+	text   string
+	index  int
+	start  int
+	end    int
+	quoted bool
+}
+
+// arguments are the arguments of a command split into words
+//
+// Text in double quotes is kept together as one word with the quotes taken off, and a backslash inside the quotes
+// makes the quote mark or backslash after it part of the text. Options such as `--token staging` are
+// picked out from anywhere in the arguments, but only for the option names the command accepts, so that anything else
+// starting with `--` is left alone. Reading a word from the front gives back the arguments that are left.
+type arguments struct {
+	text    string
+	words   []word
+	options map[string]string
+}
+
+// quotes pairs each quote mark that can start a quoted word with the marks that can end it
+var quotes = map[rune]string{
+	'"': `"`,
+	'“': `”"`,
+	'”': `”"`,
+}
+
+// escaped is true if the rune at an index is a backslash that escapes a quote mark or another backslash after it
+func escaped(rs []rune, ix int) bool {
+	return rs[ix] == '\\' && ix+1 < len(rs) && strings.ContainsRune(`\"“”`, rs[ix+1])
+}
+
+// split breaks text into words at white space, keeping quoted text together
+func split(text string) (words []word) {
+	rs := []rune(text)
+	offsets := make([]int, len(rs)+1)
+	for ix := range rs {
+		offsets[ix+1] = offsets[ix] + len(string(rs[ix]))
+	}
+
+	for ix := 0; ix < len(rs); {
+		if unicode.IsSpace(rs[ix]) {
+			ix++
+			continue
+		}
+
+		start := ix
+		if closing, ok := quotes[rs[ix]]; ok {
+			text := []rune{}
+			end := ix + 1
+			for end < len(rs) && !(strings.ContainsRune(closing, rs[end]) && (end+1 == len(rs) || unicode.IsSpace(rs[end+1]))) {
+				if escaped(rs, end) {
+					end++
+				}
+				text = append(text, rs[end])
+				end++
+			}
+			if end < len(rs) {
+				words = append(words, word{string(text), len(words), offsets[start], offsets[end+1], true})
+				ix = end + 1
+				continue
+			}
+		}
+
+		for ix < len(rs) && !unicode.IsSpace(rs[ix]) {
+			ix++
+		}
+		words = append(words, word{string(rs[start:ix]), len(words), offsets[start], offsets[ix], false})
+	}
+	return
+}
+
+// parseArgs splits the arguments of a command into words and picks out the options it accepts
+//
+// An option is followed by its value, or may be given as `--name=value`. An option at the end of the arguments has an
+// empty value.
+func parseArgs(text string, options ...string) arguments {
+	accepted := map[string]bool{}
+	for _, o := range options {
+		accepted[o] = true
+	}
+
+	a := arguments{text: text, options: map[string]string{}}
+	words := split(text)
+	for ix := 0; ix < len(words); ix++ {
+		w := words[ix]
+		if w.quoted || !strings.HasPrefix(w.text, "--") {
+			a.words = append(a.words, w)
+			continue
+		}
+
+		parts := strings.SplitN(w.text[2:], "=", 2)
+		name := strings.ToLower(parts[0])
+		if !accepted[name] {
+			a.words = append(a.words, w)
+			continue
+		}
+
+		value := ""
+		if len(parts) == 2 {
+			value = parts[1]
+		} else if ix+1 < len(words) {
+			ix++
+			value = words[ix].text
+		}
+		a.options[name] = value
+	}
+	return a
+}
+
+// empty is true if there are no words left
+func (a arguments) empty() bool {
+	return len(a.words) == 0
+}
+
+// len counts the words left
+func (a arguments) len() int {
+	return len(a.words)
+}
+
+// first is the first word left, or an empty string if there are none
+func (a arguments) first() string {
+	if a.empty() {
+		return ""
+	}
+	return a.words[0].text
+}
+
+// shift takes the first word from the front of the arguments
+func (a arguments) shift() (first string, rest arguments) {
+	if a.empty() {
+		return "", a
+	}
+	rest = a
+	rest.words = a.words[1:]
+	return a.words[0].text, rest
+}
+
+// rest is the text of the words left as it was typed, for free text such as a reason
+//
+// Quotes are only taken off if the text left is a single quoted word. Options picked out of the middle of the text
+// are replaced by a single space.
+func (a arguments) rest() string {
+	if len(a.words) == 1 && a.words[0].quoted {
+		return a.words[0].text
+	}
+
+	s := ""
+	for ix, w := range a.words {
+		if ix > 0 {
+			prev := a.words[ix-1]
+			if w.index == prev.index+1 {
+				s += a.text[prev.end:w.start]
+			} else {
+				s += " "
+			}
+		}
+		s += a.text[w.start:w.end]
+	}
+	return s
+}
+
+// option is the value of an option, and whether it was given at all
+func (a arguments) option(name string) (value string, ok bool) {
+	value, ok = a.options[name]
+	return
+}
+
+// position reads a position in the queue, counting from one, from the front of the arguments
+func (a arguments) position() (position int, rest arguments, ok bool) {
+	position, err := strconv.Atoi(a.first())
+	if err != nil {
+		return 0, a, false
+	}
+	_, rest = a.shift()
+	return position, rest, true
+}
+
+// key reads an entry key such as #a2y from the front of the arguments
+func (a arguments) key() (key string, rest arguments, ok bool) {
+	first := a.first()
+	if len(first) < 2 || !strings.HasPrefix(first, "#") {
+		return "", a, false
+	}
+	_, rest = a.shift()
+	return strings.ToLower(first[1:]), rest, true
+}
+
+// duration reads a length of time such as 90m or 1h30m from the front of the arguments
+func (a arguments) duration() (d time.Duration, rest arguments, ok bool) {
+	d, err := time.ParseDuration(a.first())
+	if err != nil {
+		return 0, a, false
+	}
+	_, rest = a.shift()
+	return d, rest, true
+}
+
+// user reads a user from the front of the arguments, given as a mention, a user name, display name, real name or email
+//
+// A name can run over several words, such as a real name, so the most words that name anybody are read. The ID is
+// empty if there is no such user, and if more than one user goes by the name they are all given as candidates.
+func (c QueueCommands) user(a arguments) (id, name string, rest arguments, candidates []string) {
+	rest = a
+	for n := a.len(); n > 0; n-- {
+		words, r := []string{}, a
+		for len(words) < n {
+			var w string
+			w, r = r.shift()
+			words = append(words, w)
+		}
+
+		name, rest = strings.Join(words, " "), r
+		candidates = c.getIDsFromName(name)
+		if len(candidates) > 0 {
+			break
+		}
+	}
+
+	if len(candidates) == 1 {
+		return candidates[0], name, rest, nil
+	}
+	return "", name, rest, candidates
+}
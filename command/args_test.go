@@ -0,0 +1,274 @@
+package command_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/doozr/guac"
+	"github.com/doozr/qbot/command"
+	"github.com/doozr/qbot/queue"
+	"github.com/doozr/qbot/usercache"
+)
+
+func TestArgsQuotedReason(t *testing.T) {
+	cmd := command.New(id, name, userCache)
+	andrew := queue.Item{ID: "U789", Reason: "Tomato"}
+	testCommand(t, cmd.Join, []CommandTest{
+		{
+			test:             "quotes are taken off a quoted reason",
+			startQueue:       queue.Queue{andrew},
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             `"fix the build"`,
+			expectedQueue:    queue.Queue{andrew, {ID: "U123", Reason: "fix the build"}},
+			expectedResponse: "<@U123|craig> (fix the build) is now next in line",
+		},
+		{
+			test:             "smart quotes are taken off a quoted reason",
+			startQueue:       queue.Queue{andrew},
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "“fix the build”",
+			expectedQueue:    queue.Queue{andrew, {ID: "U123", Reason: "fix the build"}},
+			expectedResponse: "<@U123|craig> (fix the build) is now next in line",
+		},
+		{
+			test:             "escaped quotes and backslashes inside a quoted reason are kept",
+			startQueue:       queue.Queue{andrew},
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             `"fix \"the\" build in C:\\src"`,
+			expectedQueue:    queue.Queue{andrew, {ID: "U123", Reason: `fix "the" build in C:\src`}},
+			expectedResponse: `<@U123|craig> (fix "the" build in C:\src) is now next in line`,
+		},
+		{
+			test:             "quotes and spacing inside a reason are kept",
+			startQueue:       queue.Queue{andrew},
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             `fix  the "build" --force`,
+			expectedQueue:    queue.Queue{andrew, {ID: "U123", Reason: `fix  the "build" --force`}},
+			expectedResponse: `<@U123|craig> (fix  the "build" --force) is now next in line`,
+		},
+	})
+}
+
+func TestArgsTokenOption(t *testing.T) {
+	cmd := command.New(id, name, userCache)
+	andrew := queue.Item{ID: "U789", Reason: "Tomato"}
+	start := queue.Tokens{queue.DefaultToken: {}, "staging": {Queue: queue.Queue{andrew}}}
+
+	testChannelCommand(t, cmd.Named(command.QueueCommands.Join), []TokenTest{
+		{
+			test:        "token named by option",
+			startTokens: start,
+			channel:     "C1A2B3C",
+			user:        "U123",
+			args:        "release --token staging to prod",
+			expectedTokens: queue.Tokens{queue.DefaultToken: {},
+				"staging": {Queue: queue.Queue{andrew, {ID: "U123", Reason: "release to prod"}}}},
+			expectedResponse: "<@U123|craig> (release to prod) is now next in line for the `staging` token",
+		},
+		{
+			test:             "token named by option does not exist",
+			startTokens:      start,
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "--token=live release",
+			expectedTokens:   start,
+			expectedResponse: "<@U123|craig> There is no `live` token",
+		},
+		{
+			test:             "token option without a name",
+			startTokens:      start,
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "release --token",
+			expectedTokens:   start,
+			expectedResponse: "<@U123|craig> You must provide a token name after `--token`",
+		},
+		{
+			test:        "quoted word is never a token name",
+			startTokens: start,
+			channel:     "C1A2B3C",
+			user:        "U123",
+			args:        `"staging"`,
+			expectedTokens: queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{{ID: "U123", Reason: "staging"}}},
+				"staging": {Queue: queue.Queue{andrew}}},
+			expectedResponse: "*<@U123|craig> (staging) now has the token*",
+		},
+	})
+}
+
+func TestArgsDelegateByReason(t *testing.T) {
+	cmd := command.New(id, name, userCache)
+	start := queue.Queue{
+		{ID: "U123", Reason: "Banana"},
+		{ID: "U456", Reason: "Apple pie"},
+		{ID: "U456", Reason: "Lemon"}}
+	delegated := queue.Queue{
+		{ID: "U123", Reason: "Banana"},
+		{ID: "U789", Reason: "Apple pie"},
+		{ID: "U456", Reason: "Lemon"}}
+
+	testCommand(t, cmd.Delegate, []CommandTest{
+		{
+			test:             "reason prefix after the user",
+			startQueue:       start,
+			channel:          "C1A2B3C",
+			user:             "U456",
+			args:             "andrew apple",
+			expectedQueue:    delegated,
+			expectedResponse: "<@U456|edward> (Apple pie) has delegated to <@U789|andrew>",
+		},
+		{
+			test:             "quoted reason prefix given as an option",
+			startQueue:       start,
+			channel:          "C1A2B3C",
+			user:             "U456",
+			args:             `--reason "apple p" <@U789|andrew>`,
+			expectedQueue:    delegated,
+			expectedResponse: "<@U456|edward> (Apple pie) has delegated to <@U789|andrew>",
+		},
+		{
+			test:             "reason prefix that matches nothing",
+			startQueue:       start,
+			channel:          "C1A2B3C",
+			user:             "U456",
+			args:             "andrew cherry",
+			expectedQueue:    start,
+			expectedResponse: "<@U456|edward> You cannot delegate if you are not in the queue",
+		},
+	})
+}
+
+func TestArgsNumericNames(t *testing.T) {
+	uc := usercache.New([]guac.UserInfo{
+		{ID: "U123", Name: "craig"},
+		{ID: "U456", Name: "edward"},
+		{ID: "U007", Name: "007"},
+	})
+	cmd := command.New(id, name, uc)
+	start := queue.Queue{
+		{ID: "U123", Reason: "Banana"},
+		{ID: "U007", Reason: "Martini"},
+		{ID: "U456", Reason: "Apple"},
+		{ID: "U007", Reason: "Olive"}}
+
+	testCommand(t, cmd.Boot, []CommandTest{
+		{
+			test:       "number on its own is a name",
+			startQueue: start,
+			channel:    "C1A2B3C",
+			user:       "U456",
+			args:       "007",
+			expectedQueue: queue.Queue{
+				{ID: "U123", Reason: "Banana"},
+				{ID: "U007", Reason: "Martini"},
+				{ID: "U456", Reason: "Apple"}},
+			expectedResponse: "<@U456|edward> booted <@U007|007> (Olive) from the list",
+		},
+		{
+			test:       "number in front of a name is a position",
+			startQueue: start,
+			channel:    "C1A2B3C",
+			user:       "U456",
+			args:       "2 007",
+			expectedQueue: queue.Queue{
+				{ID: "U123", Reason: "Banana"},
+				{ID: "U456", Reason: "Apple"},
+				{ID: "U007", Reason: "Olive"}},
+			expectedResponse: "<@U456|edward> booted <@U007|007> (Martini) from the list",
+		},
+		{
+			test:       "position given as an option",
+			startQueue: start,
+			channel:    "C1A2B3C",
+			user:       "U456",
+			args:       "@007 --position 2",
+			expectedQueue: queue.Queue{
+				{ID: "U123", Reason: "Banana"},
+				{ID: "U456", Reason: "Apple"},
+				{ID: "U007", Reason: "Olive"}},
+			expectedResponse: "<@U456|edward> booted <@U007|007> (Martini) from the list",
+		},
+		{
+			test:             "position option that is not a number",
+			startQueue:       start,
+			channel:          "C1A2B3C",
+			user:             "U456",
+			args:             "007 --position second",
+			expectedQueue:    start,
+			expectedResponse: "<@U456|edward> That's not a valid position in the queue",
+		},
+	})
+}
+
+func TestArgsDuration(t *testing.T) {
+	now := time.Date(2017, 3, 14, 10, 0, 0, 0, time.UTC)
+	cmd := command.New(id, name, userCache).WithClock(func() time.Time { return now })
+	fn := cmd.Reserve
+
+	_, r := fn(queue.Tokens{}, "C1A2B3C", "U123", `14:00 1h30m "big release"`)
+	assertResponse(t, "duration and quoted reason", "C1A2B3C",
+		"<@U123|craig> (big release) has reserved the token for Tue 14 Mar 14:00-15:30", r)
+
+	_, r = fn(queue.Tokens{}, "C1A2B3C", "U123", "14:00 soon release")
+	assertResponse(t, "bad duration", "C1A2B3C",
+		"<@U123|craig> `soon` is not a duration, use a duration like `1h` or `90m`", r)
+}
+
+func TestArgsOtherNames(t *testing.T) {
+	uc := usercache.New([]guac.UserInfo{
+		{ID: "U123", Name: "craig"},
+		{ID: "U456", Name: "jane.smith", DisplayName: "Jane", RealName: "Jane Smith", Email: "jane@corp.com"},
+		{ID: "U789", Name: "janet", DisplayName: "Jane", RealName: "Janet Jones"},
+	})
+	cmd := command.New(id, name, uc)
+	start := queue.Queue{
+		{ID: "U123", Reason: "Banana"},
+		{ID: "U456", Reason: "Apple"},
+		{ID: "U789", Reason: "Pear"}}
+
+	testCommand(t, cmd.Boot, []CommandTest{
+		{
+			test:             "real name over more than one word",
+			startQueue:       start,
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "jane smith",
+			expectedQueue:    queue.Queue{{ID: "U123", Reason: "Banana"}, {ID: "U789", Reason: "Pear"}},
+			expectedResponse: "<@U123|craig> booted <@U456|jane.smith> (Apple) from the list",
+		},
+		{
+			test:             "display name shared by two users",
+			startQueue:       start,
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "JANE",
+			expectedQueue:    start,
+			expectedResponse: "<@U123|craig> More than one person goes by JANE: `@jane.smith`, `@janet`. Give their user name instead",
+		},
+	})
+
+	testCommand(t, cmd.Delegate, []CommandTest{
+		{
+			test:             "email",
+			startQueue:       start,
+			channel:          "C1A2B3C",
+			user:             "U789",
+			args:             "Jane@Corp.com",
+			expectedQueue:    queue.Queue{{ID: "U123", Reason: "Banana"}, {ID: "U456", Reason: "Apple"}, {ID: "U456", Reason: "Pear"}},
+			expectedResponse: "<@U789|janet> (Pear) has delegated to <@U456|jane.smith>",
+		},
+		{
+			test:             "real name followed by a reason prefix",
+			startQueue:       start,
+			channel:          "C1A2B3C",
+			user:             "U789",
+			args:             "Jane Smith pe",
+			expectedQueue:    queue.Queue{{ID: "U123", Reason: "Banana"}, {ID: "U456", Reason: "Apple"}, {ID: "U456", Reason: "Pear"}},
+			expectedResponse: "<@U789|janet> (Pear) has delegated to <@U456|jane.smith>",
+		},
+	})
+}
@@ -2,17 +2,18 @@ package command
 
 import "github.com/doozr/qbot/queue"
 
-// Barge adds a user to the front of the queue
+// Barge adds a user to the front of the waiting list
 func (c QueueCommands) Barge(q queue.Queue, ch, id, args string) (queue.Queue, Notification) {
+	a := parseArgs(args)
 	var i queue.Item
-	i = queue.Item{ID: id, Reason: args}
+	i = queue.Item{ID: id, Reason: a.rest()}
 
-	position, _, ok := c.parsePosition(args)
-	if ok {
-		i, ok = c.findByPosition(q, position)
+	e, position, _, given, ok := c.findEntry(q, a)
+	if given {
 		if !ok {
-			return q, Notification{ch, c.response.BadIndex(id)}
+			return q, Notification{ch, c.badEntry(id, a)}
 		}
+		i = e
 	} else if i.Reason == "" {
 		n, ok := c.findItem(q, id)
 		if !ok {
@@ -25,10 +26,10 @@ func (c QueueCommands) Barge(q queue.Queue, ch, id, args string) (queue.Queue, N
 		return q, Notification{ch, c.response.NotOwned(id, position)}
 	}
 
-	q = q.Barge(i)
-	c.logActivity(id, args, "barged")
-	if q.Active() == i {
+	q = q.BargeBehind(i, c.capacity)
+	c.logActivity(id, i.Reason, "barged")
+	if q.Holds(i, c.capacity) {
 		return q, Notification{ch, c.response.JoinActive(i)}
 	}
-	return q, Notification{ch, c.response.Barge(i, q.Active())}
+	return q, Notification{ch, c.response.Barge(i, q.Holders(c.capacity))}
 }
@@ -156,3 +156,39 @@ func TestBarge(t *testing.T) {
 		},
 	})
 }
+
+func TestBargeSharedToken(t *testing.T) {
+	cmd := command.New(id, name, userCache)
+	testChannelCommand(t, cmd.Named(command.QueueCommands.Barge), []TokenTest{
+		{
+			test:             "barge behind every holder",
+			startTokens:      queue.Tokens{"envs": {Capacity: 2, Queue: queue.Queue{{ID: "U123", Reason: "Banana"}, {ID: "U456", Reason: "Apple"}, {ID: "U456", Reason: "Waiting"}}}},
+			channel:          "C1A2B3C",
+			user:             "U789",
+			args:             "envs Pear",
+			expectedTokens:   queue.Tokens{"envs": {Capacity: 2, Queue: queue.Queue{{ID: "U123", Reason: "Banana"}, {ID: "U456", Reason: "Apple"}, {ID: "U789", Reason: "Pear"}, {ID: "U456", Reason: "Waiting"}}}},
+			expectedResponse: "<@U789|andrew> (Pear) barged to the front\n<@U123|craig> (Banana) and <@U456|edward> (Apple) still have the `envs` token",
+		},
+	})
+}
+
+func TestBargeByKey(t *testing.T) {
+	cmd := command.New(id, name, userCache)
+	testCommand(t, cmd.Barge, []CommandTest{
+		{
+			test: "barge entry with key",
+			startQueue: queue.Queue{
+				{ID: "U123", Reason: "Banana", Key: "a00"},
+				{ID: "U456", Reason: "Apple", Key: "b00"},
+				{ID: "U789", Reason: "Pear", Key: "c00"}},
+			channel: "C1A2B3C",
+			user:    "U789",
+			args:    "#c00",
+			expectedQueue: queue.Queue{
+				{ID: "U123", Reason: "Banana", Key: "a00"},
+				{ID: "U789", Reason: "Pear", Key: "c00"},
+				{ID: "U456", Reason: "Apple", Key: "b00"}},
+			expectedResponse: "<@U789|andrew> (Pear) barged to the front\n<@U123|craig> (Banana) still has the token",
+		},
+	})
+}
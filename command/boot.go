@@ -1,18 +1,50 @@
 package command
 
 import (
+	"strconv"
+
 	"github.com/doozr/qbot/queue"
 )
 
 // Boot kicks someone from the waiting list
+//
+// The entry can be picked by key alone, or by the name of its owner along with an optional position given in front of
+// the name or with `--position`. A number given on its own is taken as a name.
 func (c QueueCommands) Boot(q queue.Queue, ch, booter, args string) (queue.Queue, Notification) {
 	if len(q) == 0 {
 		return q, Notification{ch, ""}
 	}
 
-	position, name, _ := c.parsePosition(args)
+	a := parseArgs(args, "position")
+	if key, rest, ok := a.key(); ok {
+		i, position, ok := c.findByKey(q, key)
+		if !ok {
+			return q, Notification{ch, c.response.BadKey(booter, key)}
+		}
+		if id, name, _, candidates := c.user(rest); len(candidates) > 1 {
+			return q, Notification{ch, c.response.UserAmbiguous(booter, name, candidates)}
+		} else if !rest.empty() && id != i.ID {
+			return q, Notification{ch, c.response.NotOwned(booter, position)}
+		}
+		return c.boot(q, ch, booter, i)
+	}
+
+	position := 0
+	if a.len() > 1 {
+		position, a, _ = a.position()
+	}
+	if value, ok := a.option("position"); ok {
+		p, err := strconv.Atoi(value)
+		if err != nil {
+			return q, Notification{ch, c.response.BadIndex(booter)}
+		}
+		position = p
+	}
 
-	id := c.getIDFromName(name)
+	id, name, _, candidates := c.user(a)
+	if len(candidates) > 1 {
+		return q, Notification{ch, c.response.UserAmbiguous(booter, name, candidates)}
+	}
 	if id == "" {
 		return q, Notification{ch, c.response.BootNoEntry(booter, name)}
 	}
@@ -29,11 +61,16 @@ func (c QueueCommands) Boot(q queue.Queue, ch, booter, args string) (queue.Queue
 		return q, Notification{ch, c.response.NotOwned(booter, position)}
 	}
 
-	if q.Active() == i {
+	return c.boot(q, ch, booter, i)
+}
+
+// boot removes an entry from the waiting list, as long as it does not hold the token
+func (c QueueCommands) boot(q queue.Queue, ch, booter string, i queue.Item) (queue.Queue, Notification) {
+	if q.Holds(i, c.capacity) {
 		return q, Notification{ch, c.response.OustNotBoot(booter)}
 	}
 
 	q = q.Remove(i)
-	c.logActivity(id, i.Reason, "booted by "+c.getNameIDPair(booter))
+	c.logActivity(i.ID, i.Reason, "booted by "+c.getNameIDPair(booter))
 	return q, Notification{ch, c.response.Boot(booter, i)}
 }
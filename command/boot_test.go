@@ -90,3 +90,49 @@ func TestBoot(t *testing.T) {
 		},
 	})
 }
+
+func TestBootByKey(t *testing.T) {
+	cmd := command.New(id, name, userCache)
+	testCommand(t, cmd.Boot, []CommandTest{
+		{
+			test: "boot entry with key",
+			startQueue: queue.Queue{
+				{ID: "U123", Reason: "Active", Key: "a00"},
+				{ID: "U456", Reason: "First", Key: "b00"},
+				{ID: "U456", Reason: "Last", Key: "c00"}},
+			channel: "C1A2B3C",
+			user:    "U789",
+			args:    "#b00",
+			expectedQueue: queue.Queue{
+				{ID: "U123", Reason: "Active", Key: "a00"},
+				{ID: "U456", Reason: "Last", Key: "c00"}},
+			expectedResponse: "<@U789|andrew> booted <@U456|edward> (First) from the list",
+		},
+		{
+			test: "refuse if name does not match entry with key",
+			startQueue: queue.Queue{
+				{ID: "U123", Reason: "Active", Key: "a00"},
+				{ID: "U456", Reason: "First", Key: "b00"}},
+			channel: "C1A2B3C",
+			user:    "U789",
+			args:    "#b00 craig",
+			expectedQueue: queue.Queue{
+				{ID: "U123", Reason: "Active", Key: "a00"},
+				{ID: "U456", Reason: "First", Key: "b00"}},
+			expectedResponse: "<@U789|andrew> You are not 2nd in line",
+		},
+		{
+			test: "refuse to boot token holder by key",
+			startQueue: queue.Queue{
+				{ID: "U123", Reason: "Active", Key: "a00"},
+				{ID: "U456", Reason: "First", Key: "b00"}},
+			channel: "C1A2B3C",
+			user:    "U789",
+			args:    "#a00",
+			expectedQueue: queue.Queue{
+				{ID: "U123", Reason: "Active", Key: "a00"},
+				{ID: "U456", Reason: "First", Key: "b00"}},
+			expectedResponse: "<@U789|andrew> You must oust the token holder",
+		},
+	})
+}
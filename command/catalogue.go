@@ -0,0 +1,156 @@
+package command
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/doozr/qbot/journal"
+	"github.com/doozr/qbot/queue"
+	"github.com/doozr/qbot/util"
+)
+
+// Catalogue holds the wording of every response as a template, keyed on name
+//
+// Templates are written for text/template and are given the values for the response, such as `.Item` or `.Position`.
+// Keys starting with a lower case letter are phrases shared by other responses, and can be called from any template
+// as functions of the same name (e.g. `{{theToken}}` or `{{nowHasToken .Item}}`).
+type Catalogue map[string]string
+
+// Theme is a catalogue that has been checked and is ready to word responses
+type Theme struct {
+	templates *template.Template
+}
+
+// partials are the keys of phrases that templates call as functions
+var partials = []string{
+	"theToken", "theQueue", "forToken", "inChannel", "ordinal", "andList", "times", "window", "holdersAtOnce",
+	"nowHasToken", "nowHaveToken", "finishedWithToken", "upForGrabs", "yielded",
+}
+
+// NewTheme checks that a catalogue words every response and nothing else, and that every template can be read
+func NewTheme(c Catalogue) (Theme, error) {
+	missing, unknown := []string{}, []string{}
+	for key := range English {
+		if _, ok := c[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	for key := range c {
+		if _, ok := English[key]; !ok {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(unknown)
+
+	if len(missing) > 0 {
+		return Theme{}, fmt.Errorf("missing responses: %s", strings.Join(missing, ", "))
+	}
+	if len(unknown) > 0 {
+		return Theme{}, fmt.Errorf("unknown responses: %s", strings.Join(unknown, ", "))
+	}
+
+	t := template.New("").Option("missingkey=error").Funcs(responses{}.funcs(nil))
+	for key, text := range c {
+		if _, err := t.New(key).Parse(text); err != nil {
+			return Theme{}, err
+		}
+	}
+	return Theme{t}, nil
+}
+
+// mustTheme checks a built in catalogue, which is a bug if it fails
+func mustTheme(c Catalogue) Theme {
+	theme, err := NewTheme(c)
+	if err != nil {
+		panic(err)
+	}
+	return theme
+}
+
+// english is the theme responses are worded with unless told otherwise
+var english = mustTheme(English)
+
+// forChannel returns a copy of the commands that words responses with the theme for the channel
+func (c QueueCommands) forChannel(ch string) QueueCommands {
+	if theme, ok := c.themes[ch]; ok {
+		c.response.theme = theme
+	}
+	return c
+}
+
+// fields are the values given to a template
+type fields map[string]interface{}
+
+// funcs are the functions that templates can call, with the phrases from the given templates
+func (n responses) funcs(t *template.Template) template.FuncMap {
+	partial := func(key string) func(...interface{}) (string, error) {
+		return func(args ...interface{}) (string, error) {
+			var data interface{}
+			if len(args) > 0 {
+				data = args[0]
+			}
+			var b strings.Builder
+			err := t.ExecuteTemplate(&b, key, data)
+			return b.String(), err
+		}
+	}
+
+	andList := partial("andList")
+	fm := template.FuncMap{
+		"link":       n.link,
+		"name":       n.getUserName,
+		"token":      func() string { return n.token },
+		"namedToken": n.isNamedToken,
+		"capacity":   func() int { return n.capacity },
+		"key":        key,
+		"reply":      reply,
+		"capitalise": capitalise,
+		"duration":   util.Duration,
+		"suffix":     util.Suffix,
+		"percentile": journal.Percentile,
+		"formatTime": func(t time.Time, layout string) string { return t.Format(layout) },
+		"sameDay": func(a, b time.Time) bool {
+			return a.Format("2006-01-02") == b.Format("2006-01-02")
+		},
+		"last": func(ix int, list []string) bool { return ix == len(list)-1 },
+		"item": func(i queue.Item) string { return fmt.Sprintf("%s (%s)", n.link(i.ID), i.Reason) },
+		"items": func(is []queue.Item) (string, error) {
+			s := []string{}
+			for _, i := range is {
+				s = append(s, fmt.Sprintf("%s (%s)", n.link(i.ID), i.Reason))
+			}
+			return andList(s)
+		},
+		"links": func(ids []string) (string, error) {
+			s := []string{}
+			for _, id := range ids {
+				s = append(s, n.link(id))
+			}
+			return andList(s)
+		},
+	}
+	for _, key := range partials {
+		fm[key] = partial(key)
+	}
+	return fm
+}
+
+// render words the named response with the theme
+func (n responses) render(key string, data interface{}) string {
+	t, err := n.theme.templates.Clone()
+	if err == nil {
+		var b strings.Builder
+		err = t.Funcs(n.funcs(t)).ExecuteTemplate(&b, key, data)
+		if err == nil {
+			return b.String()
+		}
+	}
+
+	log.Printf("Could not word %s: %s", key, err)
+	return ""
+}
@@ -0,0 +1,272 @@
+package command
+
+// English is the built in wording of every response
+var English = Catalogue{
+	// Phrases shared by other responses
+	"theToken":          "{{if namedToken}}the `{{token}}` token{{else}}the token{{end}}",
+	"theQueue":          "{{if namedToken}}the `{{token}}` queue{{else}}the queue{{end}}",
+	"forToken":          "{{if namedToken}} for {{theToken}}{{end}}",
+	"inChannel":         "{{theToken}} in <#{{.}}>",
+	"ordinal":           "{{.}}{{suffix .}}",
+	"andList":           "{{range $ix, $s := .}}{{if $ix}}{{if last $ix $}} and {{else}}, {{end}}{{end}}{{$s}}{{end}}",
+	"times":             "{{if eq . 1}}once{{else}}{{.}} times{{end}}",
+	"window":            `{{formatTime .Start "Mon 2 Jan 15:04"}}{{if sameDay .Start .End}}-{{formatTime .End "15:04"}}{{else}} to {{formatTime .End "Mon 2 Jan 15:04"}}{{end}}`,
+	"holdersAtOnce":     "{{if gt . 1}}up to {{.}} people at once{{else}}one person at a time{{end}}",
+	"nowHasToken":       "*{{item .}} now has {{theToken}}*",
+	"nowHaveToken":      "{{range $ix, $i := .}}{{if $ix}}\n{{end}}{{nowHasToken $i}}{{end}}",
+	"finishedWithToken": "{{item .}} has finished with {{theToken}}",
+	"upForGrabs":        "{{capitalise theToken}} is up for grabs",
+	"yielded":           "{{item .}} has yielded {{theToken}}",
+
+	// Finding entries
+	"BadIndex": "{{link .ID}} That's not a valid position in the queue",
+	"BadKey":   "{{link .ID}} No entry `#{{.Key}}` was found in {{theQueue}}",
+	"NotOwned": "{{link .ID}} You are not {{ordinal .Position}} in line",
+
+	// Joining, leaving and giving up the token
+	"Join":            "{{item .Item}} is now {{if .Next}}next{{else}}{{ordinal .Position}}{{end}} in line{{forToken}}",
+	"ReplaceNoReason": "{{link .Item.ID}} You must provide a new reason",
+	"JoinNoReason":    "{{link .Item.ID}} You must provide a reason for joining",
+	"JoinActive":      "{{nowHasToken .Item}}",
+	"Leave":           "{{item .Item}} has left {{theQueue}}",
+	"LeaveActive":     "{{link .Item.ID}} You have the token, did you mean `done` or `drop`?",
+	"LeaveNoEntry":    "{{link .ID}} No entry was found",
+	"Done":            "{{finishedWithToken .Item}}\n{{nowHaveToken .Promoted}}",
+	"DoneNoOthers":    "{{finishedWithToken .Item}}\n{{upForGrabs}}",
+	"DoneNotActive":   "{{link .ID}} You cannot be done if you don't have the token",
+	"Yield":           "{{yielded .Item}}\n{{nowHaveToken .Promoted}}",
+	"YieldNoOthers":   "{{link .Item.ID}} You cannot yield if there is nobody waiting",
+	"YieldNotActive":  "{{link .Item.ID}} You cannot yield if you do not have the token",
+	"Barge":           "{{item .Item}} barged to the front\n{{items .Holders}} still {{if gt (len .Holders) 1}}have{{else}}has{{end}} {{theToken}}",
+
+	// Getting rid of people
+	"Boot":           "{{link .Booter}} booted {{item .Item}} from the list",
+	"BootNoEntry":    "{{link .ID}} No entry for {{.Name}} was found",
+	"OustNotBoot":    "{{link .ID}} You must oust the token holder",
+	"Oust":           "{{link .Ouster}} ousted {{item .Item}}\n{{nowHaveToken .Promoted}}",
+	"OustNoOthers":   "{{link .Ouster}} ousted {{item .Item}}\n{{upForGrabs}}",
+	"OustNotActive":  "{{link .ID}} You can only oust the token holder",
+	"OustNoTarget":   "{{link .ID}} You must specify who you want to oust",
+	"HoldReminder":   "You have had {{theToken}} for {{duration .Held}} ({{.Item.Reason}}). Please say `done` if you have finished with it, otherwise you will be ousted in {{duration .Remaining}}",
+	"HoldEscalation": "{{item .Item}} has had {{theToken}} for {{duration .Held}} and will be ousted in {{duration .Remaining}}",
+
+	// Voting to oust
+	"OustVoteNotWaiting": "{{link .ID}} Only people waiting for {{theToken}} can vote to oust its holder",
+	"OustVote":           "{{link .Voter}} voted to oust {{item .Item}} ({{.Votes}} of {{.Needed}} votes needed within {{duration .Window}})",
+	"OustVoted":          "{{item .Item}} was voted out by {{links .Voters}}\n{{nowHaveToken .Promoted}}",
+	"NotifyOustVote":     "Somebody has voted to oust you from {{inChannel .Channel}} ({{.Item.Reason}}). Say something in <#{{.Channel}}> if you still need it, otherwise you will be ousted if {{.Needed}} people vote within {{duration .Window}}",
+
+	// Delegating
+	"Delegate":           "{{item .Item}} has delegated to {{link .Target}}",
+	"DelegateActive":     "{{item .Item}} has delegated to {{link .Next.ID}}\n{{nowHasToken .Next}}",
+	"DelegateNoSuchUser": "{{link .ID}} You cannot delegate to {{.Target}} because they don't exist",
+	"DelegateNoEntry":    "{{link .ID}} You cannot delegate if you are not in the queue",
+	"RefuseToken":        "What am I going to do with the token?",
+	"RefuseTokenActive": "{{item .Item}} has delegated to {{link .Next.ID}}\n{{nowHasToken .Next}}\n" +
+		":zap: :zap: AT LAST! ULTIMATE POWER! :zap: :zap:\n\nJust kidding ... I don't need the token, you can have it back\n" +
+		"{{item .Next}} has delegated to {{link .Item.ID}}\n{{nowHasToken .Item}}",
+
+	// Notifications from automated systems
+	"SuccessNotification":           "Received a success notification from {{link .ID}}{{with .Key}} for `{{.}}`{{end}}{{with .Message}}\n{{.}}{{end}}",
+	"FailureNotificationEmptyQueue": "Received a failure notification from {{link .ID}}: {{.Message}}",
+	"FailureNotification":           "{{range .IDs}}{{link .}} {{end}}Received a failure notification from {{link .ID}}{{with .Key}} for `{{.}}`{{end}}: {{.Message}}",
+	"SuccessMismatch": "Received a success notification from {{link .ID}} for `{{.Key}}`, but nobody holding {{theToken}} matches it " +
+		"so nothing was changed{{with .Holders}} ({{items .}} {{if eq (len .) 1}}has{{else}}have{{end}} it){{end}}",
+	"FailureMismatch": "Received a failure notification from {{link .ID}} for `{{.Key}}`, but nobody holding {{theToken}} matches it" +
+		"{{with .Holders}} ({{items .}} {{if eq (len .) 1}}has{{else}}have{{end}} it){{end}}: {{.Message}}",
+
+	// Named tokens
+	"TokenCreated":       "Created the `{{.Name}}` token{{if gt .Capacity 1}} for {{holdersAtOnce .Capacity}}{{end}}, use `join {{.Name}} <reason>` to queue for it",
+	"TokenCapacity":      "{{capitalise theToken}} can now be held by {{holdersAtOnce capacity}}{{with .Promoted}}\n{{nowHaveToken .}}{{end}}",
+	"TokenBadCapacity":   "{{link .ID}} {{with .Capacity}}`{{.}}` is not a valid number of people to hold a token at once{{else}}You must say how many people can hold {{theToken}} at once{{end}}",
+	"TokenCapacityInUse": "{{link .ID}} {{.Holders}} people hold {{theToken}} right now, so it cannot be held by fewer",
+	"TokenDeleted":       "Deleted the `{{.Name}}` token",
+	"TokenNoName":        "{{link .ID}} You must provide a token name",
+	"TokenBadName":       "{{link .ID}} `{{.Name}}` cannot be used as a token name, use a word starting with a letter",
+	"TokenExists":        "{{link .ID}} The `{{.Name}}` token already exists",
+	"TokenNotFound":      "{{link .ID}} {{with .Name}}There is no `{{.}}` token{{else}}You must provide a token name after `--token`{{end}}",
+	"TokenDeleteDefault": "{{link .ID}} The `{{.Name}}` token cannot be deleted",
+	"TokenInUse":         "{{link .ID}} The `{{.Name}}` token cannot be deleted while anybody is queued for it",
+
+	// Reservations
+	"Reserve":            "{{item .Item}} has reserved {{theToken}} for {{window .Reservation}}",
+	"ReserveBadStart":    "{{link .ID}} `{{.Start}}` is not a start time, use a time like `14:00` or `2017-03-14T14:00`",
+	"ReserveBadDuration": "{{link .ID}} `{{.Duration}}` is not a duration, use a duration like `1h` or `90m`",
+	"ReserveNoReason":    "{{link .ID}} You must provide a reason for reserving",
+	"ReservePast":        "{{link .ID}} That time has already passed",
+	"ReserveConflict":    "{{link .ID}} {{capitalise theToken}} is already reserved at that time:{{range .Conflicts}}\n{{window .}} {{name .ID}} ({{.Reason}}){{end}}",
+	"ReservationStarted": "{{item .Item}} has reserved {{theToken}} for {{window .Reservation}}\n{{nowHasToken .Item}}{{range .Displaced}}\n{{item .}} is now next in line{{end}}",
+	"ReservationEnded":   "The reservation for {{window .Reservation}} has ended\n{{.Done}}",
+
+	// Listing the queues
+	"ListEmpty":    "Nobody has the token, and nobody is waiting",
+	"ListHolder":   "*{{.Position}}: {{name .Item.ID}} ({{.Item.Reason}}) has the token*{{key .Item}}",
+	"ListWaiting":  "{{.Position}}: {{name .Item.ID}} ({{.Item.Reason}}){{key .Item}}",
+	"ListReserved": "_Reserved {{window .Reservation}}: {{name .Reservation.ID}} ({{.Reservation.Reason}})_",
+	"ListHeading":  "`{{token}}`{{if gt capacity 1}} (up to {{capacity}} at once){{end}}",
+
+	// Undo
+	"UndoNothing":   "{{link .ID}} You have nothing to undo",
+	"UndoConflict":  "{{link .ID}} Can't undo `{{.Command}}` because the queue has changed since{{with .Actors}} (by {{links .}}){{end}}",
+	"UndoTokenGone": "{{capitalise theToken}} no longer exists",
+	"Undo":          "{{link .ID}} undid `{{.Change.Command}}`{{if ne .Change.Actor .ID}} by {{link .Change.Actor}}{{end}}, which leaves:\n{{.Restored}}",
+
+	// Stats
+	"StatsBadPeriod": "{{link .ID}} `{{.Period}}` is not a period I know; use `day`, `week` or `month`",
+	"Stats": "*Stats for the last {{.Period}}{{if .All}} in your channels{{end}}*\n" +
+		"{{if .Empty}}Nothing has happened{{else}}" +
+		"{{with .Waits}}Waited for the token: median {{duration (percentile . 50)}}, 90% within {{duration (percentile . 90)}} ({{len .}} waits){{else}}Nobody has waited for the token{{end}}\n" +
+		"{{with .Holds}}Held the token: median {{duration (percentile . 50)}}, 90% within {{duration (percentile . 90)}} ({{len .}} holds){{else}}Nobody has held the token{{end}}\n" +
+		"Barges: {{.Barges}}, ousts: {{.Ousts}}\n" +
+		"*Per user:*{{range .Users}}\n{{name .ID}}: " +
+		"{{if .Holds}}held {{times .Holds}} for {{duration .Held}} in total, waited {{duration .Waited}} in total" +
+		"{{else}}waited {{duration .Waited}} in total{{end}}{{end}}{{end}}",
+
+	// Moving and swapping
+	"MoveNotWaiting":  "{{link .ID}} Only entries waiting for {{theToken}} can be moved, and only to places behind the holders",
+	"MoveNowhere":     "{{item .Item}} is already at position {{.Position}}",
+	"Move":            "{{item .Item}} has moved from position {{.From}} to {{.To}} in {{theQueue}}:\n{{.Order}}",
+	"MoveRequested":   "{{item .Request.Item}} would like to move up to position {{.To}} in {{theQueue}}. {{links .IDs}}, say {{reply .Request \"accept\"}} to let them past or {{reply .Request \"decline\"}} to refuse",
+	"MoveApproved":    "{{link .ID}} has agreed to let {{item .Request.Item}} move up, but {{links .IDs}} still need to say {{reply .Request \"accept\"}}",
+	"MoveDeclined":    "{{link .ID}} has declined to {{if .Request.Swap}}swap places with{{else}}let{{end}} {{item .Request.Item}}{{if not .Request.Swap}} move up{{end}}",
+	"NothingToAccept": "{{link .ID}} Nobody is waiting for you to let them move up or swap places",
+	"SwapNotWaiting":  "{{link .ID}} Only entries waiting for {{theToken}} can swap places",
+	"SwapOwn":         "{{link .ID}} You can't swap places with your own entry; use `move` instead",
+	"SwapRequested":   "{{item .Request.Item}} at position {{.From}} would like to swap places with {{item .Request.Target}} at position {{.To}} in {{theQueue}}. {{link .Request.Target.ID}}, say {{reply .Request \"accept\"}} to swap or {{reply .Request \"decline\"}} to refuse",
+	"Swap":            "{{item .Request.Item}} has swapped places with {{item .Request.Target}} in {{theQueue}}:\n{{.Order}}",
+	"RequestExpired":  "{{item .Request.Item}} asked to {{if .Request.Swap}}swap places with {{item .Request.Target}}{{else}}move up{{end}}, but it was not agreed in time",
+
+	// Direct messages
+	"NotifyUnavailable": "{{link .ID}} Direct messages about the queues are not available",
+	"NotifyBadSetting":  "{{link .ID}} `{{.Setting}}` is not a setting I know; use `on`, `next` or `off`",
+	"NotifyNotSaved":    "{{link .ID}} Your setting could not be saved; please try again",
+	"Notify": `{{if eq .Level "on"}}You will get a direct message whenever your place in a queue changes` +
+		`{{else if eq .Level "next"}}You will get a direct message when you get a token or are next in line for it` +
+		`{{else}}You will not get direct messages about the queues{{end}}`,
+	"NotifyAcquired": "*You now have {{inChannel .Channel}}* ({{.Item.Reason}})",
+	"NotifyNext":     "You are now next in line for {{inChannel .Channel}} ({{.Item.Reason}})",
+	"NotifyPosition": "You are now {{ordinal .Position}} in line for {{inChannel .Channel}} ({{.Item.Reason}})",
+
+	// Where
+	"WhereNothing": "You are not in any queues",
+	"WhereHolding": "*You have {{inChannel .Channel}}* ({{.Item.Reason}}){{key .Item}}{{if .Since}} and have had it for {{duration .Held}}{{end}}",
+	"WhereWaiting": "You are {{ordinal .Position}} in line for {{inChannel .Channel}} ({{.Item.Reason}}){{key .Item}}" +
+		"{{if .Joined}}, have waited {{duration .Waited}}{{end}}" +
+		"{{if .Unknown}} but there is not enough history yet to say how much longer it will be" +
+		"{{else if .Soon}} and should get it any time now{{else}} and should get it in about {{duration .Wait}}{{end}}",
+
+	// Users
+	"UserAmbiguous": "{{link .ID}} More than one person goes by {{.Name}}: " +
+		"{{range $ix, $id := .Candidates}}{{if $ix}}, {{end}}`@{{name $id}}`{{end}}. Give their user name instead",
+
+	// Admins
+	"AdminOnly": "{{link .ID}} Sorry, only admins may use `{{.Command}}` in this channel",
+
+	// Help
+	"Unknown": "{{link .ID}} I don't know `{{.Command}}`" +
+		"{{with .Suggestion}}, did you mean `{{.}}`? Say `yes` to run it{{else}}; say `help` to see what I can do{{end}}",
+	"Help": "Address each command to the bot (`{{.Name}}: <command>`), and say `help <command>` to find out more about any of them\n\n" +
+		"Each entry in `list` has a key such as `#a2y` that stays the same while it is in the queue. " +
+		"Give the key in place of a <position> to be sure of acting on the right entry. " +
+		"Wrap text in double quotes to keep it together as one argument (e.g. `delegate craig --reason \"fix the build\"`). " +
+		"If the channel has more than one token, name a token before the arguments of any command to use it instead of the default token " +
+		"(e.g. `join <token> <reason>`, or `join <reason> --token <token>`).\n" +
+		"{{range .Sections}}\n*{{.Heading}}*\n{{range .Topics}}" +
+		"{{range $ix, $usage := .Usages}}{{if $ix}}, {{end}}`{{$usage}}`{{end}}{{with .About}} - {{.}}{{end}}" +
+		"{{if .PrivateOnly}} (direct message only){{end}}{{if .Restricted}} (admins only){{end}}\n{{end}}{{end}}",
+	"HelpTopic": "{{range .Usages}}`{{.}}`\n{{end}}{{with .About}}{{.}}\n{{end}}{{with .Explain}}\n{{.}}\n{{end}}" +
+		"{{with .Examples}}\n*Examples:*\n{{range .}}`{{.}}`\n{{end}}{{end}}" +
+		"{{if .Restricted}}\n_Only admins may use `{{.Name}}` in channels that have admins_\n{{end}}" +
+		"{{if .PrivateOnly}}\n_`{{.Name}}` only works in a direct message to me_\n{{end}}" +
+		"{{if .PublicOnly}}\n_`{{.Name}}` only works in a channel_\n{{end}}",
+	"HelpNotFound": "{{link .ID}} There is no `{{.Command}}` command; say `help` to see what I can do",
+
+	// Help sections
+	"SectionNeed":          "If you don't have the token and need it:",
+	"SectionHave":          "If you have the token and have done with it:",
+	"SectionChange":        "If you are in the queue and need to change something:",
+	"SectionLeave":         "If you are in the queue and need to leave:",
+	"SectionRid":           "If you need to get rid of somebody who is in the way:",
+	"SectionTokens":        "If the channel has more than one token:",
+	"SectionNotifications": "Notifications (from automated systems):",
+	"SectionOther":         "Other useful things to know:",
+
+	// Help topics
+	"AboutJoin": "Join the queue and give a reason why",
+	"ExplainJoin": "You get the token straight away if nobody else holds it, otherwise you wait at the back of the queue. " +
+		"You can join more than once with different reasons, and each entry waits its turn.",
+	"AboutBarge": "Barge to the front of the queue so you get the token next (only with good reason!)",
+	"ExplainBarge": "With a reason you join at the front of the waiting list, behind whoever holds the token. " +
+		"With a <position> or <#key> the entry you own at that place barges to the front instead; " +
+		"you cannot barge an entry that belongs to somebody else. Without either, your first entry barges.",
+	"AboutReserve": "Book the token for later, and get it when the time comes",
+	"ExplainReserve": "<start> is a time of day such as `14:00`, meaning the next time it comes round, or a date and time such as " +
+		"`2017-03-14T09:30`. <duration> is a length of time such as `1h` or `90m`. " +
+		"When the booking starts you go to the front of the queue, and the token is released again when it ends. " +
+		"A booking is refused if as many people as can hold the token have already booked any of that time.",
+	"AboutDone":   "Release the token once you are done with it",
+	"ExplainDone": "Your entry that holds the token leaves the queue, and the next in line gets the token.",
+	"AboutDrop":   "Drop the token and leave the queue (note: actually just an alias of `done`)",
+	"ExplainDrop": "Exactly the same as `done`.",
+	"AboutYield":  "Release the token and swap places with next in line",
+	"ExplainYield": "You stay in the queue for the same reason, but the next in line gets the token and you wait behind them. " +
+		"Nothing happens if nobody is waiting.",
+	"AboutDelegate": "Delegate your place to someone else",
+	"ExplainDelegate": "Your most recent entry is delegated unless you say which one. " +
+		"Give a <reason prefix> after the user, or with `--reason`, to delegate your entry whose reason starts with it, " +
+		"or give the <#key> of the entry first. The other user takes over the entry where it is in the queue.",
+	"AboutReplace":   "Replace the reason of a queue entry you own",
+	"ExplainReplace": "The entry keeps its place in the queue. You cannot change the reason of somebody else's entry.",
+	"AboutMove":      "Move an entry you own to another place in the waiting list",
+	"ExplainMove": "Moving down happens straight away. Moving up needs the agreement of everybody you pass, " +
+		"who are asked to `accept` or `decline`; the request is dropped if it is not agreed in time. " +
+		"Entries that hold the token cannot be moved.",
+	"AboutSwap":      "Ask the owner of the entry at the given position to swap places with your entry",
+	"ExplainSwap":    "The owner is asked to `accept` or `decline`, and the entries swap places if they accept in time.",
+	"AboutAccept":    "Let somebody who asked to move up past you or swap with you do so",
+	"ExplainAccept":  "If more than one request is waiting for your answer, give the <#key> of the entry that asked.",
+	"AboutDecline":   "Refuse to let somebody who asked to move up past you or swap with you do so",
+	"ExplainDecline": "If more than one request is waiting for your answer, give the <#key> of the entry that asked.",
+	"AboutLeave":     "Leave the queue",
+	"ExplainLeave": "Your most recent entry is removed unless you give the <position> or <#key> of the entry to remove. " +
+		"You can only remove your own entries, and not one that holds the token; use `done` for that.",
+	"AboutOust": "Force the token holder to yield to the next in line (or vote to, if votes are needed)",
+	"ExplainOust": "Name the holder, or give the <#key> of the entry if they hold the token more than once. " +
+		"If the bot is set up to need votes, the holder is only ousted once enough people waiting for the token have voted, " +
+		"and votes are dropped if the holder says anything in the meantime.",
+	"AboutBoot": "Kick somebody out of the waiting list",
+	"ExplainBoot": "Their most recent entry is removed unless you give a <position>, before the name or with `--position`, " +
+		"or the <#key> of the entry. Entries that hold the token cannot be booted; use `oust` for those. " +
+		"A number on its own is taken to be a name.",
+	"AboutCreate":   "Create a new named token with its own queue",
+	"ExplainCreate": "Give <holders> to let more than one person hold the token at once.",
+	"AboutCapacity": "Change how many people can hold the token at once",
+	"ExplainCapacity": "Name a token first to change that one instead of the default token. " +
+		"People waiting get the token straight away if there is now room for them, " +
+		"but the capacity cannot go below the number of people who hold the token.",
+	"AboutDelete":   "Delete a named token once nobody is queued for it",
+	"ExplainDelete": "The default token cannot be deleted.",
+	"AboutSuccess":  "Notify the token holder and next in line of success and remove the token holder",
+	"ExplainSuccess": "Meant for automated systems such as build servers, once the token holder's work has gone through. " +
+		"Give a <key> with `--key`, such as a branch name, pull request number or entry key, to remove the token holder " +
+		"whose entry key is the same or whose reason contains it as a word. Nothing changes if no holder matches. " +
+		"Any other text is ignored.",
+	"AboutFailure": "Notify the token holder and next in line of failure with a custom error message",
+	"ExplainFailure": "Meant for automated systems such as build servers. Nobody is removed from the queue. " +
+		"Give a <key> with `--key`, such as a branch name, pull request number or entry key, to only notify the token holder " +
+		"it matches and the next in line. Nobody is notified if no holder matches.",
+	"AboutList":   "Show who has each token and who is waiting",
+	"ExplainList": "Name a token to only show that one. In a direct message, the queues of every channel you are in are shown.",
+	"AboutNotify": "Get a direct message whenever your place in a queue changes, only when you get the token or are next in line, or never",
+	"ExplainNotify": "`on` tells you about every change to your place, `next` only when you get the token or are next in line, " +
+		"and `off` turns messages off.",
+	"AboutStats":   "Show how long people wait for and hold the token, and who holds it most",
+	"ExplainStats": "Covers the last week unless a period is given. In a direct message, every channel you are in is covered.",
+	"AboutWhere":   "Show where each of your entries is in the queues and how much longer you should have to wait",
+	"ExplainWhere": "How much longer is worked out from how long people have held the token before.",
+	"AboutUndo":    "Undo the last change you made to the queue",
+	"ExplainUndo": "Admins undo the last change anybody made. " +
+		"A change cannot be undone once somebody else has changed the same queue since, as that would throw away their change too.",
+	"AboutHelp":   "Show what each command does",
+	"ExplainHelp": "Name a command to find out more about it.",
+}
@@ -0,0 +1,90 @@
+package command_test
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/doozr/qbot/command"
+	"github.com/doozr/qbot/queue"
+)
+
+// plain is the English catalogue with a few responses reworded
+func plain(changes map[string]string) Catalogue {
+	c := Catalogue{}
+	for key, text := range English {
+		c[key] = text
+	}
+	for key, text := range changes {
+		c[key] = text
+	}
+	return c
+}
+
+func TestEnglishIsComplete(t *testing.T) {
+	if _, err := NewTheme(English); err != nil {
+		t.Fatal("Expected English to be a valid theme, got ", err)
+	}
+}
+
+func TestThemeMissingKey(t *testing.T) {
+	c := plain(nil)
+	delete(c, "Join")
+	delete(c, "theToken")
+
+	_, err := NewTheme(c)
+	if err == nil || !strings.Contains(err.Error(), "missing responses: Join, theToken") {
+		t.Fatal("Expected missing responses to be reported, got ", err)
+	}
+}
+
+func TestThemeUnknownKey(t *testing.T) {
+	_, err := NewTheme(plain(map[string]string{"Joined": "{{item .Item}} joined"}))
+	if err == nil || !strings.Contains(err.Error(), "unknown responses: Joined") {
+		t.Fatal("Expected unknown responses to be reported, got ", err)
+	}
+}
+
+func TestThemeBadTemplate(t *testing.T) {
+	_, err := NewTheme(plain(map[string]string{"Join": "{{item .Item} joined"}))
+	if err == nil {
+		t.Fatal("Expected a template that cannot be read to be reported")
+	}
+}
+
+func TestThemeForChannel(t *testing.T) {
+	theme, err := NewTheme(plain(map[string]string{
+		"Join":     "{{name .Item.ID}} joined{{forToken}} at position {{.Position}}",
+		"forToken": "{{if namedToken}} ({{token}}){{end}}",
+	}))
+	if err != nil {
+		t.Fatal("Expected a valid theme, got ", err)
+	}
+
+	cmd := New(id, name, userCache).WithThemes(map[string]Theme{"C5678": theme})
+	fn := cmd.Named(QueueCommands.Join)
+	andrew := queue.Item{ID: "U789", Reason: "Tomato"}
+	tokens := queue.Tokens{
+		queue.DefaultToken: {Queue: queue.Queue{andrew}},
+		"staging":          {Queue: queue.Queue{andrew}},
+	}
+
+	_, r := fn(tokens, "C1234", "U123", "Banana")
+	assertResponse(t, "default theme", "C1234", "<@U123|craig> (Banana) is now next in line", r)
+
+	_, r = fn(tokens, "C5678", "U123", "Banana")
+	assertResponse(t, "channel theme", "C5678", "craig joined at position 2", r)
+
+	_, r = fn(tokens, "C5678", "U123", "staging Banana")
+	assertResponse(t, "channel theme with shared phrase", "C5678", "craig joined (staging) at position 2", r)
+}
+
+func TestThemeDefault(t *testing.T) {
+	theme, err := NewTheme(plain(map[string]string{"ListEmpty": "Empty"}))
+	if err != nil {
+		t.Fatal("Expected a valid theme, got ", err)
+	}
+
+	cmd := New(id, name, userCache).WithThemes(map[string]Theme{"": theme})
+	_, r := cmd.ListTokens(queue.Tokens{}, "C1234", "U123", "")
+	assertResponse(t, "default theme", "C1234", "Empty", r)
+}
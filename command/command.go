@@ -3,9 +3,14 @@ package command
 import (
 	"fmt"
 	"log"
-	"strconv"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/doozr/qbot/activity"
+	"github.com/doozr/qbot/history"
+	"github.com/doozr/qbot/journal"
+	"github.com/doozr/qbot/preferences"
 	"github.com/doozr/qbot/queue"
 	"github.com/doozr/qbot/usercache"
 )
@@ -13,6 +18,9 @@ import (
 // Command is a function for a command
 type Command func(q queue.Queue, channel string, user string, args string) (queue.Queue, Notification)
 
+// PrivateCommand is a function for a read-only command that can see the queue of every channel
+type PrivateCommand func(qs queue.Channels, channel string, user string, args string) Notification
+
 // Notification represents a message to a channel
 type Notification struct {
 	Channel string
@@ -21,19 +29,168 @@ type Notification struct {
 
 // QueueCommands provides the API to the various commands supported by the bot
 type QueueCommands struct {
-	id        string
-	name      string
-	response  responses
-	userCache usercache.UserCache
+	id         string
+	name       string
+	token      string
+	capacity   int
+	response   responses
+	userCache  usercache.UserCache
+	clock      func() time.Time
+	admins     map[string]bool
+	history    history.History
+	events     journal.Log
+	timeout    time.Duration
+	prefs      preferences.Preferences
+	votes      int
+	window     time.Duration
+	activity   activity.Activity
+	themes     map[string]Theme
+	restricted map[string]bool
 }
 
+// DefaultRequestTimeout is how long requests to move or swap entries wait to be agreed unless told otherwise
+const DefaultRequestTimeout = 15 * time.Minute
+
+// DefaultVoteWindow is how long votes to oust a token holder count for unless told otherwise
+const DefaultVoteWindow = 10 * time.Minute
+
 // New returns a new Command instance
 func New(id string, name string, uc usercache.UserCache) QueueCommands {
-	r := responses{uc}
-	c := QueueCommands{id, name, r, uc}
+	r := responses{uc, queue.DefaultToken, 1, english}
+	c := QueueCommands{id, name, queue.DefaultToken, 1, r, uc, time.Now, map[string]bool{}, nil, nil, DefaultRequestTimeout, nil,
+		0, DefaultVoteWindow, nil, map[string]Theme{}, map[string]bool{}}
+	return c
+}
+
+// WithClock returns a copy of the commands that reads the current time from the given clock
+func (c QueueCommands) WithClock(clock func() time.Time) QueueCommands {
+	c.clock = clock
+	return c
+}
+
+// WithAdmins returns a copy of the commands that treats the given users as admins
+//
+// A user ID on its own makes that user an admin in every channel, and a channel ID and user ID separated by a colon
+// (e.g. `C1234:U1234`) makes the user an admin in that channel only.
+func (c QueueCommands) WithAdmins(ids []string) QueueCommands {
+	c.admins = map[string]bool{}
+	for _, id := range ids {
+		c.admins[id] = true
+	}
+	return c
+}
+
+// WithHistory returns a copy of the commands that can undo the changes remembered by the given history
+func (c QueueCommands) WithHistory(h history.History) QueueCommands {
+	c.history = h
+	return c
+}
+
+// WithEvents returns a copy of the commands that works out stats from the events in the given log
+func (c QueueCommands) WithEvents(l journal.Log) QueueCommands {
+	c.events = l
+	return c
+}
+
+// WithRequestTimeout returns a copy of the commands that drops requests to move or swap entries that are not agreed
+// within the given time
+func (c QueueCommands) WithRequestTimeout(timeout time.Duration) QueueCommands {
+	c.timeout = timeout
+	return c
+}
+
+// WithPreferences returns a copy of the commands that reads and changes the given user preferences
+func (c QueueCommands) WithPreferences(p preferences.Preferences) QueueCommands {
+	c.prefs = p
+	return c
+}
+
+// WithOustVotes returns a copy of the commands that only ousts a token holder once the given number of people waiting
+// for the token have voted for it within the window
+//
+// Fewer than two votes ousts the holder straight away.
+func (c QueueCommands) WithOustVotes(votes int, window time.Duration) QueueCommands {
+	c.votes = votes
+	c.window = window
+	return c
+}
+
+// WithActivity returns a copy of the commands that drops votes to oust token holders who have said anything since, and
+// shows users the channels they have said anything in when asked about every channel
+func (c QueueCommands) WithActivity(a activity.Activity) QueueCommands {
+	c.activity = a
+	return c
+}
+
+// WithThemes returns a copy of the commands that words responses with the theme for the channel they are in
+//
+// Themes are keyed on channel ID, and the theme keyed on an empty string is used in every other channel.
+func (c QueueCommands) WithThemes(themes map[string]Theme) QueueCommands {
+	c.themes = themes
+	if theme, ok := themes[""]; ok {
+		c.response.theme = theme
+	}
 	return c
 }
 
+func (c QueueCommands) isAdmin(ch, id string) bool {
+	return c.admins[id] || c.admins[ch+":"+id]
+}
+
+// hasAdmins is true if anybody is an admin in the channel, either in every channel or in that one only
+func (c QueueCommands) hasAdmins(ch string) bool {
+	for admin := range c.admins {
+		if !strings.Contains(admin, ":") || strings.HasPrefix(admin, ch+":") {
+			return true
+		}
+	}
+	return false
+}
+
+// knownChannels returns the channels a user is known to be in, in order
+//
+// The bot can't ask Slack who is in a channel, so a user is taken to be in a channel if they have an entry in one of
+// its queues, made or were part of a change to it in the journal, or have said anything in it since the bot started.
+// Whatever is sent to a user about every channel only covers these, so nobody sees the queues of channels they are not
+// in.
+func (c QueueCommands) knownChannels(qs queue.Channels, id string) []string {
+	known := map[string]bool{}
+	for channel, t := range qs {
+		for _, name := range t.Names() {
+			if _, ok := c.findItem(t.Get(name), id); ok {
+				known[channel] = true
+			}
+		}
+	}
+
+	channels := map[string]bool{}
+	for channel := range qs {
+		channels[channel] = true
+	}
+	if c.events != nil {
+		for _, e := range c.events.Events() {
+			channels[e.Channel] = true
+			if e.Actor == id || (e.Item != nil && e.Item.ID == id) {
+				known[e.Channel] = true
+			}
+		}
+	}
+	if c.activity != nil {
+		for channel := range channels {
+			if !c.activity.LastSeen(channel, id).IsZero() {
+				known[channel] = true
+			}
+		}
+	}
+
+	sorted := []string{}
+	for channel := range known {
+		sorted = append(sorted, channel)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
 func (c QueueCommands) findItem(q queue.Queue, id string) (item queue.Item, ok bool) {
 	for _, i := range q {
 		if i.ID == id {
@@ -56,6 +213,28 @@ func (c QueueCommands) findItemReverse(q queue.Queue, id string) (item queue.Ite
 	return
 }
 
+// findHolder finds the first item owned by the user that holds the token
+func (c QueueCommands) findHolder(q queue.Queue, id string) (item queue.Item, ok bool) {
+	return c.findItem(queue.Queue(q.Holders(c.capacity)), id)
+}
+
+// promoted returns the items that hold the token after a change but did not before it
+func (c QueueCommands) promoted(before, after queue.Queue) []queue.Item {
+	items := []queue.Item{}
+	for _, i := range after.Holders(c.capacity) {
+		if !before.Holds(i, c.capacity) {
+			items = append(items, i)
+		}
+	}
+	return items
+}
+
+func (c QueueCommands) logPromoted(items []queue.Item) {
+	for _, i := range items {
+		c.logActivity(i.ID, i.Reason, "is active")
+	}
+}
+
 func (c QueueCommands) findByPosition(q queue.Queue, position int) (item queue.Item, ok bool) {
 	if position < 1 || position > len(q) {
 		return
@@ -64,44 +243,48 @@ func (c QueueCommands) findByPosition(q queue.Queue, position int) (item queue.I
 	return q[position-1], true
 }
 
-func (c QueueCommands) parsePosition(args string) (position int, remainder string, ok bool) {
-	remainder = args
-	fields := strings.Fields(args)
-
-	// No fields? Quit now
-	if len(fields) == 0 {
-		return
+// findByKey finds the entry with the given key and its position in the queue
+func (c QueueCommands) findByKey(q queue.Queue, key string) (item queue.Item, position int, ok bool) {
+	for ix, i := range q {
+		if i.Key != "" && i.Key == key {
+			return i, ix + 1, true
+		}
 	}
+	return
+}
 
-	// First field is not an integer? Again, stop
-	position, err := strconv.Atoi(fields[0])
-	if err != nil {
-		return
+// findEntry finds the entry named by a key or position at the front of the arguments
+//
+// given is false if the args start with neither, in which case they are returned untouched. ok is false if the entry
+// named does not exist.
+func (c QueueCommands) findEntry(q queue.Queue, a arguments) (item queue.Item, position int, rest arguments, given bool, ok bool) {
+	if key, rest, given := a.key(); given {
+		item, position, ok = c.findByKey(q, key)
+		return item, position, rest, true, ok
 	}
 
-	// Anything after this point is OK
-	ok = true
-
-	// If the integer is the only field, return an empty string
-	if len(fields) == 1 {
-		remainder = ""
-		return
+	position, rest, given = a.position()
+	if given {
+		item, ok = c.findByPosition(q, position)
 	}
-
-	// String the position part from the start of the string
-	positionLength := len(fields[0])
-	remainder = strings.Trim(args[positionLength:], " ")
 	return
 }
 
-func (c QueueCommands) getIDFromName(name string) (id string) {
-	id = ""
-	if strings.HasPrefix(name, "<@") {
-		id = strings.Trim(name, "<@>")
-	} else {
-		id = c.userCache.GetUserID(name)
+// badEntry tells the user that the key or position at the front of the arguments does not name an entry
+func (c QueueCommands) badEntry(id string, a arguments) string {
+	if key, _, ok := a.key(); ok {
+		return c.response.BadKey(id, key)
 	}
-	return
+	return c.response.BadIndex(id)
+}
+
+// getIDsFromName finds the IDs of users given as a mention such as <@U123> or <@U123|craig>, or by name with or
+// without an @ in front
+func (c QueueCommands) getIDsFromName(name string) (ids []string) {
+	if strings.HasPrefix(name, "<@") && strings.HasSuffix(name, ">") {
+		return []string{strings.SplitN(strings.TrimSuffix(name[2:], ">"), "|", 2)[0]}
+	}
+	return c.userCache.FindUsers(strings.TrimPrefix(name, "@"))
 }
 
 func (c QueueCommands) getNameIDPair(id string) (pair string) {
@@ -110,65 +293,8 @@ func (c QueueCommands) getNameIDPair(id string) (pair string) {
 }
 
 func (c QueueCommands) logActivity(id, reason, text string) {
-	log.Printf("%s (%s) %s", c.getNameIDPair(id), reason, text)
-}
-
-func cmdList(cmds [][]string) string {
-	c := ""
-	for _, vs := range cmds {
-		c += fmt.Sprintf("`%s` - %s\n", vs[0], vs[1])
+	if c.token != queue.DefaultToken {
+		text = fmt.Sprintf("%s [%s]", text, c.token)
 	}
-	return c
-}
-
-// Help provides much needed assistance
-func (c QueueCommands) Help(q queue.Queue, ch, id, args string) (queue.Queue, Notification) {
-	s := fmt.Sprintf("Address each command to the bot (`%s: <command>`)\n\n", c.name)
-
-	s += "*If you don't have the token and need it:*\n"
-	s += cmdList([][]string{
-		{"join <reason>", "Join the queue and give a reason why"},
-		{"barge <reason>", "Barge to the front of the queue so you get the token next (only with good reason!)"},
-		{"barge <position>", "Barge the entry at the given position to the front of the queue"},
-	})
-
-	s += "\n*If you have the token and have done with it:*\n"
-	s += cmdList([][]string{
-		{"done", "Release the token once you are done with it"},
-		{"drop", "Drop the token and leave the queue (note: actually just an alias of `done`)"},
-		{"yield", "Release the token and swap places with next in line"},
-	})
-
-	s += "\n*If you are in the queue and need to change something:*\n"
-	s += cmdList([][]string{
-		{"delegate <user>", "Delegate your place to someone else (your most recent entry is delegated)"},
-		{"delegate <user> <reason prefix>", "Delegate your place to someone else (match the entry with reason that starts with <reason prefix>)"},
-		{"replace <position> <reason>", "Replace the reason of a queue entry you own"},
-	})
-
-	s += "\n*If you are in the queue and need to leave:*\n"
-	s += cmdList([][]string{
-		{"leave", "Leave the queue (your most recent entry is removed)"},
-		{"leave <position>", "Leave the queue (match the entry at the given position)"},
-	})
-
-	s += "\n*If you need to get rid of somebody who is in the way:*\n"
-	s += cmdList([][]string{
-		{"oust <name>", "Force the token holder to yield to the next in line"},
-		{"boot <name>", "Kick somebody out of the waiting list (their most recent entry is removed)"},
-		{"boot <position> <name>", "Kick somebody out of the waiting list (match the entry at the given position)"},
-	})
-
-	s += "\n*Notifications (from automated systems):*\n"
-	s += cmdList([][]string{
-		{"success", "Notify the token holder and next in line of success and remove the token holder"},
-		{"failure <message>", "Notify the token holder and next in line of failure with a custom error message"},
-	})
-
-	s += "\n*Other useful things to know:*\n"
-	s += cmdList([][]string{
-		{"list", "Show who has the token and who is waiting"},
-		{"help", "Show this text"},
-	})
-	return q, Notification{id, s}
+	log.Printf("%s (%s) %s", c.getNameIDPair(id), reason, text)
 }
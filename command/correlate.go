@@ -0,0 +1,30 @@
+package command
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/doozr/qbot/queue"
+)
+
+// findCorrelated finds the token holder that a correlation key from an automated system refers to
+//
+// The key matches a holder with that entry key (e.g. `#a2y`), or otherwise a holder whose reason contains it as a whole
+// word, ignoring case, such as a branch name or pull request number. The first holder that matches is found.
+func (c QueueCommands) findCorrelated(q queue.Queue, key string) (item queue.Item, ok bool) {
+	holders := q.Holders(c.capacity)
+	entry := strings.ToLower(strings.TrimPrefix(key, "#"))
+	for _, i := range holders {
+		if i.Key != "" && i.Key == entry {
+			return i, true
+		}
+	}
+
+	word := regexp.MustCompile(`(?i)(^|[^\pL\pN])` + regexp.QuoteMeta(key) + `($|[^\pL\pN])`)
+	for _, i := range holders {
+		if word.MatchString(i.Reason) {
+			return i, true
+		}
+	}
+	return
+}
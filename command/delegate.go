@@ -1,27 +1,61 @@
 package command
 
 import (
+	"strings"
+
 	"github.com/doozr/qbot/queue"
 )
 
+// findItemByReason finds the user's most recent entry with a reason that starts with the given text, ignoring case
+func (c QueueCommands) findItemByReason(q queue.Queue, id, prefix string) (item queue.Item, ok bool) {
+	prefix = strings.ToLower(prefix)
+	for ix := len(q) - 1; ix >= 0; ix-- {
+		if q[ix].ID == id && strings.HasPrefix(strings.ToLower(q[ix].Reason), prefix) {
+			return q[ix], true
+		}
+	}
+	return
+}
+
 // Delegate hands over a place in the queue to someone else
+//
+// The entry can be picked by key or position in front of the user to delegate to, or by the start of its reason
+// after the user or with `--reason`. Otherwise the user's most recent entry is delegated. A number given on its own is
+// taken as the name of the user.
 func (c QueueCommands) Delegate(q queue.Queue, ch, owner, args string) (queue.Queue, Notification) {
 	if len(q) == 0 {
 		return q, Notification{ch, c.response.DelegateNoEntry(owner)}
 	}
 
-	position, name, ok := c.parsePosition(args)
-	if !ok {
+	a := parseArgs(args, "reason")
+	i, position, rest, given, ok := queue.Item{}, 0, a, false, false
+	if _, _, keyed := a.key(); keyed || a.len() > 1 {
+		i, position, rest, given, ok = c.findEntry(q, a)
 	}
 
-	id := c.getIDFromName(name)
+	id, name, rest, candidates := c.user(rest)
+	if len(candidates) > 1 {
+		return q, Notification{ch, c.response.UserAmbiguous(owner, name, candidates)}
+	}
 	if id == "" {
 		return q, Notification{ch, c.response.DelegateNoSuchUser(owner, name)}
 	}
 
-	i, ok := c.findByPosition(q, position)
+	prefix, _ := a.option("reason")
+	if !given && prefix == "" {
+		prefix = rest.rest()
+	}
+
 	if !ok {
-		i, ok = c.findItemReverse(q, owner)
+		if _, _, keyed := a.key(); keyed {
+			return q, Notification{ch, c.badEntry(owner, a)}
+		}
+
+		if prefix != "" {
+			i, ok = c.findItemByReason(q, owner, prefix)
+		} else {
+			i, ok = c.findItemReverse(q, owner)
+		}
 		if !ok {
 			return q, Notification{ch, c.response.DelegateNoEntry(owner)}
 		}
@@ -31,7 +65,7 @@ func (c QueueCommands) Delegate(q queue.Queue, ch, owner, args string) (queue.Qu
 		return q, Notification{ch, c.response.NotOwned(owner, position)}
 	}
 
-	isActive := q.Active() == i
+	isActive := q.Holds(i, c.capacity)
 	n := queue.Item{ID: id, Reason: i.Reason}
 
 	if id == c.id {
@@ -131,3 +131,37 @@ func TestDelegate(t *testing.T) {
 		},
 	})
 }
+
+func TestDelegateByKey(t *testing.T) {
+	cmd := command.New(id, name, userCache)
+	testCommand(t, cmd.Delegate, []CommandTest{
+		{
+			test: "delegate entry with key",
+			startQueue: queue.Queue{
+				{ID: "U123", Reason: "Banana", Key: "a00"},
+				{ID: "U456", Reason: "Apple", Key: "b00"},
+				{ID: "U456", Reason: "Pear", Key: "c00"}},
+			channel: "C1A2B3C",
+			user:    "U456",
+			args:    "#b00 andrew",
+			expectedQueue: queue.Queue{
+				{ID: "U123", Reason: "Banana", Key: "a00"},
+				{ID: "U789", Reason: "Apple", Key: "b00"},
+				{ID: "U456", Reason: "Pear", Key: "c00"}},
+			expectedResponse: "<@U456|edward> (Apple) has delegated to <@U789|andrew>",
+		},
+		{
+			test: "do nothing if key not found",
+			startQueue: queue.Queue{
+				{ID: "U123", Reason: "Banana", Key: "a00"},
+				{ID: "U456", Reason: "Apple", Key: "b00"}},
+			channel: "C1A2B3C",
+			user:    "U456",
+			args:    "#c00 andrew",
+			expectedQueue: queue.Queue{
+				{ID: "U123", Reason: "Banana", Key: "a00"},
+				{ID: "U456", Reason: "Apple", Key: "b00"}},
+			expectedResponse: "<@U456|edward> No entry `#c00` was found in the queue",
+		},
+	})
+}
@@ -2,24 +2,22 @@ package command
 
 import "github.com/doozr/qbot/queue"
 
-// Done removes the active user from the queue
+// Done removes the user's token holding entry from the queue
 func (c QueueCommands) Done(q queue.Queue, ch, id, args string) (queue.Queue, Notification) {
 	if len(q) == 0 {
 		return q, Notification{ch, ""}
 	}
 
-	i := q.Active()
-
-	if i.ID != id {
+	i, ok := c.findHolder(q, id)
+	if !ok {
 		return q, Notification{ch, c.response.DoneNotActive(id)}
 	}
 
-	q = q.Remove(i)
+	nq := q.Remove(i)
 	c.logActivity(id, i.Reason, "done")
-	if len(q) > 0 {
-		n := q.Active()
-		c.logActivity(n.ID, n.Reason, "is active")
-		return q, Notification{ch, c.response.Done(i, q)}
+	if p := c.promoted(q, nq); len(p) > 0 {
+		c.logPromoted(p)
+		return nq, Notification{ch, c.response.Done(i, p)}
 	}
-	return q, Notification{ch, c.response.DoneNoOthers(i)}
+	return nq, Notification{ch, c.response.DoneNoOthers(i)}
 }
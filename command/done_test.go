@@ -44,3 +44,36 @@ func TestDone(t *testing.T) {
 		},
 	})
 }
+
+func TestDoneSharedToken(t *testing.T) {
+	cmd := command.New(id, name, userCache)
+	testChannelCommand(t, cmd.Named(command.QueueCommands.Done), []TokenTest{
+		{
+			test:             "second holder drops token and gives it to the next in line",
+			startTokens:      queue.Tokens{"envs": {Capacity: 2, Queue: queue.Queue{{ID: "U123", Reason: "Banana"}, {ID: "U456", Reason: "Apple"}, {ID: "U789", Reason: "Next up"}}}},
+			channel:          "C1A2B3C",
+			user:             "U456",
+			args:             "envs",
+			expectedTokens:   queue.Tokens{"envs": {Capacity: 2, Queue: queue.Queue{{ID: "U123", Reason: "Banana"}, {ID: "U789", Reason: "Next up"}}}},
+			expectedResponse: "<@U456|edward> (Apple) has finished with the `envs` token\n*<@U789|andrew> (Next up) now has the `envs` token*",
+		},
+		{
+			test:             "holder drops token with nobody waiting",
+			startTokens:      queue.Tokens{"envs": {Capacity: 2, Queue: queue.Queue{{ID: "U123", Reason: "Banana"}, {ID: "U456", Reason: "Apple"}}}},
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "envs",
+			expectedTokens:   queue.Tokens{"envs": {Capacity: 2, Queue: queue.Queue{{ID: "U456", Reason: "Apple"}}}},
+			expectedResponse: "<@U123|craig> (Banana) has finished with the `envs` token\nThe `envs` token is up for grabs",
+		},
+		{
+			test:             "warns if user is waiting",
+			startTokens:      queue.Tokens{"envs": {Capacity: 2, Queue: queue.Queue{{ID: "U123", Reason: "Banana"}, {ID: "U456", Reason: "Apple"}, {ID: "U789", Reason: "Next up"}}}},
+			channel:          "C1A2B3C",
+			user:             "U789",
+			args:             "envs",
+			expectedTokens:   queue.Tokens{"envs": {Capacity: 2, Queue: queue.Queue{{ID: "U123", Reason: "Banana"}, {ID: "U456", Reason: "Apple"}, {ID: "U789", Reason: "Next up"}}}},
+			expectedResponse: "<@U789|andrew> You cannot be done if you don't have the token",
+		},
+	})
+}
@@ -2,20 +2,40 @@ package command
 
 import "github.com/doozr/qbot/queue"
 
-// Failure notifies token holder and next in line of a problem
+// Failure notifies token holders and next in line of a problem
+//
+// Given a correlation key with `--key`, only the token holder that the key matches is notified along with the next in
+// line, and nobody is notified if no holder matches.
 func (c QueueCommands) Failure(q queue.Queue, ch, id, args string) (queue.Queue, Notification) {
 	c.logActivity(id, "notification", "failure")
 
-	if len(q) == 0 {
-		return q, Notification{ch, c.response.FailureNotificationEmptyQueue(id, args)}
+	a := parseArgs(args, "key")
+	message := a.rest()
+	key, _ := a.option("key")
+
+	notify := q.Holders(c.capacity)
+	if key != "" {
+		i, ok := c.findCorrelated(q, key)
+		if !ok {
+			return q, Notification{ch, c.response.FailureMismatch(id, key, notify, message)}
+		}
+		notify = []queue.Item{i}
+	} else if len(q) == 0 {
+		return q, Notification{ch, c.response.FailureNotificationEmptyQueue(id, message)}
+	}
+
+	if w := q.WaitingBehind(c.capacity); len(w) > 0 {
+		notify = append(notify, w[0])
 	}
 
-	var ids []string
-	if len(q) > 1 && q[0].ID != q[1].ID {
-		ids = []string{q[0].ID, q[1].ID}
-	} else {
-		ids = []string{q[0].ID}
+	ids := []string{}
+	seen := map[string]bool{}
+	for _, i := range notify {
+		if !seen[i.ID] {
+			seen[i.ID] = true
+			ids = append(ids, i.ID)
+		}
 	}
 
-	return q, Notification{ch, c.response.FailureNotification(id, ids, args)}
+	return q, Notification{ch, c.response.FailureNotification(id, key, ids, message)}
 }
@@ -39,3 +39,50 @@ func TestFailure(t *testing.T) {
 		},
 	})
 }
+
+func TestFailureSharedToken(t *testing.T) {
+	cmd := command.New(id, name, userCache)
+	testChannelCommand(t, cmd.Named(command.QueueCommands.Failure), []TokenTest{
+		{
+			test:             "notify every holder and next in line",
+			startTokens:      queue.Tokens{"envs": {Capacity: 2, Queue: queue.Queue{{ID: "U123", Reason: "Banana"}, {ID: "U456", Reason: "Apple"}, {ID: "U789", Reason: "Next up"}}}},
+			channel:          "C1A2B3C",
+			user:             "U12345",
+			args:             "envs Build broke",
+			expectedTokens:   queue.Tokens{"envs": {Capacity: 2, Queue: queue.Queue{{ID: "U123", Reason: "Banana"}, {ID: "U456", Reason: "Apple"}, {ID: "U789", Reason: "Next up"}}}},
+			expectedResponse: "<@U123|craig> <@U456|edward> <@U789|andrew> Received a failure notification from <@U12345|the_bot_name>: Build broke",
+		},
+	})
+}
+
+func TestFailureCorrelated(t *testing.T) {
+	cmd := command.New(id, name, userCache)
+	start := queue.Tokens{"envs": {Capacity: 2, Queue: queue.Queue{
+		{ID: "U123", Reason: "PR 1234"},
+		{ID: "U456", Reason: "PR 123"},
+		{ID: "U789", Reason: "Next up"}}}}
+
+	testChannelCommand(t, cmd.Named(command.QueueCommands.Failure), []TokenTest{
+		{
+			test:           "notify the matching holder and next in line",
+			startTokens:    start,
+			channel:        "C1A2B3C",
+			user:           "U12345",
+			args:           "envs --key 123 tests failed",
+			expectedTokens: start,
+			expectedResponse: "<@U456|edward> <@U789|andrew> Received a failure notification from <@U12345|the_bot_name> " +
+				"for `123`: tests failed",
+		},
+		{
+			test:           "report a key that matches nobody holding the token",
+			startTokens:    start,
+			channel:        "C1A2B3C",
+			user:           "U12345",
+			args:           "envs tests failed --key=99",
+			expectedTokens: start,
+			expectedResponse: "Received a failure notification from <@U12345|the_bot_name> for `99`, " +
+				"but nobody holding the `envs` token matches it " +
+				"(<@U123|craig> (PR 1234) and <@U456|edward> (PR 123) have it): tests failed",
+		},
+	})
+}
@@ -0,0 +1,34 @@
+package command
+
+import (
+	"time"
+
+	"github.com/doozr/qbot/queue"
+)
+
+// HoldReminder privately reminds a holder of a token that they have held it for too long
+func (c QueueCommands) HoldReminder(t queue.Tokens, ch, name string, i queue.Item, held, remaining time.Duration) Notification {
+	c = c.forChannel(ch)
+	c = c.forToken(name, t.Capacity(name))
+	return Notification{i.ID, c.response.HoldReminder(i, held, remaining)}
+}
+
+// HoldEscalation tells the channel that a holder of a token has held it for too long
+func (c QueueCommands) HoldEscalation(t queue.Tokens, ch, name string, i queue.Item, held, remaining time.Duration) Notification {
+	c = c.forChannel(ch)
+	c = c.forToken(name, t.Capacity(name))
+	return Notification{ch, c.response.HoldEscalation(i, held, remaining)}
+}
+
+// HoldExpired ousts a holder of a token once they have held it for too long
+func (c QueueCommands) HoldExpired(t queue.Tokens, ch, name string, i queue.Item) (queue.Tokens, Notification) {
+	c = c.forChannel(ch)
+	c = c.forToken(name, t.Capacity(name))
+	q := t.Get(name)
+	if !q.Holds(i, c.capacity) {
+		return t, Notification{ch, ""}
+	}
+
+	q, n := c.oust(q, ch, c.id, i)
+	return t.Set(name, q), n
+}
@@ -0,0 +1,53 @@
+package command_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/doozr/qbot/command"
+	"github.com/doozr/qbot/queue"
+)
+
+func TestHoldReminder(t *testing.T) {
+	cmd := command.New(id, name, userCache)
+	i := queue.Item{ID: "U123", Reason: "Banana"}
+	tokens := queue.Tokens{"staging": {Queue: queue.Queue{i}}}
+
+	n := cmd.HoldReminder(tokens, "C1234", "staging", i, 2*time.Hour, 15*time.Minute)
+	assertResponse(t, "remind holder privately", "U123",
+		"You have had the `staging` token for 2h (Banana). Please say `done` if you have finished with it, otherwise you will be ousted in 15m", n)
+}
+
+func TestHoldEscalation(t *testing.T) {
+	cmd := command.New(id, name, userCache)
+	i := queue.Item{ID: "U123", Reason: "Banana"}
+	tokens := queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{i}}}
+
+	n := cmd.HoldEscalation(tokens, "C1A2B3C", queue.DefaultToken, i, 2*time.Hour, 15*time.Minute)
+	assertResponse(t, "tell channel", "C1A2B3C",
+		"<@U123|craig> (Banana) has had the token for 2h and will be ousted in 15m", n)
+}
+
+func TestHoldExpired(t *testing.T) {
+	cmd := command.New(id, name, userCache)
+	testChannelCommand(t, func(tokens queue.Tokens, ch, user, args string) (queue.Tokens, command.Notification) {
+		return cmd.HoldExpired(tokens, ch, args, queue.Item{ID: "U123", Reason: "Banana"})
+	}, []TokenTest{
+		{
+			test:             "oust holder to next in line",
+			startTokens:      queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{{ID: "U123", Reason: "Banana"}, {ID: "U456", Reason: "Next"}}}},
+			channel:          "C1A2B3C",
+			args:             queue.DefaultToken,
+			expectedTokens:   queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{{ID: "U456", Reason: "Next"}, {ID: "U123", Reason: "Banana"}}}},
+			expectedResponse: "<@U12345|the_bot_name> ousted <@U123|craig> (Banana)\n*<@U456|edward> (Next) now has the token*",
+		},
+		{
+			test:             "do nothing if nobody holds the token",
+			startTokens:      queue.Tokens{"staging": {Queue: queue.Queue{}}},
+			channel:          "C1A2B3C",
+			args:             "staging",
+			expectedTokens:   queue.Tokens{"staging": {Queue: queue.Queue{}}},
+			expectedResponse: "",
+		},
+	})
+}
@@ -4,7 +4,8 @@ import "github.com/doozr/qbot/queue"
 
 // Join adds an item to the queue
 func (c QueueCommands) Join(q queue.Queue, ch, id, args string) (queue.Queue, Notification) {
-	i := queue.Item{ID: id, Reason: args}
+	reason := parseArgs(args).rest()
+	i := queue.Item{ID: id, Reason: reason}
 
 	if i.Reason == "" {
 		return q, Notification{ch, c.response.JoinNoReason(i)}
@@ -15,9 +16,9 @@ func (c QueueCommands) Join(q queue.Queue, ch, id, args string) (queue.Queue, No
 	}
 
 	q = q.Add(i)
-	c.logActivity(id, args, "joined")
-	if q.Active() == i {
-		c.logActivity(id, args, "is active")
+	c.logActivity(id, reason, "joined")
+	if q.Holds(i, c.capacity) {
+		c.logActivity(id, reason, "is active")
 		return q, Notification{ch, c.response.JoinActive(i)}
 	}
 
@@ -66,3 +66,27 @@ func TestJoin(t *testing.T) {
 		},
 	})
 }
+
+func TestJoinSharedToken(t *testing.T) {
+	cmd := command.New(id, name, userCache)
+	testChannelCommand(t, cmd.Named(command.QueueCommands.Join), []TokenTest{
+		{
+			test:             "join takes a free place",
+			startTokens:      queue.Tokens{"envs": {Capacity: 2, Queue: queue.Queue{{ID: "U123", Reason: "Banana"}}}},
+			channel:          "C1A2B3C",
+			user:             "U456",
+			args:             "envs Apple",
+			expectedTokens:   queue.Tokens{"envs": {Capacity: 2, Queue: queue.Queue{{ID: "U123", Reason: "Banana"}, {ID: "U456", Reason: "Apple"}}}},
+			expectedResponse: "*<@U456|edward> (Apple) now has the `envs` token*",
+		},
+		{
+			test:             "join is next in line once every place is taken",
+			startTokens:      queue.Tokens{"envs": {Capacity: 2, Queue: queue.Queue{{ID: "U123", Reason: "Banana"}, {ID: "U456", Reason: "Apple"}}}},
+			channel:          "C1A2B3C",
+			user:             "U789",
+			args:             "envs Pear",
+			expectedTokens:   queue.Tokens{"envs": {Capacity: 2, Queue: queue.Queue{{ID: "U123", Reason: "Banana"}, {ID: "U456", Reason: "Apple"}, {ID: "U789", Reason: "Pear"}}}},
+			expectedResponse: "<@U789|andrew> (Pear) is now next in line for the `envs` token",
+		},
+	})
+}
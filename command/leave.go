@@ -8,13 +8,11 @@ func (c QueueCommands) Leave(q queue.Queue, ch, id, args string) (queue.Queue, N
 		return q, Notification{ch, ""}
 	}
 
-	position, _, ok := c.parsePosition(args)
-
-	var i queue.Item
-	if ok {
-		i, ok = c.findByPosition(q, position)
+	a := parseArgs(args)
+	i, position, _, given, ok := c.findEntry(q, a)
+	if given {
 		if !ok {
-			return q, Notification{ch, c.response.BadIndex(id)}
+			return q, Notification{ch, c.badEntry(id, a)}
 		}
 	} else {
 		i, ok = c.findItemReverse(q, id)
@@ -27,7 +25,7 @@ func (c QueueCommands) Leave(q queue.Queue, ch, id, args string) (queue.Queue, N
 		return q, Notification{ch, c.response.NotOwned(id, position)}
 	}
 
-	if q.Active() == i {
+	if q.Holds(i, c.capacity) {
 		return q, Notification{ch, c.response.LeaveActive(i)}
 	}
 
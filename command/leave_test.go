@@ -94,3 +94,49 @@ func TestLeave(t *testing.T) {
 		},
 	})
 }
+
+func TestLeaveByKey(t *testing.T) {
+	cmd := command.New(id, name, userCache)
+	testCommand(t, cmd.Leave, []CommandTest{
+		{
+			test: "remove entry with key",
+			startQueue: queue.Queue{
+				{ID: "U456", Reason: "Already here", Key: "a00"},
+				{ID: "U123", Reason: "First", Key: "b00"},
+				{ID: "U123", Reason: "Last", Key: "c00"}},
+			channel: "C1A2B3C",
+			user:    "U123",
+			args:    "#B00",
+			expectedQueue: queue.Queue{
+				{ID: "U456", Reason: "Already here", Key: "a00"},
+				{ID: "U123", Reason: "Last", Key: "c00"}},
+			expectedResponse: "<@U123|craig> (First) has left the queue",
+		},
+		{
+			test: "do nothing if key not found",
+			startQueue: queue.Queue{
+				{ID: "U456", Reason: "Already here", Key: "a00"},
+				{ID: "U123", Reason: "First", Key: "b00"}},
+			channel: "C1A2B3C",
+			user:    "U123",
+			args:    "#zzz",
+			expectedQueue: queue.Queue{
+				{ID: "U456", Reason: "Already here", Key: "a00"},
+				{ID: "U123", Reason: "First", Key: "b00"}},
+			expectedResponse: "<@U123|craig> No entry `#zzz` was found in the queue",
+		},
+		{
+			test: "warns if entry with key is not owned by user",
+			startQueue: queue.Queue{
+				{ID: "U456", Reason: "Already here", Key: "a00"},
+				{ID: "U789", Reason: "First", Key: "b00"}},
+			channel: "C1A2B3C",
+			user:    "U123",
+			args:    "#b00",
+			expectedQueue: queue.Queue{
+				{ID: "U456", Reason: "Already here", Key: "a00"},
+				{ID: "U789", Reason: "First", Key: "b00"}},
+			expectedResponse: "<@U123|craig> You are not 2nd in line",
+		},
+	})
+}
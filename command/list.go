@@ -2,20 +2,96 @@ package command
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/doozr/qbot/queue"
 )
 
+func (c QueueCommands) list(q queue.Queue) string {
+	if len(q) == 0 {
+		return c.response.ListEmpty()
+	}
+
+	lines := []string{}
+	for ix, i := range q.Holders(c.capacity) {
+		lines = append(lines, c.response.ListHolder(i, ix+1))
+	}
+	for ix, i := range q.WaitingBehind(c.capacity) {
+		lines = append(lines, c.response.ListWaiting(i, ix+c.capacity+1))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// key shows the key of an entry so that it can be given to commands, if it has one yet
+func key(i queue.Item) string {
+	if i.Key == "" {
+		return ""
+	}
+	return fmt.Sprintf(" `#%s`", i.Key)
+}
+
+// listToken lists who has a token, who is waiting and who has reserved it for later
+func (c QueueCommands) listToken(t queue.Tokens, name string) string {
+	c = c.forToken(name, t.Capacity(name))
+	s := c.list(t.Get(name))
+	for _, r := range t.Reservations(name) {
+		if !r.Started {
+			s += "\n" + c.response.ListReserved(r)
+		}
+	}
+	return s
+}
+
+// listTokens lists every token, leaving out the default token if it is unused and there are others
+func (c QueueCommands) listTokens(t queue.Tokens) string {
+	names := t.Names()
+	if len(names) == 1 {
+		return c.listToken(t, queue.DefaultToken)
+	}
+
+	sections := []string{}
+	for _, name := range names {
+		if name == queue.DefaultToken && len(t.Get(name)) == 0 && len(t.Reservations(name)) == 0 {
+			continue
+		}
+		heading := c.forToken(name, t.Capacity(name)).response.ListHeading()
+		sections = append(sections, fmt.Sprintf("%s\n%s", heading, c.listToken(t, name)))
+	}
+	return strings.Join(sections, "\n\n")
+}
+
 // List shows who has the token and who is waiting
 func (c QueueCommands) List(q queue.Queue, ch, id, args string) (queue.Queue, Notification) {
-	if len(q) == 0 {
-		return q, Notification{ch, "Nobody has the token, and nobody is waiting"}
+	return q, Notification{ch, c.list(q)}
+}
+
+// ListTokens shows who has the named token, who is waiting and who has reserved it, or every token if none is named
+func (c QueueCommands) ListTokens(t queue.Tokens, ch, id, args string) (queue.Tokens, Notification) {
+	c = c.forChannel(ch)
+	name := strings.ToLower(parseArgs(args).first())
+	if name != "" && t.Exists(name) {
+		return t, Notification{ch, c.listToken(t, name)}
+	}
+	return t, Notification{ch, c.listTokens(t)}
+}
+
+// ListAll shows who has the token and who is waiting in every channel the user is known to be in
+func (c QueueCommands) ListAll(qs queue.Channels, ch, id, args string) Notification {
+	c = c.forChannel(ch)
+	channels := []string{}
+	for _, channel := range c.knownChannels(qs, id) {
+		if _, ok := qs[channel]; ok {
+			channels = append(channels, channel)
+		}
+	}
+
+	if len(channels) == 0 {
+		return Notification{ch, c.response.ListEmpty()}
 	}
 
-	a := q.Active()
-	s := fmt.Sprintf("*%d: %s (%s) has the token*", 1, c.userCache.GetUserName(a.ID), a.Reason)
-	for ix, i := range q.Waiting() {
-		s += fmt.Sprintf("\n%d: %s (%s)", ix+2, c.userCache.GetUserName(i.ID), i.Reason)
+	sections := []string{}
+	for _, channel := range channels {
+		sections = append(sections, fmt.Sprintf("<#%s>\n%s", channel, c.listTokens(qs[channel])))
 	}
-	return q, Notification{ch, s}
+	return Notification{ch, strings.Join(sections, "\n\n")}
 }
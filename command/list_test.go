@@ -2,7 +2,9 @@ package command_test
 
 import (
 	"testing"
+	"time"
 
+	"github.com/doozr/qbot/activity"
 	"github.com/doozr/qbot/command"
 	"github.com/doozr/qbot/queue"
 )
@@ -36,3 +38,73 @@ func TestList(t *testing.T) {
 		},
 	})
 }
+
+func TestListShowsKeys(t *testing.T) {
+	cmd := command.New(id, name, userCache)
+	_, n := cmd.List(queue.Queue{{ID: "U123", Reason: "Active", Key: "a00"}, {ID: "U456", Reason: "Waiting", Key: "b00"}}, "C1A2B3C", "U789", "")
+	assertResponse(t, "list with keys", "C1A2B3C",
+		"*1: craig (Active) has the token* `#a00`\n2: edward (Waiting) `#b00`", n)
+}
+
+func TestListTokens(t *testing.T) {
+	cmd := command.New(id, name, userCache)
+	tokens := queue.Tokens{
+		queue.DefaultToken: {Queue: queue.Queue{{ID: "U123", Reason: "Active"}}},
+		"staging":          {Queue: queue.Queue{{ID: "U456", Reason: "Staging"}, {ID: "U789", Reason: "Waiting"}}},
+		"perf":             {Queue: queue.Queue{}},
+	}
+
+	_, n := cmd.ListTokens(tokens, "C1A2B3C", "U789", "")
+	assertResponse(t, "list every token", "C1A2B3C",
+		"`default`\n*1: craig (Active) has the token*\n\n`perf`\nNobody has the token, and nobody is waiting\n\n`staging`\n*1: edward (Staging) has the token*\n2: andrew (Waiting)", n)
+
+	_, n = cmd.ListTokens(tokens, "C1A2B3C", "U789", "Staging")
+	assertResponse(t, "list named token", "C1A2B3C",
+		"*1: edward (Staging) has the token*\n2: andrew (Waiting)", n)
+
+	_, n = cmd.ListTokens(queue.Tokens{"perf": {Queue: queue.Queue{}}}, "C1A2B3C", "U789", "")
+	assertResponse(t, "leave out unused default token", "C1A2B3C",
+		"`perf`\nNobody has the token, and nobody is waiting", n)
+
+	_, n = cmd.ListTokens(queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{{ID: "U123", Reason: "Active"}}}}, "C1A2B3C", "U789", "")
+	assertResponse(t, "list only default token as before", "C1A2B3C",
+		"*1: craig (Active) has the token*", n)
+
+	_, n = cmd.ListTokens(queue.Tokens{
+		queue.DefaultToken: {Queue: queue.Queue{{ID: "U123", Reason: "Active"}}},
+		"envs":             {Capacity: 2, Queue: queue.Queue{{ID: "U123", Reason: "One"}, {ID: "U456", Reason: "Two"}, {ID: "U789", Reason: "Waiting"}}},
+	}, "C1A2B3C", "U789", "")
+	assertResponse(t, "list every holder of a shared token", "C1A2B3C",
+		"`default`\n*1: craig (Active) has the token*\n\n`envs` (up to 2 at once)\n*1: craig (One) has the token*\n*2: edward (Two) has the token*\n3: andrew (Waiting)", n)
+
+	start := time.Date(2017, 3, 14, 14, 0, 0, 0, time.UTC)
+	_, n = cmd.ListTokens(queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{{ID: "U123", Reason: "Active"}}, Reservations: queue.Reservations{
+		{ID: "U123", Reason: "Started", Start: start.Add(-time.Hour), End: start, Started: true},
+		{ID: "U456", Reason: "Release", Start: start, End: start.Add(time.Hour)},
+	}}}, "C1A2B3C", "U789", "")
+	assertResponse(t, "list upcoming reservations", "C1A2B3C",
+		"*1: craig (Active) has the token*\n_Reserved Tue 14 Mar 14:00-15:00: edward (Release)_", n)
+}
+
+func TestListAll(t *testing.T) {
+	seen := activity.New()
+	seen.Seen("C2", "U789", time.Now())
+	cmd := command.New(id, name, userCache).WithActivity(seen)
+
+	qs := queue.Channels{
+		"C3": queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{{ID: "U456", Reason: "Private channel"}}}},
+		"C2": queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{{ID: "U456", Reason: "Second channel"}}}},
+		"C1": queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{{ID: "U123", Reason: "Active"}, {ID: "U789", Reason: "Waiting"}}}},
+	}
+	n := cmd.ListAll(qs, "D1A2B3C", "U789", "")
+	assertResponse(t, "list every channel the user is in, in order", "D1A2B3C",
+		"<#C1>\n*1: craig (Active) has the token*\n2: andrew (Waiting)\n\n<#C2>\n*1: edward (Second channel) has the token*", n)
+
+	n = cmd.ListAll(qs, "D1A2B3C", "U123", "")
+	assertResponse(t, "leave out channels the user has not been seen in", "D1A2B3C",
+		"<#C1>\n*1: craig (Active) has the token*\n2: andrew (Waiting)", n)
+
+	n = cmd.ListAll(queue.Channels{}, "D1A2B3C", "U789", "")
+	assertResponse(t, "gives friendly message if no queues", "D1A2B3C",
+		"Nobody has the token, and nobody is waiting", n)
+}
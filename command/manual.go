@@ -0,0 +1,188 @@
+package command
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/doozr/qbot/queue"
+)
+
+// topic is the help for one command
+//
+// The wording of a topic is in the catalogue, under `About` followed by the capitalised name of the command for the
+// one line summary (e.g. `AboutJoin`), and `Explain` followed by the same for the detail given by `help <command>`.
+type topic struct {
+	name     string
+	section  string
+	usages   []string
+	examples []string
+}
+
+// sections are the headings that the summary of every command is split into, in order
+//
+// Each is worded in the catalogue under `Section` followed by its name.
+var sections = []string{"Need", "Have", "Change", "Leave", "Rid", "Tokens", "Notifications", "Other"}
+
+// manual is the help for every command, in the order that the summary gives it
+var manual = []topic{
+	{"join", "Need", []string{"join <reason>"}, []string{"join fixing the build", `join "release 1.2" --token staging`}},
+	{"barge", "Need", []string{"barge <reason>", "barge <position>"}, []string{"barge hotfix for prod", "barge #a2y"}},
+	{"reserve", "Need", []string{"reserve <start> <duration> <reason>"},
+		[]string{"reserve 14:00 1h release", "reserve 2017-03-14T09:30 90m demo"}},
+	{"done", "Have", []string{"done"}, nil},
+	{"drop", "Have", []string{"drop"}, nil},
+	{"yield", "Have", []string{"yield"}, nil},
+	{"delegate", "Change", []string{"delegate <user>", "delegate <user> <reason prefix>", "delegate <#key> <user>"},
+		[]string{"delegate @craig", "delegate craig apple", `delegate craig --reason "fix the build"`, "delegate #a2y craig"}},
+	{"replace", "Change", []string{"replace <position> <reason>"}, []string{"replace 3 fixing the tests too"}},
+	{"move", "Change", []string{"move <position> <to>"}, []string{"move 4 2", "move #a2y 2"}},
+	{"swap", "Change", []string{"swap <position>"}, []string{"swap 2", "swap #a2y"}},
+	{"accept", "Change", []string{"accept [<#key>]"}, []string{"accept", "accept #a2y"}},
+	{"decline", "Change", []string{"decline [<#key>]"}, []string{"decline", "decline #a2y"}},
+	{"leave", "Leave", []string{"leave", "leave <position>"}, []string{"leave", "leave 3", "leave #a2y"}},
+	{"oust", "Rid", []string{"oust <name>", "oust <#key>"}, []string{"oust @craig", "oust #a2y"}},
+	{"boot", "Rid", []string{"boot <name>", "boot <position> <name>", "boot <#key>"},
+		[]string{"boot @craig", "boot 3 craig", "boot craig --position 3", "boot #a2y"}},
+	{"create", "Tokens", []string{"create <token>", "create <token> <holders>"}, []string{"create staging", "create testers 3"}},
+	{"capacity", "Tokens", []string{"capacity <holders>"}, []string{"capacity 2", "capacity staging 3"}},
+	{"delete", "Tokens", []string{"delete <token>"}, []string{"delete staging"}},
+	{"success", "Notifications", []string{"success", "success --key <key>"},
+		[]string{"success", "success --key feature/login", "success --key #a2y"}},
+	{"failure", "Notifications", []string{"failure <message>", "failure --key <key> <message>"},
+		[]string{"failure the build is broken", "failure --key 123 the build is broken"}},
+	{"list", "Other", []string{"list", "list <token>"}, []string{"list", "list staging"}},
+	{"notify", "Other", []string{"notify on|next|off"}, []string{"notify next"}},
+	{"stats", "Other", []string{"stats [day|week|month]"}, []string{"stats", "stats month", "stats staging day"}},
+	{"where", "Other", []string{"where"}, nil},
+	{"undo", "Other", []string{"undo"}, nil},
+	{"help", "Other", []string{"help", "help <command>"}, []string{"help", "help barge"}},
+}
+
+// Documented is true if the command has help
+func Documented(name string) bool {
+	_, ok := findTopic(name)
+	return ok
+}
+
+// findTopic finds the help for a command
+func findTopic(name string) (t topic, ok bool) {
+	for _, t := range manual {
+		if t.name == name {
+			return t, true
+		}
+	}
+	return
+}
+
+// WithRestricted returns a copy of the commands that tells users the given commands may only be used by admins
+//
+// This is only used for help. The commands are restricted by wrapping them with AdminOnly.
+func (c QueueCommands) WithRestricted(names []string) QueueCommands {
+	c.restricted = map[string]bool{}
+	for _, name := range names {
+		c.restricted[name] = true
+	}
+	return c
+}
+
+// helpTopic is a command as help gives it
+type helpTopic struct {
+	Name        string
+	Usages      []string
+	Examples    []string
+	About       string
+	Explain     string
+	PrivateOnly bool
+	PublicOnly  bool
+	Restricted  bool
+}
+
+// helpSection is a heading of the summary and the commands under it
+type helpSection struct {
+	Heading string
+	Topics  []helpTopic
+}
+
+// describe gives the help for a command that can be used in public, in private or both
+//
+// A command without a topic in the manual is still given, with only its name as usage, so that help never leaves out
+// a command the bot knows.
+func (c QueueCommands) describe(ch, name string, public, private map[string]bool) (h helpTopic, section string) {
+	h = helpTopic{
+		Name:        name,
+		Usages:      []string{name},
+		PrivateOnly: private[name] && !public[name],
+		PublicOnly:  public[name] && !private[name],
+		Restricted:  public[name] && c.restricted[name] && c.hasAdmins(ch),
+	}
+	section = "Other"
+
+	if t, ok := findTopic(name); ok {
+		section = t.section
+		h.Usages = t.usages
+		h.Examples = t.examples
+		h.About = c.response.render("About"+capitalise(name), nil)
+		h.Explain = c.response.render("Explain"+capitalise(name), nil)
+	}
+	return
+}
+
+// Help explains the commands that can be used in public and private
+//
+// Without arguments it sums up every command, and given the name of a command it goes into detail about that one.
+// Only the commands in the lists given are explained, so that help always matches what the bot will actually do.
+func (c QueueCommands) Help(public, private []string) Command {
+	isPublic, isPrivate := map[string]bool{}, map[string]bool{}
+	for _, name := range public {
+		isPublic[name] = true
+	}
+	for _, name := range private {
+		isPrivate[name] = true
+	}
+
+	names := []string{}
+	for name := range isPublic {
+		names = append(names, name)
+	}
+	for name := range isPrivate {
+		if !isPublic[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return func(q queue.Queue, ch, id, args string) (queue.Queue, Notification) {
+		c := c.forChannel(ch)
+
+		name := strings.ToLower(parseArgs(args).first())
+		if name != "" {
+			if !isPublic[name] && !isPrivate[name] {
+				return q, Notification{ch, c.response.HelpNotFound(id, name)}
+			}
+			h, _ := c.describe(ch, name, isPublic, isPrivate)
+			return q, Notification{id, c.response.HelpTopic(h)}
+		}
+
+		bySection := map[string][]helpTopic{}
+		for _, t := range manual {
+			if isPublic[t.name] || isPrivate[t.name] {
+				h, section := c.describe(ch, t.name, isPublic, isPrivate)
+				bySection[section] = append(bySection[section], h)
+			}
+		}
+		for _, name := range names {
+			if !Documented(name) {
+				h, section := c.describe(ch, name, isPublic, isPrivate)
+				bySection[section] = append(bySection[section], h)
+			}
+		}
+
+		summary := []helpSection{}
+		for _, s := range sections {
+			if len(bySection[s]) > 0 {
+				summary = append(summary, helpSection{c.response.render("Section"+s, nil), bySection[s]})
+			}
+		}
+		return q, Notification{id, c.response.Help(c.name, summary)}
+	}
+}
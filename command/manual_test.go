@@ -0,0 +1,78 @@
+package command_test
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/doozr/qbot/command"
+	"github.com/doozr/qbot/queue"
+)
+
+func TestHelpSummary(t *testing.T) {
+	cmd := New(id, name, userCache).WithAdmins([]string{"U456"}).WithRestricted([]string{"boot"})
+	fn := cmd.Help([]string{"join", "boot", "list", "yes", "help"}, []string{"list", "where", "help"})
+
+	_, r := fn(queue.Queue{}, "C1234", "U123", "")
+	if r.Channel != "U123" {
+		t.Fatal("Expected help to be sent to the user, got ", r)
+	}
+	for _, line := range []string{
+		"`join <reason>` - Join the queue and give a reason why\n",
+		"`boot <name>`, `boot <position> <name>`, `boot <#key>` - Kick somebody out of the waiting list (admins only)\n",
+		"`where` - Show where each of your entries is in the queues and how much longer you should have to wait (direct message only)\n",
+		"*Other useful things to know:*\n`list`, `list <token>` - Show who has each token and who is waiting\n",
+		"`yes`\n",
+	} {
+		if !strings.Contains(r.Message, line) {
+			t.Fatalf("Expected help to contain %q, got %s", line, r.Message)
+		}
+	}
+	if strings.Contains(r.Message, "`barge") || strings.Contains(r.Message, "have done with it") {
+		t.Fatal("Expected commands that are not given to be left out, got ", r.Message)
+	}
+}
+
+func TestHelpTopic(t *testing.T) {
+	cmd := New(id, name, userCache)
+	fn := cmd.Help([]string{"barge", "help"}, []string{"where", "help"})
+
+	_, r := fn(queue.Queue{}, "C1234", "U123", "Barge")
+	if r.Channel != "U123" ||
+		!strings.HasPrefix(r.Message, "`barge <reason>`\n`barge <position>`\nBarge to the front of the queue") ||
+		!strings.Contains(r.Message, "you cannot barge an entry that belongs to somebody else") ||
+		!strings.Contains(r.Message, "*Examples:*\n`barge hotfix for prod`\n`barge #a2y`\n") ||
+		!strings.HasSuffix(r.Message, "_`barge` only works in a channel_\n") {
+		t.Fatal("Expected detail about barge, got ", r)
+	}
+
+	_, r = fn(queue.Queue{}, "D1234", "U123", "where")
+	if !strings.HasSuffix(r.Message, "_`where` only works in a direct message to me_\n") {
+		t.Fatal("Expected where to be direct message only, got ", r)
+	}
+
+	_, r = fn(queue.Queue{}, "C1234", "U123", "join")
+	assertResponse(t, "command that is not given", "C1234",
+		"<@U123|craig> There is no `join` command; say `help` to see what I can do", r)
+}
+
+func TestHelpTopicRestricted(t *testing.T) {
+	cmd := New(id, name, userCache).WithRestricted([]string{"boot"})
+	fn := cmd.Help([]string{"boot"}, nil)
+
+	_, r := fn(queue.Queue{}, "C1234", "U123", "boot")
+	if strings.Contains(r.Message, "admins") {
+		t.Fatal("Expected no restriction without admins, got ", r.Message)
+	}
+
+	fn = cmd.WithAdmins([]string{"U456"}).Help([]string{"boot"}, nil)
+	_, r = fn(queue.Queue{}, "C1234", "U123", "boot")
+	if !strings.Contains(r.Message, "_Only admins may use `boot` in channels that have admins_\n") {
+		t.Fatal("Expected restriction, got ", r.Message)
+	}
+
+	fn = cmd.WithAdmins([]string{"C5678:U456"}).Help([]string{"boot"}, nil)
+	_, r = fn(queue.Queue{}, "C1234", "U123", "boot")
+	if strings.Contains(r.Message, "admins") {
+		t.Fatal("Expected no restriction where only another channel has admins, got ", r.Message)
+	}
+}
@@ -0,0 +1,167 @@
+package command
+
+import (
+	"github.com/doozr/qbot/queue"
+)
+
+// awaiting finds who has yet to agree to a request, which is everybody else that the entry would move past, or the
+// owner of the entry it would swap with
+//
+// ok is false if the entry or the entry it is moving in front of or swapping with has left the queue.
+func (c QueueCommands) awaiting(q queue.Queue, r queue.Request) (ids []string, ok bool) {
+	from, to := q.IndexOf(r.Item), q.IndexOf(r.Target)
+	if from < 0 || to < 0 {
+		return
+	}
+
+	ids = []string{}
+	if r.Swap {
+		if r.Target.ID != r.ID && !r.HasApproved(r.Target.ID) {
+			ids = append(ids, r.Target.ID)
+		}
+		return ids, true
+	}
+
+	seen := map[string]bool{r.ID: true}
+	for ix := to; ix < from; ix++ {
+		owner := q[ix].ID
+		if !seen[owner] && !r.HasApproved(owner) {
+			ids = append(ids, owner)
+		}
+		seen[owner] = true
+	}
+	return ids, true
+}
+
+// moved moves the entry in front of the one named by the request and lists the queue that results
+func (c QueueCommands) moved(t queue.Tokens, ch, name string, r queue.Request) (queue.Tokens, Notification) {
+	q := t.Get(name)
+	from, to := q.IndexOf(r.Item), q.IndexOf(r.Target)
+	nq := q.Move(from, to)
+
+	t = t.Set(name, nq).SetRequests(name, t.Requests(name).Remove(r))
+	c.logActivity(r.Item.ID, r.Item.Reason, "moved")
+	return t, Notification{ch, c.response.Move(r.Item, from+1, to+1, c.list(nq))}
+}
+
+// Move moves one of the user's entries to another place in the waiting list
+//
+// Users may move their own entries back as they please, but moving forward past anybody else waits until everybody
+// passed has agreed with `accept`.
+func (c QueueCommands) Move(t queue.Tokens, ch, id, args string) (queue.Tokens, Notification) {
+	c = c.forChannel(ch)
+	name, args, ok := c.parseToken(t, args)
+	if !ok {
+		return t, Notification{ch, c.response.TokenNotFound(id, name)}
+	}
+	c = c.forToken(name, t.Capacity(name))
+	q := t.Get(name)
+
+	a := parseArgs(args)
+	i, from, rest, _, ok := c.findEntry(q, a)
+	if !ok {
+		return t, Notification{ch, c.badEntry(id, a)}
+	}
+
+	to, _, ok := rest.position()
+	if !ok || to < 1 || to > len(q) {
+		return t, Notification{ch, c.response.BadIndex(id)}
+	}
+
+	if i.ID != id {
+		return t, Notification{ch, c.response.NotOwned(id, from)}
+	}
+
+	if from <= c.capacity || to <= c.capacity {
+		return t, Notification{ch, c.response.MoveNotWaiting(id)}
+	}
+
+	if from == to {
+		return t, Notification{ch, c.response.MoveNowhere(i, from)}
+	}
+
+	r := queue.Request{ID: id, Item: i, Target: q[to-1], Expires: c.clock().Add(c.timeout)}
+	ids, _ := c.awaiting(q, r)
+	if to > from || len(ids) == 0 {
+		return c.moved(t, ch, name, r)
+	}
+
+	t = c.dropStale(t.SetRequests(name, t.Requests(name).Add(r)))
+	c.logActivity(id, i.Reason, "asked to move")
+	return t, Notification{ch, c.response.MoveRequested(r, to, ids)}
+}
+
+// dropStale forgets requests to move entries that have left the queue, or to move in front of entries that have left
+func (c QueueCommands) dropStale(t queue.Tokens) queue.Tokens {
+	for _, name := range t.Names() {
+		for _, r := range t.Requests(name) {
+			if _, ok := c.awaiting(t.Get(name), r); !ok {
+				t = t.SetRequests(name, t.Requests(name).Remove(r))
+			}
+		}
+	}
+	return t
+}
+
+// findRequest finds the first request waiting for the user to agree, optionally to move the entry with a given key
+func (c QueueCommands) findRequest(t queue.Tokens, id, args string) (name string, r queue.Request, ok bool) {
+	key, _, keyed := parseArgs(args).key()
+	for _, name = range t.Names() {
+		q := t.Get(name)
+		for _, r = range t.Requests(name) {
+			if keyed && r.Item.Key != key {
+				continue
+			}
+
+			ids, _ := c.awaiting(q, r)
+			for _, a := range ids {
+				if a == id {
+					return name, r, true
+				}
+			}
+		}
+	}
+	return
+}
+
+// Accept agrees to let somebody move past the user's entries, moving them once everybody passed has agreed, or to
+// swap places with them
+//
+// Requests are dropped if the entry or the entry it would move in front of has left the queue since they were made.
+func (c QueueCommands) Accept(t queue.Tokens, ch, id, args string) (queue.Tokens, Notification) {
+	c = c.forChannel(ch)
+	t = c.dropStale(t)
+	name, r, ok := c.findRequest(t, id, args)
+	if !ok {
+		return t, Notification{ch, c.response.NothingToAccept(id)}
+	}
+	c = c.forToken(name, t.Capacity(name))
+
+	r = r.Approve(id)
+	ids, _ := c.awaiting(t.Get(name), r)
+	if len(ids) == 0 && r.Swap {
+		return c.swapped(t, ch, name, r)
+	}
+	if len(ids) == 0 {
+		return c.moved(t, ch, name, r)
+	}
+
+	t = t.SetRequests(name, t.Requests(name).Replace(r))
+	c.logActivity(id, r.Item.Reason, "agreed to a move")
+	return t, Notification{ch, c.response.MoveApproved(id, r, ids)}
+}
+
+// Decline refuses to let somebody move past the user's entries or swap places with them, dropping their request
+func (c QueueCommands) Decline(t queue.Tokens, ch, id, args string) (queue.Tokens, Notification) {
+	c = c.forChannel(ch)
+	t = c.dropStale(t)
+	name, r, ok := c.findRequest(t, id, args)
+	if !ok {
+		return t, Notification{ch, c.response.NothingToAccept(id)}
+	}
+	c = c.forToken(name, t.Capacity(name))
+
+	t = t.SetRequests(name, t.Requests(name).Remove(r))
+	c.logActivity(id, r.Item.Reason, "declined a move")
+	return t, Notification{ch, c.response.MoveDeclined(id, r)}
+}
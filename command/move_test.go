@@ -0,0 +1,192 @@
+package command_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/doozr/qbot/command"
+	"github.com/doozr/qbot/queue"
+)
+
+var (
+	moveHolder = queue.Item{ID: "U456", Reason: "Holder", Key: "aaa"}
+	moveEdward = queue.Item{ID: "U456", Reason: "Potato", Key: "bbb"}
+	moveAndrew = queue.Item{ID: "U789", Reason: "Tomato", Key: "ccc"}
+	moveCraig  = queue.Item{ID: "U123", Reason: "Banana", Key: "ddd"}
+	moveLater  = queue.Item{ID: "U123", Reason: "Later", Key: "eee"}
+)
+
+var moveNow = time.Date(2017, 3, 14, 12, 0, 0, 0, time.UTC)
+
+func moveTokens(q queue.Queue, rs ...queue.Request) queue.Tokens {
+	return queue.Tokens{queue.DefaultToken: {Queue: q, Requests: rs}}
+}
+
+func TestMove(t *testing.T) {
+	cmd := command.New(id, name, userCache).WithClock(func() time.Time { return moveNow })
+	start := queue.Queue{moveHolder, moveCraig, moveEdward, moveAndrew, moveLater}
+
+	testChannelCommand(t, cmd.Move, []TokenTest{
+		{
+			test:           "move own entry back straight away",
+			startTokens:    moveTokens(start),
+			channel:        "C1A2B3C",
+			user:           "U123",
+			args:           "2 4",
+			expectedTokens: moveTokens(queue.Queue{moveHolder, moveEdward, moveAndrew, moveCraig, moveLater}),
+			expectedResponse: "<@U123|craig> (Banana) has moved from position 2 to 4 in the queue:\n" +
+				"*1: edward (Holder) has the token* `#aaa`\n2: edward (Potato) `#bbb`\n3: andrew (Tomato) `#ccc`\n" +
+				"4: craig (Banana) `#ddd`\n5: craig (Later) `#eee`",
+		},
+		{
+			test:           "move own entry forward past own entries straight away",
+			startTokens:    moveTokens(queue.Queue{moveHolder, moveCraig, moveLater}),
+			channel:        "C1A2B3C",
+			user:           "U123",
+			args:           "#eee 2",
+			expectedTokens: moveTokens(queue.Queue{moveHolder, moveLater, moveCraig}),
+			expectedResponse: "<@U123|craig> (Later) has moved from position 3 to 2 in the queue:\n" +
+				"*1: edward (Holder) has the token* `#aaa`\n2: craig (Later) `#eee`\n3: craig (Banana) `#ddd`",
+		},
+		{
+			test:        "ask everybody passed before moving forward",
+			startTokens: moveTokens(start),
+			channel:     "C1A2B3C",
+			user:        "U123",
+			args:        "5 2",
+			expectedTokens: moveTokens(start,
+				queue.Request{ID: "U123", Item: moveLater, Target: moveCraig, Expires: moveNow.Add(command.DefaultRequestTimeout)}),
+			expectedResponse: "<@U123|craig> (Later) would like to move up to position 2 in the queue. " +
+				"<@U456|edward> and <@U789|andrew>, say `accept #eee` to let them past or `decline #eee` to refuse",
+		},
+		{
+			test:             "refuse to move entries owned by others",
+			startTokens:      moveTokens(start),
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "3 2",
+			expectedTokens:   moveTokens(start),
+			expectedResponse: "<@U123|craig> You are not 3rd in line",
+		},
+		{
+			test:             "refuse to move into the holders",
+			startTokens:      moveTokens(start),
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "2 1",
+			expectedTokens:   moveTokens(start),
+			expectedResponse: "<@U123|craig> Only entries waiting for the token can be moved, and only to places behind the holders",
+		},
+		{
+			test:             "refuse to move to a position outside the queue",
+			startTokens:      moveTokens(start),
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "2 6",
+			expectedTokens:   moveTokens(start),
+			expectedResponse: "<@U123|craig> That's not a valid position in the queue",
+		},
+		{
+			test:             "refuse to move an entry that does not exist",
+			startTokens:      moveTokens(start),
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "#zzz 2",
+			expectedTokens:   moveTokens(start),
+			expectedResponse: "<@U123|craig> No entry `#zzz` was found in the queue",
+		},
+		{
+			test:             "say when the entry is already there",
+			startTokens:      moveTokens(start),
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "2 2",
+			expectedTokens:   moveTokens(start),
+			expectedResponse: "<@U123|craig> (Banana) is already at position 2",
+		},
+	})
+}
+
+func TestAccept(t *testing.T) {
+	cmd := command.New(id, name, userCache)
+	start := queue.Queue{moveHolder, moveCraig, moveEdward, moveAndrew, moveLater}
+	request := queue.Request{ID: "U123", Item: moveLater, Target: moveCraig}
+	approved := request.Approve("U456")
+
+	testChannelCommand(t, cmd.Accept, []TokenTest{
+		{
+			test:             "wait for everybody passed to agree",
+			startTokens:      moveTokens(start, request),
+			channel:          "C1A2B3C",
+			user:             "U456",
+			args:             "",
+			expectedTokens:   moveTokens(start, approved),
+			expectedResponse: "<@U456|edward> has agreed to let <@U123|craig> (Later) move up, but <@U789|andrew> still need to say `accept #eee`",
+		},
+		{
+			test:           "move once everybody passed has agreed",
+			startTokens:    moveTokens(start, approved),
+			channel:        "C1A2B3C",
+			user:           "U789",
+			args:           "#eee",
+			expectedTokens: moveTokens(queue.Queue{moveHolder, moveLater, moveCraig, moveEdward, moveAndrew}),
+			expectedResponse: "<@U123|craig> (Later) has moved from position 5 to 2 in the queue:\n" +
+				"*1: edward (Holder) has the token* `#aaa`\n2: craig (Later) `#eee`\n3: craig (Banana) `#ddd`\n" +
+				"4: edward (Potato) `#bbb`\n5: andrew (Tomato) `#ccc`",
+		},
+		{
+			test:             "ignore requests that do not need the user to agree",
+			startTokens:      moveTokens(start, approved),
+			channel:          "C1A2B3C",
+			user:             "U456",
+			args:             "",
+			expectedTokens:   moveTokens(start, approved),
+			expectedResponse: "<@U456|edward> Nobody is waiting for you to let them move up or swap places",
+		},
+		{
+			test:             "ignore requests for other entries",
+			startTokens:      moveTokens(start, request),
+			channel:          "C1A2B3C",
+			user:             "U456",
+			args:             "#ddd",
+			expectedTokens:   moveTokens(start, request),
+			expectedResponse: "<@U456|edward> Nobody is waiting for you to let them move up or swap places",
+		},
+		{
+			test:             "drop the request once the entry has left",
+			startTokens:      moveTokens(queue.Queue{moveHolder, moveCraig, moveEdward, moveAndrew}, request),
+			channel:          "C1A2B3C",
+			user:             "U456",
+			args:             "",
+			expectedTokens:   moveTokens(queue.Queue{moveHolder, moveCraig, moveEdward, moveAndrew}),
+			expectedResponse: "<@U456|edward> Nobody is waiting for you to let them move up or swap places",
+		},
+	})
+}
+
+func TestDecline(t *testing.T) {
+	cmd := command.New(id, name, userCache)
+	start := queue.Queue{moveHolder, moveCraig, moveEdward, moveAndrew, moveLater}
+	request := queue.Request{ID: "U123", Item: moveLater, Target: moveCraig}
+
+	testChannelCommand(t, cmd.Decline, []TokenTest{
+		{
+			test:             "drop the request",
+			startTokens:      moveTokens(start, request),
+			channel:          "C1A2B3C",
+			user:             "U789",
+			args:             "#eee",
+			expectedTokens:   moveTokens(start),
+			expectedResponse: "<@U789|andrew> has declined to let <@U123|craig> (Later) move up",
+		},
+		{
+			test:             "refuse when nobody is waiting for the user",
+			startTokens:      moveTokens(start, request),
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "",
+			expectedTokens:   moveTokens(start, request),
+			expectedResponse: "<@U123|craig> Nobody is waiting for you to let them move up or swap places",
+		},
+	})
+}
@@ -0,0 +1,86 @@
+package command
+
+import (
+	"strings"
+
+	"github.com/doozr/qbot/preferences"
+	"github.com/doozr/qbot/queue"
+)
+
+// notifyLevels are the settings that can be given to `notify`
+var notifyLevels = map[string]preferences.Notify{
+	string(preferences.NotifyOff):  preferences.NotifyOff,
+	string(preferences.NotifyNext): preferences.NotifyNext,
+	string(preferences.NotifyAll):  preferences.NotifyAll,
+}
+
+// Notify turns direct messages about changes to the user's place in the queues on or off
+//
+// With no setting it says which messages the user gets at the moment.
+func (c QueueCommands) Notify(qs queue.Channels, ch, id, args string) Notification {
+	c = c.forChannel(ch)
+	if c.prefs == nil {
+		return Notification{ch, c.response.NotifyUnavailable(id)}
+	}
+
+	setting := parseArgs(args).first()
+	if setting == "" {
+		return Notification{ch, c.response.Notify(c.prefs.Notify(id))}
+	}
+
+	n, ok := notifyLevels[strings.ToLower(setting)]
+	if !ok {
+		return Notification{ch, c.response.NotifyBadSetting(id, setting)}
+	}
+
+	err := c.prefs.SetNotify(id, n)
+	if err != nil {
+		return Notification{ch, c.response.NotifyNotSaved(id)}
+	}
+
+	c.logActivity(id, string(n), "set notifications")
+	return Notification{ch, c.response.Notify(n)}
+}
+
+// PositionChanged sends a direct message to the owner of an entry whose place in a queue has changed, if they want
+// to know
+//
+// The entry is looked for in the named token before and after the change. Entries that have left or that still hold
+// the token are not mentioned. An entry that has just been delegated to the user is new to them, even though it keeps
+// its key.
+func (c QueueCommands) PositionChanged(ch, name string, before, after queue.Tokens, i queue.Item) Notification {
+	c = c.forChannel(ch)
+	none := Notification{i.ID, ""}
+	if c.prefs == nil {
+		return none
+	}
+
+	was := c.forToken(name, before.Capacity(name))
+	c = c.forToken(name, after.Capacity(name))
+
+	from := before.Get(name).IndexOf(i) + 1
+	if from > 0 && before.Get(name)[from-1].ID != i.ID {
+		from = 0
+	}
+	to := after.Get(name).IndexOf(i) + 1
+	held := from > 0 && from <= was.capacity
+	if to == 0 || (from == to && was.capacity == c.capacity) {
+		return none
+	}
+
+	level := c.prefs.Notify(i.ID)
+	acquired := to <= c.capacity && !held
+	next := to == c.capacity+1
+
+	switch {
+	case level == preferences.NotifyOff:
+		return none
+	case acquired:
+		return Notification{i.ID, c.response.NotifyAcquired(ch, i)}
+	case next:
+		return Notification{i.ID, c.response.NotifyNext(ch, i)}
+	case level == preferences.NotifyAll && to > c.capacity:
+		return Notification{i.ID, c.response.NotifyPosition(ch, i, to)}
+	}
+	return none
+}
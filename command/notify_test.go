@@ -0,0 +1,133 @@
+package command_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/doozr/qbot/command"
+	"github.com/doozr/qbot/preferences"
+	"github.com/doozr/qbot/queue"
+)
+
+func notifyPreferences(users map[string]preferences.User) preferences.Preferences {
+	return preferences.New(users, func(map[string]preferences.User) error { return nil })
+}
+
+func TestNotify(t *testing.T) {
+	prefs := notifyPreferences(map[string]preferences.User{"U456": {Notify: preferences.NotifyAll}})
+	cmd := command.New(id, name, userCache).WithPreferences(prefs)
+
+	r := cmd.Notify(queue.Channels{}, "D1234", "U456", "")
+	assertResponse(t, "report current setting", "D1234",
+		"You will get a direct message whenever your place in a queue changes", r)
+
+	r = cmd.Notify(queue.Channels{}, "D1234", "U123", "")
+	assertResponse(t, "report default setting", "D1234",
+		"You will not get direct messages about the queues", r)
+
+	r = cmd.Notify(queue.Channels{}, "D1234", "U123", "Next")
+	assertResponse(t, "turn on next notifications", "D1234",
+		"You will get a direct message when you get a token or are next in line for it", r)
+	if prefs.Notify("U123") != preferences.NotifyNext {
+		t.Fatal("Expected setting to be saved, got ", prefs.Notify("U123"))
+	}
+
+	r = cmd.Notify(queue.Channels{}, "D1234", "U456", "off")
+	assertResponse(t, "turn off notifications", "D1234",
+		"You will not get direct messages about the queues", r)
+	if prefs.Notify("U456") != preferences.NotifyOff {
+		t.Fatal("Expected setting to be saved, got ", prefs.Notify("U456"))
+	}
+
+	r = cmd.Notify(queue.Channels{}, "D1234", "U456", "sometimes")
+	assertResponse(t, "bad setting", "D1234",
+		"<@U456|edward> `sometimes` is not a setting I know; use `on`, `next` or `off`", r)
+}
+
+func TestNotifyNotSaved(t *testing.T) {
+	prefs := preferences.New(map[string]preferences.User{}, func(map[string]preferences.User) error {
+		return fmt.Errorf("Error!")
+	})
+	cmd := command.New(id, name, userCache).WithPreferences(prefs)
+
+	r := cmd.Notify(queue.Channels{}, "D1234", "U456", "on")
+	assertResponse(t, "setting not saved", "D1234",
+		"<@U456|edward> Your setting could not be saved; please try again", r)
+}
+
+func TestNotifyUnavailable(t *testing.T) {
+	cmd := command.New(id, name, userCache)
+
+	r := cmd.Notify(queue.Channels{}, "D1234", "U456", "on")
+	assertResponse(t, "no preferences", "D1234",
+		"<@U456|edward> Direct messages about the queues are not available", r)
+}
+
+func TestPositionChanged(t *testing.T) {
+	prefs := notifyPreferences(map[string]preferences.User{
+		"U123": {Notify: preferences.NotifyNext},
+		"U456": {Notify: preferences.NotifyAll},
+	})
+	cmd := command.New(id, name, userCache).WithPreferences(prefs)
+
+	craig := queue.Item{ID: "U123", Reason: "Banana", Key: "fp8"}
+	edward := queue.Item{ID: "U456", Reason: "Potato", Key: "lqy"}
+	andrew := queue.Item{ID: "U789", Reason: "Tomato", Key: "jox"}
+	release := queue.Item{ID: "U789", Reason: "Release", Key: "ecf"}
+	delegatedToCraig := queue.Item{ID: "U123", Reason: "Tomato", Key: "jox"}
+	delegatedToEdward := queue.Item{ID: "U456", Reason: "Banana", Key: "fp8"}
+
+	tokens := func(q ...queue.Item) queue.Tokens {
+		return queue.Tokens{queue.DefaultToken: {Queue: queue.Queue(q)}}
+	}
+
+	tests := []struct {
+		test     string
+		before   queue.Tokens
+		after    queue.Tokens
+		item     queue.Item
+		expected string
+	}{
+		{"acquired", tokens(andrew, craig), tokens(craig), craig,
+			"*You now have the token in <#C1234>* (Banana)"},
+		{"next in line", tokens(andrew, release, craig), tokens(andrew, craig), craig,
+			"You are now next in line for the token in <#C1234> (Banana)"},
+		{"joined next in line", tokens(andrew), tokens(andrew, craig), craig,
+			"You are now next in line for the token in <#C1234> (Banana)"},
+		{"position for next only", tokens(andrew, release, edward, craig), tokens(andrew, edward, craig), craig,
+			""},
+		{"position for all", tokens(andrew, release, craig, edward), tokens(andrew, craig, edward), edward,
+			"You are now 3rd in line for the token in <#C1234> (Potato)"},
+		{"turned off", tokens(craig, edward), tokens(andrew), andrew,
+			""},
+		{"unchanged", tokens(andrew, craig), tokens(andrew, craig), craig,
+			""},
+		{"left", tokens(andrew, craig), tokens(andrew), craig,
+			""},
+		{"delegated the token", tokens(andrew, edward), tokens(delegatedToCraig, edward), delegatedToCraig,
+			"*You now have the token in <#C1234>* (Tomato)"},
+		{"delegated a place", tokens(andrew, craig), tokens(andrew, delegatedToEdward), delegatedToEdward,
+			"You are now next in line for the token in <#C1234> (Banana)"},
+		{"capacity increased", tokens(andrew, craig),
+			queue.Tokens{queue.DefaultToken: {Capacity: 2, Queue: queue.Queue{andrew, craig}}}, craig,
+			"*You now have the token in <#C1234>* (Banana)"},
+	}
+
+	for _, tt := range tests {
+		r := cmd.PositionChanged("C1234", queue.DefaultToken, tt.before, tt.after, tt.item)
+		assertResponse(t, tt.test, tt.item.ID, tt.expected, r)
+	}
+}
+
+func TestPositionChangedDoesNotRepeatHolding(t *testing.T) {
+	prefs := notifyPreferences(map[string]preferences.User{"U123": {Notify: preferences.NotifyAll}})
+	cmd := command.New(id, name, userCache).WithPreferences(prefs)
+
+	craig := queue.Item{ID: "U123", Reason: "Banana", Key: "fp8"}
+	andrew := queue.Item{ID: "U789", Reason: "Tomato", Key: "jox"}
+	before := queue.Tokens{queue.DefaultToken: {Capacity: 2, Queue: queue.Queue{andrew, craig}}}
+	after := queue.Tokens{queue.DefaultToken: {Capacity: 2, Queue: queue.Queue{craig}}}
+
+	r := cmd.PositionChanged("C1234", queue.DefaultToken, before, after, craig)
+	assertResponse(t, "already holding", "U123", "", r)
+}
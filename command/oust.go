@@ -2,34 +2,63 @@ package command
 
 import "github.com/doozr/qbot/queue"
 
-// Oust boots the current token holder and gives it to the next person
-func (c QueueCommands) Oust(q queue.Queue, ch, ouster, args string) (queue.Queue, Notification) {
-	if len(q) == 0 {
-		return q, Notification{ch, ""}
+// oust forces a token holder to yield to the next in line, or removes them if nobody is waiting
+func (c QueueCommands) oust(q queue.Queue, ch, ouster string, i queue.Item) (queue.Queue, Notification) {
+	c.logActivity(i.ID, i.Reason, "ousted by "+c.getNameIDPair(ouster))
+	if len(q) <= c.capacity {
+		q = q.Remove(i)
+		return q, Notification{ch, c.response.OustNoOthers(ouster, i)}
+	}
+
+	nq := q.YieldHolder(i, c.capacity)
+	p := c.promoted(q, nq)
+	c.logPromoted(p)
+	return nq, Notification{ch, c.response.Oust(ouster, i, p)}
+}
+
+// findOustTarget finds the token holder named by the arguments, or says why there isn't one
+func (c QueueCommands) findOustTarget(q queue.Queue, ouster, args string) (i queue.Item, response string, ok bool) {
+	a := parseArgs(args)
+	if a.empty() {
+		return i, c.response.OustNoTarget(ouster), false
 	}
 
-	if args == "" {
-		return q, Notification{ch, c.response.OustNoTarget(ouster)}
+	if key, _, keyed := a.key(); keyed {
+		i, _, ok = c.findByKey(q, key)
+		if !ok {
+			return i, c.response.BadKey(ouster, key), false
+		}
+		if !q.Holds(i, c.capacity) {
+			return i, c.response.OustNotActive(ouster), false
+		}
+		return i, "", true
 	}
 
-	id := c.getIDFromName(args)
+	id, name, _, candidates := c.user(a)
+	if len(candidates) > 1 {
+		return i, c.response.UserAmbiguous(ouster, name, candidates), false
+	}
 	if id == "" {
-		return q, Notification{ch, c.response.OustNotActive(ouster)}
+		return i, c.response.OustNotActive(ouster), false
 	}
 
-	i := q.Active()
-	if i.ID != id {
-		return q, Notification{ch, c.response.OustNotActive(ouster)}
+	i, ok = c.findHolder(q, id)
+	if !ok {
+		return i, c.response.OustNotActive(ouster), false
 	}
+	return i, "", true
+}
 
-	c.logActivity(i.ID, i.Reason, "ousted by "+c.getNameIDPair(ouster))
-	if len(q) == 1 {
-		q = q.Remove(i)
-		return q, Notification{ch, c.response.OustNoOthers(ouster, i)}
+// Oust boots a token holder and gives their place to the next person
+func (c QueueCommands) Oust(q queue.Queue, ch, ouster, args string) (queue.Queue, Notification) {
+	if len(q) == 0 {
+		return q, Notification{ch, ""}
+	}
+
+	i, response, ok := c.findOustTarget(q, ouster, args)
+	if !ok {
+		return q, Notification{ch, response}
 	}
 
-	q = q.Yield()
-	n := q.Active()
-	c.logActivity(n.ID, n.Reason, "is active")
-	return q, Notification{ch, c.response.Oust(ouster, i, n)}
+	return c.oust(q, ch, ouster, i)
 }
@@ -66,3 +66,59 @@ func TestOust(t *testing.T) {
 		},
 	})
 }
+
+func TestOustSharedToken(t *testing.T) {
+	cmd := command.New(id, name, userCache)
+	testChannelCommand(t, cmd.Named(command.QueueCommands.Oust), []TokenTest{
+		{
+			test:             "oust second holder",
+			startTokens:      queue.Tokens{"envs": {Capacity: 2, Queue: queue.Queue{{ID: "U123", Reason: "Banana"}, {ID: "U456", Reason: "Apple"}, {ID: "U789", Reason: "Next up"}}}},
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "envs edward",
+			expectedTokens:   queue.Tokens{"envs": {Capacity: 2, Queue: queue.Queue{{ID: "U123", Reason: "Banana"}, {ID: "U789", Reason: "Next up"}, {ID: "U456", Reason: "Apple"}}}},
+			expectedResponse: "<@U123|craig> ousted <@U456|edward> (Apple)\n*<@U789|andrew> (Next up) now has the `envs` token*",
+		},
+		{
+			test:             "cannot oust somebody who is waiting",
+			startTokens:      queue.Tokens{"envs": {Capacity: 2, Queue: queue.Queue{{ID: "U123", Reason: "Banana"}, {ID: "U456", Reason: "Apple"}, {ID: "U789", Reason: "Next up"}}}},
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "envs andrew",
+			expectedTokens:   queue.Tokens{"envs": {Capacity: 2, Queue: queue.Queue{{ID: "U123", Reason: "Banana"}, {ID: "U456", Reason: "Apple"}, {ID: "U789", Reason: "Next up"}}}},
+			expectedResponse: "<@U123|craig> You can only oust the token holder",
+		},
+	})
+}
+
+func TestOustByKey(t *testing.T) {
+	cmd := command.New(id, name, userCache)
+	testCommand(t, cmd.Oust, []CommandTest{
+		{
+			test: "oust holder with key",
+			startQueue: queue.Queue{
+				{ID: "U123", Reason: "Banana", Key: "a00"},
+				{ID: "U456", Reason: "Apple", Key: "b00"}},
+			channel: "C1A2B3C",
+			user:    "U789",
+			args:    "#a00",
+			expectedQueue: queue.Queue{
+				{ID: "U456", Reason: "Apple", Key: "b00"},
+				{ID: "U123", Reason: "Banana", Key: "a00"}},
+			expectedResponse: "<@U789|andrew> ousted <@U123|craig> (Banana)\n*<@U456|edward> (Apple) now has the token*",
+		},
+		{
+			test: "refuse to oust waiting entry with key",
+			startQueue: queue.Queue{
+				{ID: "U123", Reason: "Banana", Key: "a00"},
+				{ID: "U456", Reason: "Apple", Key: "b00"}},
+			channel: "C1A2B3C",
+			user:    "U789",
+			args:    "#b00",
+			expectedQueue: queue.Queue{
+				{ID: "U123", Reason: "Banana", Key: "a00"},
+				{ID: "U456", Reason: "Apple", Key: "b00"}},
+			expectedResponse: "<@U789|andrew> You can only oust the token holder",
+		},
+	})
+}
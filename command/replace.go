@@ -4,18 +4,15 @@ import "github.com/doozr/qbot/queue"
 
 // Replace swaps the entry at a given position for another one
 func (c QueueCommands) Replace(q queue.Queue, ch, id, args string) (queue.Queue, Notification) {
-	position, reason, ok := c.parsePosition(args)
+	a := parseArgs(args)
+	o, position, rest, _, ok := c.findEntry(q, a)
 	if !ok {
-		return q, Notification{ch, c.response.BadIndex(id)}
+		return q, Notification{ch, c.badEntry(id, a)}
 	}
 
+	reason := rest.rest()
 	i := queue.Item{ID: id, Reason: reason}
 
-	o, ok := c.findByPosition(q, position)
-	if !ok {
-		return q, Notification{ch, c.response.BadIndex(id)}
-	}
-
 	if i.ID != o.ID {
 		return q, Notification{ch, c.response.NotOwned(i.ID, position)}
 	}
@@ -26,7 +23,7 @@ func (c QueueCommands) Replace(q queue.Queue, ch, id, args string) (queue.Queue,
 
 	q = q.Delegate(o, i)
 	c.logActivity(id, reason, "replaced")
-	if q.Active() == i {
+	if q.Holds(i, c.capacity) {
 		return q, Notification{ch, c.response.JoinActive(i)}
 	}
 	return q, Notification{ch, c.response.Join(i, position)}
@@ -2,14 +2,29 @@ package command_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/doozr/qbot/command"
 	"github.com/doozr/qbot/queue"
 )
 
 func TestReplace(t *testing.T) {
+	joined := time.Date(2017, 3, 14, 9, 0, 0, 0, time.UTC)
+	acquired := time.Date(2017, 3, 14, 9, 30, 0, 0, time.UTC)
+
 	cmd := command.New(id, name, userCache)
 	testCommand(t, cmd.Replace, []CommandTest{
+		{
+			test: "replace keeps join and acquisition times",
+			startQueue: queue.Queue([]queue.Item{
+				{ID: "U123", Reason: "Already here", JoinedAt: joined, ActiveSince: acquired}}),
+			channel: "C1A2B3C",
+			user:    "U123",
+			args:    "1 Banana",
+			expectedQueue: queue.Queue([]queue.Item{
+				{ID: "U123", Reason: "Banana", JoinedAt: joined, ActiveSince: acquired}}),
+			expectedResponse: "*<@U123|craig> (Banana) now has the token*",
+		},
 		{
 			test: "replace when active and position owned by self",
 			startQueue: queue.Queue([]queue.Item{
@@ -115,3 +130,22 @@ func TestReplace(t *testing.T) {
 		},
 	})
 }
+
+func TestReplaceByKey(t *testing.T) {
+	cmd := command.New(id, name, userCache)
+	testCommand(t, cmd.Replace, []CommandTest{
+		{
+			test: "replace reason of entry with key and keep the key",
+			startQueue: queue.Queue{
+				{ID: "U456", Reason: "Active", Key: "a00"},
+				{ID: "U123", Reason: "Apple", Key: "b00"}},
+			channel: "C1A2B3C",
+			user:    "U123",
+			args:    "#b00 Banana",
+			expectedQueue: queue.Queue{
+				{ID: "U456", Reason: "Active", Key: "a00"},
+				{ID: "U123", Reason: "Banana", Key: "b00"}},
+			expectedResponse: "<@U123|craig> (Banana) is now next in line",
+		},
+	})
+}
@@ -0,0 +1,122 @@
+package command
+
+import (
+	"time"
+
+	"github.com/doozr/qbot/queue"
+)
+
+// reserveDateFormat is the format of a start time on a particular day
+const reserveDateFormat = "2006-01-02T15:04"
+
+// reserveTimeFormat is the format of a start time at the next occurrence of a time of day
+const reserveTimeFormat = "15:04"
+
+// parseStart reads a start time as either a date and time or the next occurrence of a time of day
+func (c QueueCommands) parseStart(s string) (start time.Time, ok bool) {
+	now := c.clock()
+
+	start, err := time.ParseInLocation(reserveDateFormat, s, now.Location())
+	if err == nil {
+		return start, true
+	}
+
+	t, err := time.ParseInLocation(reserveTimeFormat, s, now.Location())
+	if err != nil {
+		return
+	}
+
+	start = time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, now.Location())
+	if !start.After(now) {
+		start = start.AddDate(0, 0, 1)
+	}
+	return start, true
+}
+
+// Reserve books a token for a window of time, after which it is handed to the user when the window opens
+//
+// A booking is refused if the token is already booked by as many people as can hold it at any time in the window.
+func (c QueueCommands) Reserve(t queue.Tokens, ch, id, args string) (queue.Tokens, Notification) {
+	c = c.forChannel(ch)
+	name, args, ok := c.parseToken(t, args)
+	if !ok {
+		return t, Notification{ch, c.response.TokenNotFound(id, name)}
+	}
+	c = c.forToken(name, t.Capacity(name))
+
+	s, a := parseArgs(args).shift()
+	start, ok := c.parseStart(s)
+	if !ok {
+		return t, Notification{ch, c.response.ReserveBadStart(id, s)}
+	}
+
+	d := a.first()
+	duration, a, ok := a.duration()
+	if !ok || duration <= 0 {
+		return t, Notification{ch, c.response.ReserveBadDuration(id, d)}
+	}
+
+	reason := a.rest()
+	if reason == "" {
+		return t, Notification{ch, c.response.ReserveNoReason(id)}
+	}
+
+	r := queue.Reservation{ID: id, Reason: reason, Start: start, End: start.Add(duration)}
+	if !r.End.After(c.clock()) {
+		return t, Notification{ch, c.response.ReservePast(id)}
+	}
+
+	rs := t.Reservations(name)
+	if rs.MostAtOnce(r) >= c.capacity {
+		return t, Notification{ch, c.response.ReserveConflict(id, rs.Overlapping(r))}
+	}
+
+	t = t.SetReservations(name, rs.Add(r))
+	c.logActivity(id, reason, "reserved "+c.response.render("window", r))
+	return t, Notification{ch, c.response.Reserve(r)}
+}
+
+// ReservationStarted hands a token to the user who reserved it once their window opens
+//
+// The reserver goes to the front of the queue, so anybody who no longer holds the token as a result is next in line.
+func (c QueueCommands) ReservationStarted(t queue.Tokens, ch, name string, r queue.Reservation) (queue.Tokens, Notification) {
+	c = c.forChannel(ch)
+	c = c.forToken(name, t.Capacity(name))
+	q := t.Get(name)
+	nq := q.Prepend(r.Item())
+
+	displaced := []queue.Item{}
+	for _, i := range q.Holders(c.capacity) {
+		if !nq.Holds(i, c.capacity) {
+			displaced = append(displaced, i)
+		}
+	}
+
+	t = t.Set(name, nq).SetReservations(name, t.Reservations(name).Start(r))
+	c.logActivity(r.ID, r.Reason, "is active by reservation")
+	return t, Notification{ch, c.response.ReservationStarted(r, displaced)}
+}
+
+// ReservationEnded releases a token from the user who reserved it once their window closes
+//
+// The reservation is forgotten either way, but the token is only released if the reserver still holds it for the
+// reason they gave.
+func (c QueueCommands) ReservationEnded(t queue.Tokens, ch, name string, r queue.Reservation) (queue.Tokens, Notification) {
+	c = c.forChannel(ch)
+	c = c.forToken(name, t.Capacity(name))
+	t = t.SetReservations(name, t.Reservations(name).Remove(r))
+
+	q := t.Get(name)
+	i := r.Item()
+	if !r.Started || !q.Holds(i, c.capacity) {
+		return t, Notification{ch, ""}
+	}
+
+	nq := q.Remove(i)
+	c.logActivity(i.ID, i.Reason, "reservation ended")
+	if p := c.promoted(q, nq); len(p) > 0 {
+		c.logPromoted(p)
+		return t.Set(name, nq), Notification{ch, c.response.ReservationEnded(r, c.response.Done(i, p))}
+	}
+	return t.Set(name, nq), Notification{ch, c.response.ReservationEnded(r, c.response.DoneNoOthers(i))}
+}
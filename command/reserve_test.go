@@ -0,0 +1,190 @@
+package command_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/doozr/qbot/command"
+	"github.com/doozr/qbot/queue"
+)
+
+var reserveNow = time.Date(2017, 3, 14, 10, 0, 0, 0, time.UTC)
+
+func reserved(id, reason string, start time.Time, d time.Duration) queue.Reservation {
+	return queue.Reservation{ID: id, Reason: reason, Start: start, End: start.Add(d)}
+}
+
+func TestReserve(t *testing.T) {
+	cmd := command.New(id, name, userCache).WithClock(func() time.Time { return reserveNow })
+	at14 := time.Date(2017, 3, 14, 14, 0, 0, 0, time.UTC)
+	release := reserved("U456", "Release", at14, time.Hour)
+	rollback := reserved("U789", "Rollback", at14.Add(time.Hour), time.Hour)
+	hotfix := reserved("U456", "Hotfix", at14.Add(time.Hour), time.Hour)
+
+	testChannelCommand(t, cmd.Reserve, []TokenTest{
+		{
+			test:             "reserve later today",
+			startTokens:      queue.Tokens{},
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "14:00 1h Banana",
+			expectedTokens:   queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{}, Reservations: queue.Reservations{reserved("U123", "Banana", at14, time.Hour)}}},
+			expectedResponse: "<@U123|craig> (Banana) has reserved the token for Tue 14 Mar 14:00-15:00",
+		},
+		{
+			test:             "reserve time of day that has passed for tomorrow",
+			startTokens:      queue.Tokens{},
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "09:00 30m Banana",
+			expectedTokens:   queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{}, Reservations: queue.Reservations{reserved("U123", "Banana", time.Date(2017, 3, 15, 9, 0, 0, 0, time.UTC), 30*time.Minute)}}},
+			expectedResponse: "<@U123|craig> (Banana) has reserved the token for Wed 15 Mar 09:00-09:30",
+		},
+		{
+			test:             "reserve named token on a date",
+			startTokens:      queue.Tokens{"staging": {Queue: queue.Queue{}}},
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "staging 2017-03-16T23:00 2h Banana",
+			expectedTokens:   queue.Tokens{"staging": {Queue: queue.Queue{}, Reservations: queue.Reservations{reserved("U123", "Banana", time.Date(2017, 3, 16, 23, 0, 0, 0, time.UTC), 2*time.Hour)}}},
+			expectedResponse: "<@U123|craig> (Banana) has reserved the `staging` token for Thu 16 Mar 23:00 to Fri 17 Mar 01:00",
+		},
+		{
+			test:             "refuse overlapping reservation",
+			startTokens:      queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{}, Reservations: queue.Reservations{release}}},
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "14:30 1h Banana",
+			expectedTokens:   queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{}, Reservations: queue.Reservations{release}}},
+			expectedResponse: "<@U123|craig> The token is already reserved at that time:\nTue 14 Mar 14:00-15:00 edward (Release)",
+		},
+		{
+			test:             "allow back to back reservation",
+			startTokens:      queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{}, Reservations: queue.Reservations{release}}},
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "15:00 1h Banana",
+			expectedTokens:   queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{}, Reservations: queue.Reservations{release, reserved("U123", "Banana", at14.Add(time.Hour), time.Hour)}}},
+			expectedResponse: "<@U123|craig> (Banana) has reserved the token for Tue 14 Mar 15:00-16:00",
+		},
+		{
+			test:             "allow overlapping reservation while token has room",
+			startTokens:      queue.Tokens{"envs": {Capacity: 2, Queue: queue.Queue{}, Reservations: queue.Reservations{release}}},
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "envs 14:30 1h Banana",
+			expectedTokens:   queue.Tokens{"envs": {Capacity: 2, Queue: queue.Queue{}, Reservations: queue.Reservations{release, reserved("U123", "Banana", at14.Add(30*time.Minute), time.Hour)}}},
+			expectedResponse: "<@U123|craig> (Banana) has reserved the `envs` token for Tue 14 Mar 14:30-15:30",
+		},
+		{
+			test:             "allow reservation over back to back reservations while token has room",
+			startTokens:      queue.Tokens{"envs": {Capacity: 2, Queue: queue.Queue{}, Reservations: queue.Reservations{release, rollback}}},
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "envs 14:30 1h Banana",
+			expectedTokens:   queue.Tokens{"envs": {Capacity: 2, Queue: queue.Queue{}, Reservations: queue.Reservations{release, reserved("U123", "Banana", at14.Add(30*time.Minute), time.Hour), rollback}}},
+			expectedResponse: "<@U123|craig> (Banana) has reserved the `envs` token for Tue 14 Mar 14:30-15:30",
+		},
+		{
+			test:           "refuse reservation once token is full",
+			startTokens:    queue.Tokens{"envs": {Capacity: 2, Queue: queue.Queue{}, Reservations: queue.Reservations{release, rollback, hotfix}}},
+			channel:        "C1A2B3C",
+			user:           "U123",
+			args:           "envs 14:30 1h Banana",
+			expectedTokens: queue.Tokens{"envs": {Capacity: 2, Queue: queue.Queue{}, Reservations: queue.Reservations{release, rollback, hotfix}}},
+			expectedResponse: "<@U123|craig> The `envs` token is already reserved at that time:\nTue 14 Mar 14:00-15:00 edward (Release)\n" +
+				"Tue 14 Mar 15:00-16:00 andrew (Rollback)\nTue 14 Mar 15:00-16:00 edward (Hotfix)",
+		},
+		{
+			test:             "refuse bad start time",
+			startTokens:      queue.Tokens{},
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "later 1h Banana",
+			expectedTokens:   queue.Tokens{},
+			expectedResponse: "<@U123|craig> `later` is not a start time, use a time like `14:00` or `2017-03-14T14:00`",
+		},
+		{
+			test:             "refuse bad duration",
+			startTokens:      queue.Tokens{},
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "14:00 Banana",
+			expectedTokens:   queue.Tokens{},
+			expectedResponse: "<@U123|craig> `Banana` is not a duration, use a duration like `1h` or `90m`",
+		},
+		{
+			test:             "refuse missing reason",
+			startTokens:      queue.Tokens{},
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "14:00 1h",
+			expectedTokens:   queue.Tokens{},
+			expectedResponse: "<@U123|craig> You must provide a reason for reserving",
+		},
+		{
+			test:             "refuse window that is over",
+			startTokens:      queue.Tokens{},
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "2017-03-13T14:00 1h Banana",
+			expectedTokens:   queue.Tokens{},
+			expectedResponse: "<@U123|craig> That time has already passed",
+		},
+	})
+}
+
+func TestReservationStarted(t *testing.T) {
+	cmd := command.New(id, name, userCache)
+	r := reserved("U123", "Banana", reserveNow, time.Hour)
+	started := r
+	started.Started = true
+
+	testChannelCommand(t, func(tokens queue.Tokens, ch, user, args string) (queue.Tokens, command.Notification) {
+		return cmd.ReservationStarted(tokens, ch, args, r)
+	}, []TokenTest{
+		{
+			test:             "hand token to reserver and make holder next in line",
+			startTokens:      queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{{ID: "U456", Reason: "Apple"}}, Reservations: queue.Reservations{r}}},
+			channel:          "C1A2B3C",
+			args:             queue.DefaultToken,
+			expectedTokens:   queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{{ID: "U123", Reason: "Banana"}, {ID: "U456", Reason: "Apple"}}, Reservations: queue.Reservations{started}}},
+			expectedResponse: "<@U123|craig> (Banana) has reserved the token for Tue 14 Mar 10:00-11:00\n*<@U123|craig> (Banana) now has the token*\n<@U456|edward> (Apple) is now next in line",
+		},
+		{
+			test:             "hand free token to reserver",
+			startTokens:      queue.Tokens{"staging": {Queue: queue.Queue{}, Reservations: queue.Reservations{r}}},
+			channel:          "C1A2B3C",
+			args:             "staging",
+			expectedTokens:   queue.Tokens{"staging": {Queue: queue.Queue{{ID: "U123", Reason: "Banana"}}, Reservations: queue.Reservations{started}}},
+			expectedResponse: "<@U123|craig> (Banana) has reserved the `staging` token for Tue 14 Mar 10:00-11:00\n*<@U123|craig> (Banana) now has the `staging` token*",
+		},
+	})
+}
+
+func TestReservationEnded(t *testing.T) {
+	cmd := command.New(id, name, userCache)
+	r := reserved("U123", "Banana", reserveNow, time.Hour)
+	r.Started = true
+
+	testChannelCommand(t, func(tokens queue.Tokens, ch, user, args string) (queue.Tokens, command.Notification) {
+		return cmd.ReservationEnded(tokens, ch, args, r)
+	}, []TokenTest{
+		{
+			test:             "release token from reserver",
+			startTokens:      queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{{ID: "U123", Reason: "Banana"}, {ID: "U456", Reason: "Apple"}}, Reservations: queue.Reservations{r}}},
+			channel:          "C1A2B3C",
+			args:             queue.DefaultToken,
+			expectedTokens:   queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{{ID: "U456", Reason: "Apple"}}}},
+			expectedResponse: "The reservation for Tue 14 Mar 10:00-11:00 has ended\n<@U123|craig> (Banana) has finished with the token\n*<@U456|edward> (Apple) now has the token*",
+		},
+		{
+			test:             "forget reservation if reserver has already finished",
+			startTokens:      queue.Tokens{"staging": {Queue: queue.Queue{{ID: "U456", Reason: "Apple"}}, Reservations: queue.Reservations{r}}},
+			channel:          "C1A2B3C",
+			args:             "staging",
+			expectedTokens:   queue.Tokens{"staging": {Queue: queue.Queue{{ID: "U456", Reason: "Apple"}}}},
+			expectedResponse: "",
+		},
+	})
+}
@@ -2,15 +2,27 @@ package command
 
 import (
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/doozr/qbot/history"
+	"github.com/doozr/qbot/journal"
+	"github.com/doozr/qbot/preferences"
 	"github.com/doozr/qbot/queue"
 	"github.com/doozr/qbot/usercache"
-	"github.com/doozr/qbot/util"
 )
 
-// responses builds mad-libbed reply strings
+// responses builds mad-libbed reply strings from the templates of a theme
 type responses struct {
 	UserCache usercache.UserCache
+	token     string
+	capacity  int
+	theme     Theme
+}
+
+func (n responses) isNamedToken() bool {
+	return n.token != "" && n.token != queue.DefaultToken
 }
 
 func (n responses) getUserName(id string) (username string) {
@@ -22,196 +34,512 @@ func (n responses) link(i string) string {
 	return fmt.Sprintf("<@%s|%s>", i, n.getUserName(i))
 }
 
-func (n responses) item(i queue.Item) string {
-	return fmt.Sprintf("%s (%s)", n.link(i.ID), i.Reason)
-}
-
-func (n responses) finishedWithToken(i queue.Item) string {
-	return fmt.Sprintf("%s has finished with the token", n.item(i))
-}
-
-func (n responses) nowHasToken(i queue.Item) string {
-	return fmt.Sprintf("*%s now has the token*", n.item(i))
-}
-
-func (n responses) upForGrabs() string {
-	return fmt.Sprint("The token is up for grabs")
-}
-
-func (n responses) yielded(i queue.Item) string {
-	return fmt.Sprintf("%s has yielded the token", n.item(i))
+// capitalise makes the first letter of a sentence upper case
+func capitalise(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
 }
 
-func (n responses) ousted(ouster string, i queue.Item) string {
-	return fmt.Sprintf("%s ousted %s", n.link(ouster), n.item(i))
+// reply suggests how to accept or decline a request, naming the entry if there could be more than one
+func reply(r queue.Request, cmd string) string {
+	if r.Item.Key == "" {
+		return fmt.Sprintf("`%s`", cmd)
+	}
+	return fmt.Sprintf("`%s #%s`", cmd, r.Item.Key)
 }
 
 // BadIndex is an attempt to manipulate the queue with an out of range index
 func (n responses) BadIndex(id string) string {
-	return fmt.Sprintf("%s That's not a valid position in the queue", n.link(id))
+	return n.render("BadIndex", fields{"ID": id})
+}
+
+// BadKey is an attempt to manipulate the queue with a key that no entry has
+func (n responses) BadKey(id, key string) string {
+	return n.render("BadKey", fields{"ID": id, "Key": key})
 }
 
 // NotOwned is an attempt to change a queue entry that the user does not own
 func (n responses) NotOwned(id string, position int) string {
-	suffix := util.Suffix(position)
-	ordinal := fmt.Sprintf("%d%s", position, suffix)
-	return fmt.Sprintf("%s You are not %s in line", n.link(id), ordinal)
+	return n.render("NotOwned", fields{"ID": id, "Position": position})
 }
 
 // Join is a successful join to the queue
 func (n responses) Join(i queue.Item, position int) string {
-	ordinal := "next"
-	if position > 2 {
-		suffix := util.Suffix(position)
-		ordinal = fmt.Sprintf("%d%s", position, suffix)
-	}
-	return fmt.Sprintf("%s is now %s in line", n.item(i), ordinal)
+	return n.render("Join", fields{"Item": i, "Position": position, "Next": position <= n.capacity+1})
 }
 
 // ReplaceNoReason tells the user that a reason is required when replacing
 func (n responses) ReplaceNoReason(i queue.Item) string {
-	return fmt.Sprintf("%s You must provide a new reason", n.link(i.ID))
+	return n.render("ReplaceNoReason", fields{"Item": i})
 }
 
 // JoinNoReason tells the user that a reason is required on join
 func (n responses) JoinNoReason(i queue.Item) string {
-	return fmt.Sprintf("%s You must provide a reason for joining", n.link(i.ID))
+	return n.render("JoinNoReason", fields{"Item": i})
 }
 
 // JoinActive tells the user that they have immediately taken the token on join
 func (n responses) JoinActive(i queue.Item) string {
-	return fmt.Sprintf("%s", n.nowHasToken(i))
+	return n.render("JoinActive", fields{"Item": i})
 }
 
 // Leave is a successful leave from the queue
 func (n responses) Leave(i queue.Item) string {
-	return fmt.Sprintf("%s has left the queue", n.item(i))
+	return n.render("Leave", fields{"Item": i})
 }
 
 // LeaveActive tells the user they should use done rather than leave if they have the token
 func (n responses) LeaveActive(i queue.Item) string {
-	return fmt.Sprintf("%s You have the token, did you mean `done` or `drop`?", n.link(i.ID))
+	return n.render("LeaveActive", fields{"Item": i})
 }
 
 // LeaveNoEntry tells the user that an entry does not exist
 func (n responses) LeaveNoEntry(id string) string {
-	return fmt.Sprintf("%s No entry was found", n.link(id))
+	return n.render("LeaveNoEntry", fields{"ID": id})
 }
 
 // Done is a successful drop of the token
-func (n responses) Done(i queue.Item, q queue.Queue) string {
-	a := q.Active()
-	return fmt.Sprintf("%s\n%s",
-		n.finishedWithToken(i), n.nowHasToken(a))
+func (n responses) Done(i queue.Item, promoted []queue.Item) string {
+	return n.render("Done", fields{"Item": i, "Promoted": promoted})
 }
 
 // DoneNoOthers is a successful drop of the token when nobody can pick it up
 func (n responses) DoneNoOthers(i queue.Item) string {
-	return fmt.Sprintf("%s\n%s",
-		n.finishedWithToken(i), n.upForGrabs())
+	return n.render("DoneNoOthers", fields{"Item": i})
 }
 
 // DoneNotActive tells the user that they must have the token to use done
 func (n responses) DoneNotActive(user string) string {
-	return fmt.Sprintf("%s You cannot be done if you don't have the token", n.link(user))
+	return n.render("DoneNotActive", fields{"ID": user})
 }
 
 // Yield is a successful passing of the token to next in line
-func (n responses) Yield(i queue.Item, q queue.Queue) string {
-	a := q.Active()
-	return fmt.Sprintf("%s\n%s", n.yielded(i), n.nowHasToken(a))
+func (n responses) Yield(i queue.Item, promoted []queue.Item) string {
+	return n.render("Yield", fields{"Item": i, "Promoted": promoted})
 }
 
 // YieldNoOthers tells the user that they cannot yield if nobody is waiting
 func (n responses) YieldNoOthers(i queue.Item) string {
-	return fmt.Sprintf("%s You cannot yield if there is nobody waiting", n.link(i.ID))
+	return n.render("YieldNoOthers", fields{"Item": i})
 }
 
 // YieldNotActive tells the user they that must have the token to yield
 func (n responses) YieldNotActive(i queue.Item) string {
-	return fmt.Sprintf("%s You cannot yield if you do not have the token", n.link(i.ID))
+	return n.render("YieldNotActive", fields{"Item": i})
 }
 
 // Barge is a successful barge to the front of the queue
-func (n responses) Barge(i queue.Item, a queue.Item) string {
-	return fmt.Sprintf("%s barged to the front\n%s still has the token", n.item(i), n.item(a))
+func (n responses) Barge(i queue.Item, holders []queue.Item) string {
+	return n.render("Barge", fields{"Item": i, "Holders": holders})
 }
 
 // Boot is a successful force remove from the queue
 func (n responses) Boot(booter string, i queue.Item) string {
-	return fmt.Sprintf("%s booted %s from the list", n.link(booter), n.item(i))
+	return n.render("Boot", fields{"Booter": booter, "Item": i})
 }
 
 // BootNoEntry tells the user that an entry with the requested user does not exist
 func (n responses) BootNoEntry(id, name string) string {
-	return fmt.Sprintf("%s No entry for %s was found", n.link(id), name)
+	return n.render("BootNoEntry", fields{"ID": id, "Name": name})
 }
 
 // OustNotBoot tells the user that they can't boot the token holder
 func (n responses) OustNotBoot(booter string) string {
-	return fmt.Sprintf("%s You must oust the token holder", n.link(booter))
+	return n.render("OustNotBoot", fields{"ID": booter})
 }
 
 // Oust is a successful oust
-func (n responses) Oust(ouster string, i queue.Item, a queue.Item) string {
-	return fmt.Sprintf("%s\n%s", n.ousted(ouster, i), n.nowHasToken(a))
+func (n responses) Oust(ouster string, i queue.Item, promoted []queue.Item) string {
+	return n.render("Oust", fields{"Ouster": ouster, "Item": i, "Promoted": promoted})
 }
 
 // OustNotActive tells the user they can only oust the token holder
 func (n responses) OustNotActive(ouster string) string {
-	return fmt.Sprintf("%s You can only oust the token holder", n.link(ouster))
+	return n.render("OustNotActive", fields{"ID": ouster})
 }
 
 // OustNoTarget tells the user they must specify a target to oust
 func (n responses) OustNoTarget(ouster string) string {
-	return fmt.Sprintf("%s You must specify who you want to oust", n.link(ouster))
+	return n.render("OustNoTarget", fields{"ID": ouster})
+}
+
+// HoldReminder privately asks the token holder to release the token
+func (n responses) HoldReminder(i queue.Item, held, remaining time.Duration) string {
+	return n.render("HoldReminder", fields{"Item": i, "Held": held, "Remaining": remaining})
+}
+
+// HoldEscalation tells the channel that the token holder has had the token too long
+func (n responses) HoldEscalation(i queue.Item, held, remaining time.Duration) string {
+	return n.render("HoldEscalation", fields{"Item": i, "Held": held, "Remaining": remaining})
 }
 
 // OustNoOthers is a successful oust when nobody can pick up the token
 func (n responses) OustNoOthers(ouster string, i queue.Item) string {
-	return fmt.Sprintf("%s\n%s", n.ousted(ouster, i), n.upForGrabs())
+	return n.render("OustNoOthers", fields{"Ouster": ouster, "Item": i})
 }
 
 func (n responses) Delegate(i queue.Item, target string) string {
-	return fmt.Sprintf("%s has delegated to %s", n.item(i), n.link(target))
+	return n.render("Delegate", fields{"Item": i, "Target": target})
 }
 
 func (n responses) DelegateActive(i queue.Item, ni queue.Item) string {
-	return fmt.Sprintf("%s\n%s", n.Delegate(i, ni.ID), n.nowHasToken(ni))
+	return n.render("DelegateActive", fields{"Item": i, "Next": ni})
 }
 
 func (n responses) DelegateNoSuchUser(delegator, target string) string {
-	return fmt.Sprintf("%s You cannot delegate to %s because they don't exist", n.link(delegator), target)
+	return n.render("DelegateNoSuchUser", fields{"ID": delegator, "Target": target})
 }
 
 func (n responses) DelegateNoEntry(delegator string) string {
-	return fmt.Sprintf("%s You cannot delegate if you are not in the queue", n.link(delegator))
+	return n.render("DelegateNoEntry", fields{"ID": delegator})
 }
 
 func (n responses) RefuseToken() string {
-	return "What am I going to do with the token?"
+	return n.render("RefuseToken", nil)
 }
 
 func (n responses) RefuseTokenActive(i queue.Item, ni queue.Item) string {
-	return fmt.Sprintf("%s\n:zap: :zap: AT LAST! ULTIMATE POWER! :zap: :zap:\n\nJust kidding ... I don't need the token, you can have it back\n%s", n.DelegateActive(i, ni), n.DelegateActive(ni, i))
+	return n.render("RefuseTokenActive", fields{"Item": i, "Next": ni})
 }
 
-func (n responses) SuccessNotification(id string, message string) string {
-	response := fmt.Sprintf("Received a success notification from %s", n.link(id))
-	if message == "" {
-		return response
-	}
-	return fmt.Sprintf("%s\n%s", response, message)
+func (n responses) SuccessNotification(id, key, message string) string {
+	return n.render("SuccessNotification", fields{"ID": id, "Key": key, "Message": message})
+}
+
+// SuccessMismatch tells the sender of a success notification that no token holder matches its correlation key
+func (n responses) SuccessMismatch(id, key string, holders []queue.Item) string {
+	return n.render("SuccessMismatch", fields{"ID": id, "Key": key, "Holders": holders})
 }
 
 func (n responses) FailureNotificationEmptyQueue(id string, message string) string {
-	return fmt.Sprintf("Received a failure notification from %s: %s", n.link(id), message)
+	return n.render("FailureNotificationEmptyQueue", fields{"ID": id, "Message": message})
+}
+
+func (n responses) FailureNotification(id, key string, ids []string, message string) string {
+	return n.render("FailureNotification", fields{"ID": id, "Key": key, "IDs": ids, "Message": message})
+}
+
+// FailureMismatch tells the sender of a failure notification that no token holder matches its correlation key
+func (n responses) FailureMismatch(id, key string, holders []queue.Item, message string) string {
+	return n.render("FailureMismatch", fields{"ID": id, "Key": key, "Holders": holders, "Message": message})
+}
+
+// TokenCreated is a successful creation of a named token
+func (n responses) TokenCreated(name string, capacity int) string {
+	return n.render("TokenCreated", fields{"Name": name, "Capacity": capacity})
+}
+
+// TokenCapacity is a successful change to the number of people who can hold a token at once
+func (n responses) TokenCapacity(promoted []queue.Item) string {
+	return n.render("TokenCapacity", fields{"Promoted": promoted})
+}
+
+// TokenBadCapacity tells the user that the number of people who can hold a token at once is not valid
+func (n responses) TokenBadCapacity(id, capacity string) string {
+	return n.render("TokenBadCapacity", fields{"ID": id, "Capacity": capacity})
+}
+
+// TokenCapacityInUse tells the user that a token cannot be held by fewer people than currently hold it
+func (n responses) TokenCapacityInUse(id string, holders int) string {
+	return n.render("TokenCapacityInUse", fields{"ID": id, "Holders": holders})
+}
+
+// TokenDeleted is a successful deletion of a named token
+func (n responses) TokenDeleted(name string) string {
+	return n.render("TokenDeleted", fields{"Name": name})
+}
+
+// TokenNoName tells the user that a token name is required
+func (n responses) TokenNoName(id string) string {
+	return n.render("TokenNoName", fields{"ID": id})
+}
+
+// TokenBadName tells the user that a token name cannot be used
+func (n responses) TokenBadName(id, name string) string {
+	return n.render("TokenBadName", fields{"ID": id, "Name": name})
+}
+
+// TokenExists tells the user that a token with the name already exists
+func (n responses) TokenExists(id, name string) string {
+	return n.render("TokenExists", fields{"ID": id, "Name": name})
+}
+
+// TokenNotFound tells the user that there is no token with the name, or that they gave `--token` without one
+func (n responses) TokenNotFound(id, name string) string {
+	return n.render("TokenNotFound", fields{"ID": id, "Name": name})
+}
+
+// TokenDeleteDefault tells the user that the default token cannot be deleted
+func (n responses) TokenDeleteDefault(id string) string {
+	return n.render("TokenDeleteDefault", fields{"ID": id, "Name": queue.DefaultToken})
+}
+
+// TokenInUse tells the user that a token cannot be deleted while it has a queue
+func (n responses) TokenInUse(id, name string) string {
+	return n.render("TokenInUse", fields{"ID": id, "Name": name})
+}
+
+// Reserve is a successful booking of the token
+func (n responses) Reserve(r queue.Reservation) string {
+	return n.render("Reserve", fields{"Item": r.Item(), "Reservation": r})
+}
+
+// ReserveBadStart tells the user that the start of a reservation is not a time
+func (n responses) ReserveBadStart(id, start string) string {
+	return n.render("ReserveBadStart", fields{"ID": id, "Start": start})
+}
+
+// ReserveBadDuration tells the user that the length of a reservation is not a duration
+func (n responses) ReserveBadDuration(id, duration string) string {
+	return n.render("ReserveBadDuration", fields{"ID": id, "Duration": duration})
+}
+
+// ReserveNoReason tells the user that a reason is required when reserving
+func (n responses) ReserveNoReason(id string) string {
+	return n.render("ReserveNoReason", fields{"ID": id})
+}
+
+// ReservePast tells the user that a reservation would already be over
+func (n responses) ReservePast(id string) string {
+	return n.render("ReservePast", fields{"ID": id})
+}
+
+// ReserveConflict tells the user that the token is already booked for some of the time
+func (n responses) ReserveConflict(id string, conflicts queue.Reservations) string {
+	return n.render("ReserveConflict", fields{"ID": id, "Conflicts": conflicts})
+}
+
+// ReservationStarted tells the channel that a reservation has started and who no longer holds the token
+func (n responses) ReservationStarted(r queue.Reservation, displaced []queue.Item) string {
+	return n.render("ReservationStarted", fields{"Item": r.Item(), "Reservation": r, "Displaced": displaced})
+}
+
+// ReservationEnded tells the channel that a reservation is over and who has the token now
+func (n responses) ReservationEnded(r queue.Reservation, done string) string {
+	return n.render("ReservationEnded", fields{"Reservation": r, "Done": done})
+}
+
+// ListEmpty says that nobody has the token or is waiting for it
+func (n responses) ListEmpty() string {
+	return n.render("ListEmpty", nil)
+}
+
+// ListHolder is the line of a list for an entry that holds the token
+func (n responses) ListHolder(i queue.Item, position int) string {
+	return n.render("ListHolder", fields{"Item": i, "Position": position})
+}
+
+// ListWaiting is the line of a list for an entry that is waiting for the token
+func (n responses) ListWaiting(i queue.Item, position int) string {
+	return n.render("ListWaiting", fields{"Item": i, "Position": position})
+}
+
+// ListReserved is the line of a list for a reservation that has not started yet
+func (n responses) ListReserved(r queue.Reservation) string {
+	return n.render("ListReserved", fields{"Reservation": r})
+}
+
+// ListHeading names a token, along with how many can hold it at once if more than one can
+func (n responses) ListHeading() string {
+	return n.render("ListHeading", nil)
+}
+
+func (n responses) UndoNothing(id string) string {
+	return n.render("UndoNothing", fields{"ID": id})
 }
 
-func (n responses) FailureNotification(id string, ids []string, message string) string {
-	users := ""
-	for _, i := range ids {
-		users += fmt.Sprintf("%s ", n.link(i))
+func (n responses) UndoConflict(id string, change history.Change, actors []string) string {
+	return n.render("UndoConflict", fields{"ID": id, "Command": change.Command, "Actors": actors})
+}
+
+func (n responses) UndoTokenGone() string {
+	return n.render("UndoTokenGone", nil)
+}
+
+func (n responses) Undo(id string, change history.Change, restored string) string {
+	return n.render("Undo", fields{"ID": id, "Change": change, "Restored": restored})
+}
+
+func (n responses) StatsBadPeriod(id, period string) string {
+	return n.render("StatsBadPeriod", fields{"ID": id, "Period": period})
+}
+
+// userStats are the stats of one user, for listing
+type userStats struct {
+	ID string
+	journal.UserStats
+}
+
+func (n responses) Stats(period string, all bool, s journal.Stats) string {
+	users := []userStats{}
+	for id, u := range s.Users {
+		users = append(users, userStats{id, u})
 	}
-	return fmt.Sprintf("%sReceived a failure notification from %s: %s", users, n.link(id), message)
+	sort.Slice(users, func(i, j int) bool {
+		a, b := users[i], users[j]
+		if a.Held != b.Held {
+			return a.Held > b.Held
+		}
+		return n.getUserName(a.ID) < n.getUserName(b.ID)
+	})
+
+	return n.render("Stats", fields{
+		"Period": period,
+		"All":    all,
+		"Empty":  len(s.Users) == 0 && s.Barges == 0 && s.Ousts == 0,
+		"Waits":  s.Waits,
+		"Holds":  s.Holds,
+		"Barges": s.Barges,
+		"Ousts":  s.Ousts,
+		"Users":  users,
+	})
+}
+
+func (n responses) MoveNotWaiting(id string) string {
+	return n.render("MoveNotWaiting", fields{"ID": id})
+}
+
+func (n responses) MoveNowhere(i queue.Item, position int) string {
+	return n.render("MoveNowhere", fields{"Item": i, "Position": position})
+}
+
+func (n responses) Move(i queue.Item, from, to int, order string) string {
+	return n.render("Move", fields{"Item": i, "From": from, "To": to, "Order": order})
+}
+
+func (n responses) MoveRequested(r queue.Request, to int, ids []string) string {
+	return n.render("MoveRequested", fields{"Request": r, "To": to, "IDs": ids})
+}
+
+func (n responses) MoveApproved(id string, r queue.Request, ids []string) string {
+	return n.render("MoveApproved", fields{"ID": id, "Request": r, "IDs": ids})
+}
+
+func (n responses) MoveDeclined(id string, r queue.Request) string {
+	return n.render("MoveDeclined", fields{"ID": id, "Request": r})
+}
+
+func (n responses) NothingToAccept(id string) string {
+	return n.render("NothingToAccept", fields{"ID": id})
+}
+
+func (n responses) SwapNotWaiting(id string) string {
+	return n.render("SwapNotWaiting", fields{"ID": id})
+}
+
+func (n responses) SwapOwn(id string) string {
+	return n.render("SwapOwn", fields{"ID": id})
+}
+
+func (n responses) SwapRequested(r queue.Request, from, to int) string {
+	return n.render("SwapRequested", fields{"Request": r, "From": from, "To": to})
+}
+
+func (n responses) Swap(r queue.Request, order string) string {
+	return n.render("Swap", fields{"Request": r, "Order": order})
+}
+
+func (n responses) RequestExpired(r queue.Request) string {
+	return n.render("RequestExpired", fields{"Request": r})
+}
+
+func (n responses) NotifyUnavailable(id string) string {
+	return n.render("NotifyUnavailable", fields{"ID": id})
+}
+
+func (n responses) NotifyBadSetting(id, setting string) string {
+	return n.render("NotifyBadSetting", fields{"ID": id, "Setting": setting})
+}
+
+func (n responses) NotifyNotSaved(id string) string {
+	return n.render("NotifyNotSaved", fields{"ID": id})
+}
+
+func (n responses) Notify(level preferences.Notify) string {
+	return n.render("Notify", fields{"Level": string(level)})
+}
+
+func (n responses) NotifyAcquired(ch string, i queue.Item) string {
+	return n.render("NotifyAcquired", fields{"Channel": ch, "Item": i})
+}
+
+func (n responses) NotifyNext(ch string, i queue.Item) string {
+	return n.render("NotifyNext", fields{"Channel": ch, "Item": i})
+}
+
+func (n responses) NotifyPosition(ch string, i queue.Item, position int) string {
+	return n.render("NotifyPosition", fields{"Channel": ch, "Item": i, "Position": position})
+}
+
+func (n responses) WhereNothing() string {
+	return n.render("WhereNothing", nil)
+}
+
+func (n responses) WhereHolding(ch string, i queue.Item, now time.Time) string {
+	return n.render("WhereHolding", fields{
+		"Channel": ch,
+		"Item":    i,
+		"Since":   !i.ActiveSince.IsZero(),
+		"Held":    now.Sub(i.ActiveSince),
+	})
+}
+
+func (n responses) WhereWaiting(ch string, i queue.Item, position int, now time.Time, wait time.Duration) string {
+	return n.render("WhereWaiting", fields{
+		"Channel":  ch,
+		"Item":     i,
+		"Position": position,
+		"Joined":   !i.JoinedAt.IsZero(),
+		"Waited":   now.Sub(i.JoinedAt),
+		"Unknown":  wait < 0,
+		"Soon":     wait >= 0 && wait < time.Minute,
+		"Wait":     wait,
+	})
+}
+
+func (n responses) AdminOnly(id, cmd string) string {
+	return n.render("AdminOnly", fields{"ID": id, "Command": cmd})
+}
+
+func (n responses) OustVoteNotWaiting(id string) string {
+	return n.render("OustVoteNotWaiting", fields{"ID": id})
+}
+
+func (n responses) OustVote(voter string, i queue.Item, votes, needed int, window time.Duration) string {
+	return n.render("OustVote", fields{"Voter": voter, "Item": i, "Votes": votes, "Needed": needed, "Window": window})
+}
+
+func (n responses) OustVoted(i queue.Item, voters []string, promoted []queue.Item) string {
+	return n.render("OustVoted", fields{"Item": i, "Voters": voters, "Promoted": promoted})
+}
+
+func (n responses) NotifyOustVote(ch string, i queue.Item, needed int, window time.Duration) string {
+	return n.render("NotifyOustVote", fields{"Channel": ch, "Item": i, "Needed": needed, "Window": window})
+}
+
+// Unknown tells the user that a command is not known, and which one they might have meant
+func (n responses) Unknown(id, cmd, suggestion string) string {
+	return n.render("Unknown", fields{"ID": id, "Command": cmd, "Suggestion": suggestion})
+}
+
+// Help sums up every command under its heading
+func (n responses) Help(name string, sections []helpSection) string {
+	return n.render("Help", fields{"Name": name, "Sections": sections})
+}
+
+// HelpTopic explains one command in detail
+func (n responses) HelpTopic(t helpTopic) string {
+	return n.render("HelpTopic", t)
+}
+
+// UserAmbiguous tells the user that more than one person goes by the name they gave, and who they are
+func (n responses) UserAmbiguous(id, name string, candidates []string) string {
+	return n.render("UserAmbiguous", fields{"ID": id, "Name": name, "Candidates": candidates})
+}
+
+// HelpNotFound tells the user there is no such command to explain
+func (n responses) HelpNotFound(id, cmd string) string {
+	return n.render("HelpNotFound", fields{"ID": id, "Command": cmd})
 }
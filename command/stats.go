@@ -0,0 +1,62 @@
+package command
+
+import (
+	"strings"
+	"time"
+
+	"github.com/doozr/qbot/journal"
+	"github.com/doozr/qbot/queue"
+)
+
+// statsPeriods are the periods that stats can be worked out over
+var statsPeriods = map[string]time.Duration{
+	"day":   24 * time.Hour,
+	"week":  7 * 24 * time.Hour,
+	"month": 30 * 24 * time.Hour,
+}
+
+// parsePeriod reads the period to work out stats over, which is a week if none is given
+func (c QueueCommands) parsePeriod(args string) (period string, since time.Time, ok bool) {
+	period = strings.ToLower(parseArgs(args).first())
+	if period == "" {
+		period = "week"
+	}
+
+	d, ok := statsPeriods[period]
+	return period, c.clock().Add(-d), ok
+}
+
+// stats works out the stats for the given channels
+func (c QueueCommands) stats(channels []string, id, args string, all bool) string {
+	period, since, ok := c.parsePeriod(args)
+	if !ok {
+		return c.response.StatsBadPeriod(id, period)
+	}
+
+	in := map[string]bool{}
+	for _, channel := range channels {
+		in[channel] = true
+	}
+	events := []journal.Event{}
+	if c.events != nil {
+		for _, e := range c.events.Events() {
+			if in[e.Channel] {
+				events = append(events, e)
+			}
+		}
+	}
+
+	return c.response.Stats(period, all, journal.Summarise(events, "", since))
+}
+
+// Stats shows how long people have waited for and held the tokens in the channel
+func (c QueueCommands) Stats(t queue.Tokens, ch, id, args string) (queue.Tokens, Notification) {
+	c = c.forChannel(ch)
+	return t, Notification{ch, c.stats([]string{ch}, id, args, false)}
+}
+
+// StatsAll shows how long people have waited for and held the tokens in every channel the user is known to be in
+func (c QueueCommands) StatsAll(qs queue.Channels, ch, id, args string) Notification {
+	c = c.forChannel(ch)
+	return Notification{ch, c.stats(c.knownChannels(qs, id), id, args, true)}
+}
@@ -0,0 +1,140 @@
+package command_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/doozr/qbot/command"
+	"github.com/doozr/qbot/journal"
+	"github.com/doozr/qbot/queue"
+)
+
+var statsNow = time.Date(2017, 3, 14, 12, 0, 0, 0, time.UTC)
+
+// statsEvents has craig hold the token for an hour while edward waits, then edward hold it for half an hour, after
+// andrew barged in and held it for an hour a few weeks ago. andrew is also holding a token in another channel.
+func statsEvents() []journal.Event {
+	start := statsNow.Add(-2 * time.Hour)
+	craig := queue.Item{ID: "U123", Reason: "Banana", Key: "fp8", JoinedAt: start, ActiveSince: start}
+	edward := queue.Item{ID: "U456", Reason: "Potato", Key: "lqy", JoinedAt: start}
+	active := edward
+	active.ActiveSince = start.Add(time.Hour)
+	andrew := queue.Item{ID: "U789", Reason: "Tomato", Key: "jox", JoinedAt: statsNow.AddDate(0, 0, -20), ActiveSince: statsNow.AddDate(0, 0, -20)}
+	elsewhere := queue.Item{ID: "U789", Reason: "Tomato", Key: "jox", JoinedAt: start, ActiveSince: start}
+
+	entry := func(at time.Time, actor, command, channel string, i queue.Item, before, after int) journal.Event {
+		return journal.Event{Time: at, Actor: actor, Command: command, Type: journal.EntryEvent,
+			Channel: channel, Token: queue.DefaultToken, Item: &i, Before: before, After: after}
+	}
+
+	return []journal.Event{
+		entry(andrew.JoinedAt, "U789", "barge", "C1A2B3C", andrew, 0, 1),
+		entry(andrew.JoinedAt.Add(time.Hour), "U789", "done", "C1A2B3C", andrew, 1, 0),
+		entry(start, "U123", "join", "C1A2B3C", craig, 0, 1),
+		entry(start, "U456", "join", "C1A2B3C", edward, 0, 2),
+		entry(start.Add(time.Hour), "U123", "done", "C1A2B3C", craig, 1, 0),
+		entry(start.Add(time.Hour), "U123", "done", "C1A2B3C", active, 1, 1),
+		entry(start.Add(90*time.Minute), "U456", "done", "C1A2B3C", active, 1, 0),
+		entry(start, "U789", "join", "C4D5E6F", elsewhere, 0, 1),
+	}
+}
+
+func TestStats(t *testing.T) {
+	cmd := command.New(id, name, userCache).
+		WithClock(func() time.Time { return statsNow }).
+		WithEvents(journal.NewLog(statsEvents(), 31*24*time.Hour))
+
+	testChannelCommand(t, cmd.Stats, []TokenTest{
+		{
+			test:           "show stats for the last week by default",
+			startTokens:    queue.Tokens{},
+			channel:        "C1A2B3C",
+			user:           "U123",
+			args:           "",
+			expectedTokens: queue.Tokens{},
+			expectedResponse: "*Stats for the last week*\n" +
+				"Waited for the token: median less than a minute, 90% within 1h (2 waits)\n" +
+				"Held the token: median 30m, 90% within 1h (2 holds)\n" +
+				"Barges: 0, ousts: 0\n" +
+				"*Per user:*\n" +
+				"craig: held once for 1h in total, waited less than a minute in total\n" +
+				"edward: held once for 30m in total, waited 1h in total",
+		},
+		{
+			test:           "show stats for a month",
+			startTokens:    queue.Tokens{},
+			channel:        "C1A2B3C",
+			user:           "U123",
+			args:           "Month",
+			expectedTokens: queue.Tokens{},
+			expectedResponse: "*Stats for the last month*\n" +
+				"Waited for the token: median less than a minute, 90% within 1h (3 waits)\n" +
+				"Held the token: median 1h, 90% within 1h (3 holds)\n" +
+				"Barges: 1, ousts: 0\n" +
+				"*Per user:*\n" +
+				"andrew: held once for 1h in total, waited less than a minute in total\n" +
+				"craig: held once for 1h in total, waited less than a minute in total\n" +
+				"edward: held once for 30m in total, waited 1h in total",
+		},
+		{
+			test:           "show stats for a day",
+			startTokens:    queue.Tokens{},
+			channel:        "C1A2B3C",
+			user:           "U123",
+			args:           "day",
+			expectedTokens: queue.Tokens{},
+			expectedResponse: "*Stats for the last day*\n" +
+				"Waited for the token: median less than a minute, 90% within 1h (2 waits)\n" +
+				"Held the token: median 30m, 90% within 1h (2 holds)\n" +
+				"Barges: 0, ousts: 0\n" +
+				"*Per user:*\n" +
+				"craig: held once for 1h in total, waited less than a minute in total\n" +
+				"edward: held once for 30m in total, waited 1h in total",
+		},
+		{
+			test:             "refuse an unknown period",
+			startTokens:      queue.Tokens{},
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "fortnight",
+			expectedTokens:   queue.Tokens{},
+			expectedResponse: "<@U123|craig> `fortnight` is not a period I know; use `day`, `week` or `month`",
+		},
+	})
+
+	testChannelCommand(t, cmd.Stats, []TokenTest{
+		{
+			test:             "say when nothing has happened in the channel",
+			startTokens:      queue.Tokens{},
+			channel:          "C9Z8Y7X",
+			user:             "U123",
+			expectedTokens:   queue.Tokens{},
+			expectedResponse: "*Stats for the last week*\nNothing has happened",
+		},
+	})
+}
+
+func TestStatsAll(t *testing.T) {
+	cmd := command.New(id, name, userCache).
+		WithClock(func() time.Time { return statsNow }).
+		WithEvents(journal.NewLog(statsEvents(), 31*24*time.Hour))
+
+	r := cmd.StatsAll(queue.Channels{}, "D1234", "U789", "")
+	assertResponse(t, "stats across every channel the user is in", "D1234", "*Stats for the last week in your channels*\n"+
+		"Waited for the token: median less than a minute, 90% within 1h (3 waits)\n"+
+		"Held the token: median 30m, 90% within 1h (2 holds)\n"+
+		"Barges: 0, ousts: 0\n"+
+		"*Per user:*\n"+
+		"craig: held once for 1h in total, waited less than a minute in total\n"+
+		"edward: held once for 30m in total, waited 1h in total\n"+
+		"andrew: waited less than a minute in total", r)
+
+	r = cmd.StatsAll(queue.Channels{}, "D1234", "U123", "")
+	assertResponse(t, "leave out channels the user has not been part of", "D1234", "*Stats for the last week in your channels*\n"+
+		"Waited for the token: median less than a minute, 90% within 1h (2 waits)\n"+
+		"Held the token: median 30m, 90% within 1h (2 holds)\n"+
+		"Barges: 0, ousts: 0\n"+
+		"*Per user:*\n"+
+		"craig: held once for 1h in total, waited less than a minute in total\n"+
+		"edward: held once for 30m in total, waited 1h in total", r)
+}
@@ -2,23 +2,37 @@ package command
 
 import "github.com/doozr/qbot/queue"
 
-// Success removes the active user from the queue
+// Success removes the first token holder from the queue
+//
+// Given a correlation key with `--key`, such as a branch name or entry key, it removes the token holder that the key
+// matches instead, and changes nothing if no holder matches. Any other text is ignored, as it is by Failure.
 func (c QueueCommands) Success(q queue.Queue, ch, id, args string) (queue.Queue, Notification) {
 	c.logActivity(id, "notification", "success")
 
+	key, _ := parseArgs(args, "key").option("key")
+	if key != "" {
+		i, ok := c.findCorrelated(q, key)
+		if !ok {
+			return q, Notification{ch, c.response.SuccessMismatch(id, key, q.Holders(c.capacity))}
+		}
+		return c.success(q, ch, id, key, i)
+	}
+
 	if len(q) == 0 {
-		return q, Notification{ch, c.response.SuccessNotification(id, "")}
+		return q, Notification{ch, c.response.SuccessNotification(id, "", "")}
 	}
+	return c.success(q, ch, id, "", q.Active())
+}
 
-	i := q.Active()
-	q = q.Remove(i)
+// success removes a token holder that an automated system says is done
+func (c QueueCommands) success(q queue.Queue, ch, id, key string, i queue.Item) (queue.Queue, Notification) {
+	nq := q.Remove(i)
 	c.logActivity(id, i.Reason, "done")
 
-	if len(q) > 0 {
-		n := q.Active()
-		c.logActivity(n.ID, n.Reason, "is active")
-		return q, Notification{ch, c.response.SuccessNotification(id, c.response.Done(i, q))}
+	if p := c.promoted(q, nq); len(p) > 0 {
+		c.logPromoted(p)
+		return nq, Notification{ch, c.response.SuccessNotification(id, key, c.response.Done(i, p))}
 	}
 
-	return q, Notification{ch, c.response.SuccessNotification(id, c.response.DoneNoOthers(i))}
+	return nq, Notification{ch, c.response.SuccessNotification(id, key, c.response.DoneNoOthers(i))}
 }
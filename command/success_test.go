@@ -26,6 +26,15 @@ func TestSuccess(t *testing.T) {
 			expectedQueue:    queue.Queue([]queue.Item{{ID: "U456", Reason: "Next up"}}),
 			expectedResponse: "Received a success notification from <@U789|andrew>\n<@U123|craig> (Banana) has finished with the token\n*<@U456|edward> (Next up) now has the token*",
 		},
+		{
+			test:             "ignore text that is not a key",
+			startQueue:       queue.Queue([]queue.Item{{ID: "U123", Reason: "Banana"}, {ID: "U456", Reason: "Next up"}}),
+			channel:          "C1A2B3C",
+			user:             "U789",
+			args:             "build passed",
+			expectedQueue:    queue.Queue([]queue.Item{{ID: "U456", Reason: "Next up"}}),
+			expectedResponse: "Received a success notification from <@U789|andrew>\n<@U123|craig> (Banana) has finished with the token\n*<@U456|edward> (Next up) now has the token*",
+		},
 		{
 			test:             "does nothing if the queue is empty",
 			startQueue:       queue.Queue{},
@@ -36,3 +45,61 @@ func TestSuccess(t *testing.T) {
 		},
 	})
 }
+
+func TestSuccessCorrelated(t *testing.T) {
+	cmd := command.New(id, name, userCache)
+	start := queue.Tokens{"envs": {Capacity: 2, Queue: queue.Queue{
+		{ID: "U123", Reason: "PR 1234", Key: "a2y"},
+		{ID: "U456", Reason: "feature/login PR 123", Key: "b3z"},
+		{ID: "U789", Reason: "Next up"}}}}
+
+	testChannelCommand(t, cmd.Named(command.QueueCommands.Success), []TokenTest{
+		{
+			test:        "remove the holder whose reason has the key as a word",
+			startTokens: start,
+			channel:     "C1A2B3C",
+			user:        "U12345",
+			args:        "envs --key 123",
+			expectedTokens: queue.Tokens{"envs": {Capacity: 2, Queue: queue.Queue{
+				{ID: "U123", Reason: "PR 1234", Key: "a2y"},
+				{ID: "U789", Reason: "Next up"}}}},
+			expectedResponse: "Received a success notification from <@U12345|the_bot_name> for `123`\n" +
+				"<@U456|edward> (feature/login PR 123) has finished with the `envs` token\n" +
+				"*<@U789|andrew> (Next up) now has the `envs` token*",
+		},
+		{
+			test:        "remove the holder with the entry key",
+			startTokens: start,
+			channel:     "C1A2B3C",
+			user:        "U12345",
+			args:        "envs --key #A2Y",
+			expectedTokens: queue.Tokens{"envs": {Capacity: 2, Queue: queue.Queue{
+				{ID: "U456", Reason: "feature/login PR 123", Key: "b3z"},
+				{ID: "U789", Reason: "Next up"}}}},
+			expectedResponse: "Received a success notification from <@U12345|the_bot_name> for `#A2Y`\n" +
+				"<@U123|craig> (PR 1234) has finished with the `envs` token\n" +
+				"*<@U789|andrew> (Next up) now has the `envs` token*",
+		},
+		{
+			test:           "report a key that matches nobody holding the token",
+			startTokens:    start,
+			channel:        "C1A2B3C",
+			user:           "U12345",
+			args:           "envs --key next",
+			expectedTokens: start,
+			expectedResponse: "Received a success notification from <@U12345|the_bot_name> for `next`, " +
+				"but nobody holding the `envs` token matches it so nothing was changed " +
+				"(<@U123|craig> (PR 1234) and <@U456|edward> (feature/login PR 123) have it)",
+		},
+		{
+			test:           "report a key when nobody holds the token",
+			startTokens:    queue.Tokens{"envs": {Capacity: 2}},
+			channel:        "C1A2B3C",
+			user:           "U12345",
+			args:           "envs --key 123",
+			expectedTokens: queue.Tokens{"envs": {Capacity: 2}},
+			expectedResponse: "Received a success notification from <@U12345|the_bot_name> for `123`, " +
+				"but nobody holding the `envs` token matches it so nothing was changed",
+		},
+	})
+}
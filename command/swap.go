@@ -0,0 +1,87 @@
+package command
+
+import (
+	"github.com/doozr/qbot/queue"
+)
+
+// swapped swaps the entries named by the request and lists the queue that results
+func (c QueueCommands) swapped(t queue.Tokens, ch, name string, r queue.Request) (queue.Tokens, Notification) {
+	q := t.Get(name)
+	a, b := q.IndexOf(r.Item), q.IndexOf(r.Target)
+	nq := q.Swap(a, b)
+
+	t = t.Set(name, nq).SetRequests(name, t.Requests(name).Remove(r))
+	c.logActivity(r.Item.ID, r.Item.Reason, "swapped with "+c.getNameIDPair(r.Target.ID))
+	return t, Notification{ch, c.response.Swap(r, c.list(nq))}
+}
+
+// findOwnWaiting finds the user's entry nearest the front of the waiting list
+func (c QueueCommands) findOwnWaiting(q queue.Queue, id string) (item queue.Item, position int, ok bool) {
+	for ix, i := range q.WaitingBehind(c.capacity) {
+		if i.ID == id {
+			return i, ix + c.capacity + 1, true
+		}
+	}
+	return
+}
+
+// Swap asks the owner of another entry in the waiting list to swap places with one of the user's entries
+//
+// The user's entry nearest the front is swapped unless they give the key or position of another after the one they
+// want to swap with. Nobody else moves once the swap is agreed with `accept`.
+func (c QueueCommands) Swap(t queue.Tokens, ch, id, args string) (queue.Tokens, Notification) {
+	c = c.forChannel(ch)
+	name, args, ok := c.parseToken(t, args)
+	if !ok {
+		return t, Notification{ch, c.response.TokenNotFound(id, name)}
+	}
+	c = c.forToken(name, t.Capacity(name))
+	q := t.Get(name)
+
+	a := parseArgs(args)
+	target, to, rest, _, ok := c.findEntry(q, a)
+	if !ok {
+		return t, Notification{ch, c.badEntry(id, a)}
+	}
+
+	i, from, _, given, ok := c.findEntry(q, rest)
+	if given && !ok {
+		return t, Notification{ch, c.badEntry(id, rest)}
+	}
+	if given && i.ID != id {
+		return t, Notification{ch, c.response.NotOwned(id, from)}
+	}
+	if !given {
+		i, from, ok = c.findOwnWaiting(q, id)
+		if !ok {
+			return t, Notification{ch, c.response.SwapNotWaiting(id)}
+		}
+	}
+
+	if from <= c.capacity || to <= c.capacity {
+		return t, Notification{ch, c.response.SwapNotWaiting(id)}
+	}
+
+	if target.ID == id {
+		return t, Notification{ch, c.response.SwapOwn(id)}
+	}
+
+	r := queue.Request{ID: id, Item: i, Target: target, Swap: true, Expires: c.clock().Add(c.timeout)}
+	t = c.dropStale(t.SetRequests(name, t.Requests(name).Add(r)))
+	c.logActivity(id, i.Reason, "asked to swap with "+c.getNameIDPair(target.ID))
+	return t, Notification{ch, c.response.SwapRequested(r, from, to)}
+}
+
+// RequestExpired drops a request to move or swap an entry that has not been agreed in time
+func (c QueueCommands) RequestExpired(t queue.Tokens, ch, name string, r queue.Request) (queue.Tokens, Notification) {
+	c = c.forChannel(ch)
+	c = c.forToken(name, t.Capacity(name))
+	t = t.SetRequests(name, t.Requests(name).Remove(r))
+
+	if _, ok := c.awaiting(t.Get(name), r); !ok {
+		return t, Notification{ch, ""}
+	}
+
+	c.logActivity(r.Item.ID, r.Item.Reason, "request expired")
+	return t, Notification{ch, c.response.RequestExpired(r)}
+}
@@ -0,0 +1,152 @@
+package command_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/doozr/qbot/command"
+	"github.com/doozr/qbot/queue"
+)
+
+func TestSwap(t *testing.T) {
+	cmd := command.New(id, name, userCache).WithClock(func() time.Time { return moveNow })
+	start := queue.Queue{moveHolder, moveEdward, moveAndrew, moveCraig, moveLater}
+	expires := moveNow.Add(command.DefaultRequestTimeout)
+
+	testChannelCommand(t, cmd.Swap, []TokenTest{
+		{
+			test:        "ask the owner of the other entry to swap with the user's first waiting entry",
+			startTokens: moveTokens(start),
+			channel:     "C1A2B3C",
+			user:        "U123",
+			args:        "2",
+			expectedTokens: moveTokens(start,
+				queue.Request{ID: "U123", Item: moveCraig, Target: moveEdward, Swap: true, Expires: expires}),
+			expectedResponse: "<@U123|craig> (Banana) at position 4 would like to swap places with <@U456|edward> (Potato) " +
+				"at position 2 in the queue. <@U456|edward>, say `accept #ddd` to swap or `decline #ddd` to refuse",
+		},
+		{
+			test:        "swap a given entry by key",
+			startTokens: moveTokens(start),
+			channel:     "C1A2B3C",
+			user:        "U123",
+			args:        "#ccc #eee",
+			expectedTokens: moveTokens(start,
+				queue.Request{ID: "U123", Item: moveLater, Target: moveAndrew, Swap: true, Expires: expires}),
+			expectedResponse: "<@U123|craig> (Later) at position 5 would like to swap places with <@U789|andrew> (Tomato) " +
+				"at position 3 in the queue. <@U789|andrew>, say `accept #eee` to swap or `decline #eee` to refuse",
+		},
+		{
+			test:             "refuse to swap another user's entry",
+			startTokens:      moveTokens(start),
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "2 3",
+			expectedTokens:   moveTokens(start),
+			expectedResponse: "<@U123|craig> You are not 3rd in line",
+		},
+		{
+			test:             "refuse to swap with a holder",
+			startTokens:      moveTokens(start),
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "1",
+			expectedTokens:   moveTokens(start),
+			expectedResponse: "<@U123|craig> Only entries waiting for the token can swap places",
+		},
+		{
+			test:             "refuse when the user is not waiting",
+			startTokens:      moveTokens(queue.Queue{moveHolder, moveEdward}),
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "2",
+			expectedTokens:   moveTokens(queue.Queue{moveHolder, moveEdward}),
+			expectedResponse: "<@U123|craig> Only entries waiting for the token can swap places",
+		},
+		{
+			test:             "refuse to swap with own entry",
+			startTokens:      moveTokens(start),
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "5",
+			expectedTokens:   moveTokens(start),
+			expectedResponse: "<@U123|craig> You can't swap places with your own entry; use `move` instead",
+		},
+		{
+			test:             "refuse to swap with a position outside the queue",
+			startTokens:      moveTokens(start),
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "9",
+			expectedTokens:   moveTokens(start),
+			expectedResponse: "<@U123|craig> That's not a valid position in the queue",
+		},
+	})
+}
+
+func TestAcceptSwap(t *testing.T) {
+	cmd := command.New(id, name, userCache)
+	start := queue.Queue{moveHolder, moveEdward, moveAndrew, moveCraig, moveLater}
+	request := queue.Request{ID: "U123", Item: moveCraig, Target: moveEdward, Swap: true}
+
+	testChannelCommand(t, cmd.Accept, []TokenTest{
+		{
+			test:           "swap without moving anybody else",
+			startTokens:    moveTokens(start, request),
+			channel:        "C1A2B3C",
+			user:           "U456",
+			args:           "",
+			expectedTokens: moveTokens(queue.Queue{moveHolder, moveCraig, moveAndrew, moveEdward, moveLater}),
+			expectedResponse: "<@U123|craig> (Banana) has swapped places with <@U456|edward> (Potato) in the queue:\n" +
+				"*1: edward (Holder) has the token* `#aaa`\n2: craig (Banana) `#ddd`\n3: andrew (Tomato) `#ccc`\n" +
+				"4: edward (Potato) `#bbb`\n5: craig (Later) `#eee`",
+		},
+		{
+			test:             "only the owner of the other entry can agree",
+			startTokens:      moveTokens(start, request),
+			channel:          "C1A2B3C",
+			user:             "U789",
+			args:             "",
+			expectedTokens:   moveTokens(start, request),
+			expectedResponse: "<@U789|andrew> Nobody is waiting for you to let them move up or swap places",
+		},
+	})
+
+	testChannelCommand(t, cmd.Decline, []TokenTest{
+		{
+			test:             "decline a swap",
+			startTokens:      moveTokens(start, request),
+			channel:          "C1A2B3C",
+			user:             "U456",
+			args:             "#ddd",
+			expectedTokens:   moveTokens(start),
+			expectedResponse: "<@U456|edward> has declined to swap places with <@U123|craig> (Banana)",
+		},
+	})
+}
+
+func TestRequestExpired(t *testing.T) {
+	cmd := command.New(id, name, userCache)
+	start := queue.Queue{moveHolder, moveEdward, moveAndrew, moveCraig, moveLater}
+	swap := queue.Request{ID: "U123", Item: moveCraig, Target: moveEdward, Swap: true}
+	move := queue.Request{ID: "U123", Item: moveLater, Target: moveEdward}
+
+	tokens, n := cmd.RequestExpired(moveTokens(start, swap, move), "C1A2B3C", queue.DefaultToken, swap)
+	if !tokens.Equal(moveTokens(start, move)) {
+		t.Errorf("expired swap: unexpected tokens %v", tokens)
+	}
+	assertResponse(t, "expired swap", "C1A2B3C",
+		"<@U123|craig> (Banana) asked to swap places with <@U456|edward> (Potato), but it was not agreed in time", n)
+
+	tokens, n = cmd.RequestExpired(tokens, "C1A2B3C", queue.DefaultToken, move)
+	if !tokens.Equal(moveTokens(start)) {
+		t.Errorf("expired move: unexpected tokens %v", tokens)
+	}
+	assertResponse(t, "expired move", "C1A2B3C", "<@U123|craig> (Later) asked to move up, but it was not agreed in time", n)
+
+	tokens, n = cmd.RequestExpired(moveTokens(queue.Queue{moveHolder}, move), "C1A2B3C", queue.DefaultToken, move)
+	if !tokens.Equal(moveTokens(queue.Queue{moveHolder})) {
+		t.Errorf("expired stale request: unexpected tokens %v", tokens)
+	}
+	assertResponse(t, "expired stale request", "C1A2B3C", "", n)
+}
@@ -0,0 +1,165 @@
+package command
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/doozr/qbot/queue"
+)
+
+// ChannelCommand is a function for a command that can see every token in a channel
+type ChannelCommand func(t queue.Tokens, channel string, user string, args string) (queue.Tokens, Notification)
+
+// TokenCommand is a Command that is given a QueueCommands configured for the token it acts on
+//
+// Method expressions such as QueueCommands.Join satisfy this type.
+type TokenCommand func(c QueueCommands, q queue.Queue, channel string, user string, args string) (queue.Queue, Notification)
+
+var validTokenName = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// forToken returns a copy of the commands configured for the named token and the number of items that can hold it
+func (c QueueCommands) forToken(name string, capacity int) QueueCommands {
+	c.token = name
+	c.capacity = capacity
+	c.response.token = name
+	c.response.capacity = capacity
+	return c
+}
+
+// parseToken splits a token name from the front of the arguments, or takes it from the `--token` option
+//
+// The first argument is only treated as a token name if it is not quoted and a named token by that name exists,
+// otherwise the default token is used and the arguments are left untouched. ok is false if the option is given
+// without a name or names a token that does not exist.
+func (c QueueCommands) parseToken(t queue.Tokens, args string) (name string, remainder string, ok bool) {
+	a := parseArgs(args, "token")
+	if value, given := a.option("token"); given {
+		name = strings.ToLower(value)
+		return name, a.rest(), name == queue.DefaultToken || t.Exists(name)
+	}
+
+	if a.empty() || a.words[0].quoted {
+		return queue.DefaultToken, args, true
+	}
+
+	name, rest := a.shift()
+	name = strings.ToLower(name)
+	if name == queue.DefaultToken || !t.Exists(name) {
+		return queue.DefaultToken, args, true
+	}
+	return name, rest.rest(), true
+}
+
+// Named runs a command against the token named by the first argument, or the default token if none is named
+func (c QueueCommands) Named(cmd TokenCommand) ChannelCommand {
+	return func(t queue.Tokens, ch, id, args string) (queue.Tokens, Notification) {
+		name, args, ok := c.parseToken(t, args)
+		if !ok {
+			return t, Notification{ch, c.forChannel(ch).response.TokenNotFound(id, name)}
+		}
+		q, n := cmd(c.forChannel(ch).forToken(name, t.Capacity(name)), t.Get(name), ch, id, args)
+		return t.Set(name, q), n
+	}
+}
+
+// parseCapacity reads the number of people who can hold a token at once
+func (c QueueCommands) parseCapacity(args string) (capacity int, ok bool) {
+	capacity, err := strconv.Atoi(strings.TrimSpace(args))
+	return capacity, err == nil && capacity > 0
+}
+
+// Create adds a new named token to the channel, optionally with the number of people who can hold it at once
+func (c QueueCommands) Create(t queue.Tokens, ch, id, args string) (queue.Tokens, Notification) {
+	c = c.forChannel(ch)
+	name, a := parseArgs(args).shift()
+	name = strings.ToLower(name)
+
+	if name == "" {
+		return t, Notification{ch, c.response.TokenNoName(id)}
+	}
+
+	if t.Exists(name) {
+		return t, Notification{ch, c.response.TokenExists(id, name)}
+	}
+
+	if !validTokenName.MatchString(name) {
+		return t, Notification{ch, c.response.TokenBadName(id, name)}
+	}
+
+	capacity := 1
+	if !a.empty() {
+		var ok bool
+		capacity, ok = c.parseCapacity(a.rest())
+		if !ok {
+			return t, Notification{ch, c.response.TokenBadCapacity(id, a.rest())}
+		}
+	}
+
+	t = t.Add(name).SetCapacity(name, capacity)
+	c.logActivity(id, name, "created token")
+	return t, Notification{ch, c.response.TokenCreated(name, capacity)}
+}
+
+// Delete removes a named token from the channel once nobody is queued for it
+func (c QueueCommands) Delete(t queue.Tokens, ch, id, args string) (queue.Tokens, Notification) {
+	c = c.forChannel(ch)
+	name := strings.ToLower(parseArgs(args).first())
+
+	if name == "" {
+		return t, Notification{ch, c.response.TokenNoName(id)}
+	}
+
+	if name == queue.DefaultToken {
+		return t, Notification{ch, c.response.TokenDeleteDefault(id)}
+	}
+
+	if !t.Exists(name) {
+		return t, Notification{ch, c.response.TokenNotFound(id, name)}
+	}
+
+	if len(t.Get(name)) > 0 {
+		return t, Notification{ch, c.response.TokenInUse(id, name)}
+	}
+
+	t = t.Remove(name)
+	c.logActivity(id, name, "deleted token")
+	return t, Notification{ch, c.response.TokenDeleted(name)}
+}
+
+// Capacity changes the number of people who can hold a token at once
+//
+// The capacity cannot be reduced below the number of people who currently hold the token.
+func (c QueueCommands) Capacity(t queue.Tokens, ch, id, args string) (queue.Tokens, Notification) {
+	c = c.forChannel(ch)
+	name, args, ok := c.parseToken(t, args)
+	if !ok {
+		return t, Notification{ch, c.response.TokenNotFound(id, name)}
+	}
+	c = c.forToken(name, t.Capacity(name))
+
+	capacity, ok := c.parseCapacity(args)
+	if !ok {
+		return t, Notification{ch, c.response.TokenBadCapacity(id, args)}
+	}
+
+	q := t.Get(name)
+	holders := len(q.Holders(c.capacity))
+	if capacity < holders {
+		return t, Notification{ch, c.response.TokenCapacityInUse(id, holders)}
+	}
+
+	promoted := []queue.Item{}
+	for _, i := range q.Holders(capacity) {
+		if !q.Holds(i, c.capacity) {
+			promoted = append(promoted, i)
+		}
+	}
+
+	t = t.SetCapacity(name, capacity)
+	c = c.forToken(name, capacity)
+	c.logActivity(id, name, fmt.Sprintf("set capacity to %d", capacity))
+	c.logPromoted(promoted)
+	return t, Notification{ch, c.response.TokenCapacity(promoted)}
+}
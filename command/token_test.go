@@ -0,0 +1,248 @@
+package command_test
+
+import (
+	"testing"
+
+	"github.com/doozr/qbot/command"
+	"github.com/doozr/qbot/queue"
+)
+
+type TokenTest struct {
+	test             string
+	startTokens      queue.Tokens
+	channel          string
+	user             string
+	args             string
+	expectedTokens   queue.Tokens
+	expectedResponse string
+}
+
+func testChannelCommand(t *testing.T, fn command.ChannelCommand, tests []TokenTest) {
+	for _, tt := range tests {
+		tokens, r := fn(tt.startTokens, tt.channel, tt.user, tt.args)
+		if !tokens.Equal(tt.expectedTokens) {
+			t.Errorf("%s: expected tokens '%v', got '%v'", tt.test, tt.expectedTokens, tokens)
+		}
+		assertResponse(t, tt.test, tt.channel, tt.expectedResponse, r)
+	}
+}
+
+func TestNamed(t *testing.T) {
+	cmd := command.New(id, name, userCache)
+	testChannelCommand(t, cmd.Named(command.QueueCommands.Join), []TokenTest{
+		{
+			test:             "join default token when no token is named",
+			startTokens:      queue.Tokens{"staging": {Queue: queue.Queue{}}},
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "Banana",
+			expectedTokens:   queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{{ID: "U123", Reason: "Banana"}}}, "staging": {Queue: queue.Queue{}}},
+			expectedResponse: "*<@U123|craig> (Banana) now has the token*",
+		},
+		{
+			test:             "join named token",
+			startTokens:      queue.Tokens{"staging": {Queue: queue.Queue{{ID: "U456", Reason: "Already here"}}}},
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "Staging Banana",
+			expectedTokens:   queue.Tokens{"staging": {Queue: queue.Queue{{ID: "U456", Reason: "Already here"}, {ID: "U123", Reason: "Banana"}}}},
+			expectedResponse: "<@U123|craig> (Banana) is now next in line for the `staging` token",
+		},
+		{
+			test:             "treat unknown token name as part of the reason",
+			startTokens:      queue.Tokens{"staging": {Queue: queue.Queue{}}},
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "perf Banana",
+			expectedTokens:   queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{{ID: "U123", Reason: "perf Banana"}}}, "staging": {Queue: queue.Queue{}}},
+			expectedResponse: "*<@U123|craig> (perf Banana) now has the token*",
+		},
+		{
+			test:             "do not treat default as a token name",
+			startTokens:      queue.Tokens{},
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "default Banana",
+			expectedTokens:   queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{{ID: "U123", Reason: "default Banana"}}}},
+			expectedResponse: "*<@U123|craig> (default Banana) now has the token*",
+		},
+	})
+
+	testChannelCommand(t, cmd.Named(command.QueueCommands.Done), []TokenTest{
+		{
+			test: "release named token",
+			startTokens: queue.Tokens{
+				queue.DefaultToken: {Queue: queue.Queue{{ID: "U123", Reason: "Default"}}},
+				"staging":          {Queue: queue.Queue{{ID: "U123", Reason: "Banana"}}}},
+			channel: "C1A2B3C",
+			user:    "U123",
+			args:    "staging",
+			expectedTokens: queue.Tokens{
+				queue.DefaultToken: {Queue: queue.Queue{{ID: "U123", Reason: "Default"}}},
+				"staging":          {Queue: queue.Queue{}}},
+			expectedResponse: "<@U123|craig> (Banana) has finished with the `staging` token\nThe `staging` token is up for grabs",
+		},
+	})
+}
+
+func TestCreate(t *testing.T) {
+	cmd := command.New(id, name, userCache)
+	testChannelCommand(t, cmd.Create, []TokenTest{
+		{
+			test:             "create named token",
+			startTokens:      queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{{ID: "U123", Reason: "Banana"}}}},
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "Staging",
+			expectedTokens:   queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{{ID: "U123", Reason: "Banana"}}}, "staging": {Queue: queue.Queue{}}},
+			expectedResponse: "Created the `staging` token, use `join staging <reason>` to queue for it",
+		},
+		{
+			test:             "refuse to create existing token",
+			startTokens:      queue.Tokens{"staging": {Queue: queue.Queue{}}},
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "staging",
+			expectedTokens:   queue.Tokens{"staging": {Queue: queue.Queue{}}},
+			expectedResponse: "<@U123|craig> The `staging` token already exists",
+		},
+		{
+			test:             "refuse to create default token",
+			startTokens:      queue.Tokens{},
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "default",
+			expectedTokens:   queue.Tokens{},
+			expectedResponse: "<@U123|craig> The `default` token already exists",
+		},
+		{
+			test:             "refuse numeric token name",
+			startTokens:      queue.Tokens{},
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "2",
+			expectedTokens:   queue.Tokens{},
+			expectedResponse: "<@U123|craig> `2` cannot be used as a token name, use a word starting with a letter",
+		},
+		{
+			test:             "refuse missing token name",
+			startTokens:      queue.Tokens{},
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "",
+			expectedTokens:   queue.Tokens{},
+			expectedResponse: "<@U123|craig> You must provide a token name",
+		},
+		{
+			test:             "create token that several people can hold",
+			startTokens:      queue.Tokens{},
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "envs 3",
+			expectedTokens:   queue.Tokens{"envs": {Capacity: 3, Queue: queue.Queue{}}},
+			expectedResponse: "Created the `envs` token for up to 3 people at once, use `join envs <reason>` to queue for it",
+		},
+		{
+			test:             "refuse bad number of holders",
+			startTokens:      queue.Tokens{},
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "envs 0",
+			expectedTokens:   queue.Tokens{},
+			expectedResponse: "<@U123|craig> `0` is not a valid number of people to hold a token at once",
+		},
+	})
+}
+
+func TestCapacity(t *testing.T) {
+	cmd := command.New(id, name, userCache)
+	testChannelCommand(t, cmd.Capacity, []TokenTest{
+		{
+			test:             "raise capacity and hand out free places",
+			startTokens:      queue.Tokens{"envs": {Queue: queue.Queue{{ID: "U123", Reason: "Banana"}, {ID: "U456", Reason: "Apple"}, {ID: "U789", Reason: "Pear"}}}},
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "envs 2",
+			expectedTokens:   queue.Tokens{"envs": {Capacity: 2, Queue: queue.Queue{{ID: "U123", Reason: "Banana"}, {ID: "U456", Reason: "Apple"}, {ID: "U789", Reason: "Pear"}}}},
+			expectedResponse: "The `envs` token can now be held by up to 2 people at once\n*<@U456|edward> (Apple) now has the `envs` token*",
+		},
+		{
+			test:             "change capacity of default token",
+			startTokens:      queue.Tokens{},
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "2",
+			expectedTokens:   queue.Tokens{queue.DefaultToken: {Capacity: 2, Queue: queue.Queue{}}},
+			expectedResponse: "The token can now be held by up to 2 people at once",
+		},
+		{
+			test:             "lower capacity back to one",
+			startTokens:      queue.Tokens{"envs": {Capacity: 2, Queue: queue.Queue{{ID: "U123", Reason: "Banana"}}}},
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "envs 1",
+			expectedTokens:   queue.Tokens{"envs": {Queue: queue.Queue{{ID: "U123", Reason: "Banana"}}}},
+			expectedResponse: "The `envs` token can now be held by one person at a time",
+		},
+		{
+			test:             "refuse to lower capacity below the number of holders",
+			startTokens:      queue.Tokens{"envs": {Capacity: 3, Queue: queue.Queue{{ID: "U123", Reason: "Banana"}, {ID: "U456", Reason: "Apple"}}}},
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "envs 1",
+			expectedTokens:   queue.Tokens{"envs": {Capacity: 3, Queue: queue.Queue{{ID: "U123", Reason: "Banana"}, {ID: "U456", Reason: "Apple"}}}},
+			expectedResponse: "<@U123|craig> 2 people hold the `envs` token right now, so it cannot be held by fewer",
+		},
+		{
+			test:             "refuse missing capacity",
+			startTokens:      queue.Tokens{"envs": {Queue: queue.Queue{}}},
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "envs",
+			expectedTokens:   queue.Tokens{"envs": {Queue: queue.Queue{}}},
+			expectedResponse: "<@U123|craig> You must say how many people can hold the `envs` token at once",
+		},
+	})
+}
+
+func TestDelete(t *testing.T) {
+	cmd := command.New(id, name, userCache)
+	testChannelCommand(t, cmd.Delete, []TokenTest{
+		{
+			test:             "delete unused token",
+			startTokens:      queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{{ID: "U123", Reason: "Banana"}}}, "staging": {Queue: queue.Queue{}}},
+			channel:          "C1A2B3C",
+			user:             "U123",
+			args:             "staging",
+			expectedTokens:   queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{{ID: "U123", Reason: "Banana"}}}},
+			expectedResponse: "Deleted the `staging` token",
+		},
+		{
+			test:             "refuse to delete token in use",
+			startTokens:      queue.Tokens{"staging": {Queue: queue.Queue{{ID: "U123", Reason: "Banana"}}}},
+			channel:          "C1A2B3C",
+			user:             "U456",
+			args:             "staging",
+			expectedTokens:   queue.Tokens{"staging": {Queue: queue.Queue{{ID: "U123", Reason: "Banana"}}}},
+			expectedResponse: "<@U456|edward> The `staging` token cannot be deleted while anybody is queued for it",
+		},
+		{
+			test:             "refuse to delete unknown token",
+			startTokens:      queue.Tokens{},
+			channel:          "C1A2B3C",
+			user:             "U456",
+			args:             "staging",
+			expectedTokens:   queue.Tokens{},
+			expectedResponse: "<@U456|edward> There is no `staging` token",
+		},
+		{
+			test:             "refuse to delete default token",
+			startTokens:      queue.Tokens{},
+			channel:          "C1A2B3C",
+			user:             "U456",
+			args:             "default",
+			expectedTokens:   queue.Tokens{},
+			expectedResponse: "<@U456|edward> The `default` token cannot be deleted",
+		},
+	})
+}
@@ -0,0 +1,94 @@
+package command
+
+import (
+	"strings"
+
+	"github.com/doozr/qbot/history"
+	"github.com/doozr/qbot/queue"
+)
+
+// lastUndoable finds the most recent change that the user may undo
+//
+// Users may only undo their own changes, but admins of the channel may undo anybody's.
+func (c QueueCommands) lastUndoable(changes []history.Change, ch, id string) (ix int, ok bool) {
+	for ix = len(changes) - 1; ix >= 0; ix-- {
+		if !changes[ix].Undone && (changes[ix].Actor == id || c.isAdmin(ch, id)) {
+			return ix, true
+		}
+	}
+	return
+}
+
+// changedSince finds who else has changed any of the named tokens since a change was made
+func (c QueueCommands) changedSince(changes []history.Change, names []string, id string) []string {
+	actors := []string{}
+	seen := map[string]bool{}
+	for _, change := range changes {
+		if change.Undone || change.Actor == id || seen[change.Actor] || !change.Touches(names) {
+			continue
+		}
+		seen[change.Actor] = true
+		actors = append(actors, change.Actor)
+	}
+	return actors
+}
+
+// restored lists the tokens as they are once a change is undone
+func (c QueueCommands) restored(t queue.Tokens, names []string) string {
+	sections := []string{}
+	for _, name := range names {
+		if !t.Exists(name) {
+			sections = append(sections, c.forToken(name, 1).response.UndoTokenGone())
+			continue
+		}
+
+		s := c.listToken(t, name)
+		if len(names) > 1 || name != queue.DefaultToken {
+			s = c.forToken(name, t.Capacity(name)).response.ListHeading() + "\n" + s
+		}
+		sections = append(sections, s)
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+// Undo puts the tokens back as they were before the last change the user made
+//
+// The change is refused if anything it touched has been changed again since, as undoing it would throw away the later
+// change too.
+func (c QueueCommands) Undo(t queue.Tokens, ch, id, args string) (queue.Tokens, Notification) {
+	c = c.forChannel(ch)
+	if c.history == nil {
+		return t, Notification{ch, c.response.UndoNothing(id)}
+	}
+
+	changes := c.history.Changes(ch)
+	ix, ok := c.lastUndoable(changes, ch, id)
+	if !ok {
+		return t, Notification{ch, c.response.UndoNothing(id)}
+	}
+
+	change := changes[ix]
+	names := change.Changed()
+	for _, name := range names {
+		if !history.Only(t, name).Equal(history.Only(change.After, name)) {
+			actors := c.changedSince(changes[ix+1:], names, id)
+			return t, Notification{ch, c.response.UndoConflict(id, change, actors)}
+		}
+	}
+
+	nt := queue.Tokens{}
+	for name, tok := range t {
+		nt[name] = tok
+	}
+	for _, name := range names {
+		if tok, ok := change.Before[name]; ok {
+			nt[name] = tok
+		} else {
+			delete(nt, name)
+		}
+	}
+
+	c.history.MarkUndone(ch, change.ID)
+	c.logActivity(change.Actor, change.Command, "undone by "+c.getNameIDPair(id))
+	return nt, Notification{ch, c.response.Undo(id, change, c.restored(nt, names))}
+}
@@ -0,0 +1,185 @@
+package command_test
+
+import (
+	"testing"
+
+	"github.com/doozr/qbot/command"
+	"github.com/doozr/qbot/history"
+	"github.com/doozr/qbot/queue"
+)
+
+type UndoTest struct {
+	test             string
+	changes          []history.Change
+	startTokens      queue.Tokens
+	user             string
+	expectedTokens   queue.Tokens
+	expectedResponse string
+}
+
+func testUndo(t *testing.T, tests []UndoTest) {
+	for _, tt := range tests {
+		h := history.New(10)
+		for _, change := range tt.changes {
+			h.Record("C1A2B3C", change)
+		}
+
+		cmd := command.New(id, name, userCache).WithAdmins([]string{"U789"}).WithHistory(h)
+		testChannelCommand(t, cmd.Undo, []TokenTest{{
+			test:             tt.test,
+			startTokens:      tt.startTokens,
+			channel:          "C1A2B3C",
+			user:             tt.user,
+			expectedTokens:   tt.expectedTokens,
+			expectedResponse: tt.expectedResponse,
+		}})
+	}
+}
+
+func TestUndo(t *testing.T) {
+	craig := queue.Item{ID: "U123", Reason: "Banana"}
+	edward := queue.Item{ID: "U456", Reason: "Potato"}
+	andrew := queue.Item{ID: "U789", Reason: "Tomato"}
+
+	both := queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{craig, edward}}}
+	craigOnly := queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{craig}}}
+	edwardOnly := queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{edward}}}
+	all := queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{craig, edward, andrew}}}
+	ousted := queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{edward, craig}}}
+
+	testUndo(t, []UndoTest{
+		{
+			test:             "refuse if user has made no changes",
+			changes:          []history.Change{{Actor: "U456", Command: "join Potato", Before: craigOnly, After: both}},
+			startTokens:      both,
+			user:             "U123",
+			expectedTokens:   both,
+			expectedResponse: "<@U123|craig> You have nothing to undo",
+		},
+		{
+			test:             "restore queue before the user's last change",
+			changes:          []history.Change{{Actor: "U123", Command: "boot 2 edward", Before: both, After: craigOnly}},
+			startTokens:      craigOnly,
+			user:             "U123",
+			expectedTokens:   both,
+			expectedResponse: "<@U123|craig> undid `boot 2 edward`, which leaves:\n*1: craig (Banana) has the token*\n2: edward (Potato)",
+		},
+		{
+			test: "undo own change when later changes by others touch other tokens",
+			changes: []history.Change{
+				{Actor: "U123", Command: "done", Before: both, After: edwardOnly},
+				{Actor: "U456", Command: "create staging", Before: edwardOnly, After: edwardOnly.Add("staging")},
+			},
+			startTokens:      edwardOnly.Add("staging"),
+			user:             "U123",
+			expectedTokens:   both.Add("staging"),
+			expectedResponse: "<@U123|craig> undid `done`, which leaves:\n*1: craig (Banana) has the token*\n2: edward (Potato)",
+		},
+		{
+			test: "refuse when a later change by somebody else conflicts",
+			changes: []history.Change{
+				{Actor: "U123", Command: "join Banana", Before: queue.Tokens{}, After: craigOnly},
+				{Actor: "U456", Command: "join Potato", Before: craigOnly, After: both},
+			},
+			startTokens:      both,
+			user:             "U123",
+			expectedTokens:   both,
+			expectedResponse: "<@U123|craig> Can't undo `join Banana` because the queue has changed since (by <@U456|edward>)",
+		},
+		{
+			test: "refuse when a later change by the bot itself conflicts",
+			changes: []history.Change{
+				{Actor: "U456", Command: "join Potato", Before: craigOnly, After: both},
+				{Actor: id, Command: "hold", Before: both, After: ousted},
+			},
+			startTokens:      ousted,
+			user:             "U456",
+			expectedTokens:   ousted,
+			expectedResponse: "<@U456|edward> Can't undo `join Potato` because the queue has changed since (by <@U12345|the_bot_name>)",
+		},
+		{
+			test:             "refuse when the queue has changed without a recorded change",
+			changes:          []history.Change{{Actor: "U123", Command: "join Banana", Before: queue.Tokens{}, After: craigOnly}},
+			startTokens:      queue.Tokens{},
+			user:             "U123",
+			expectedTokens:   queue.Tokens{},
+			expectedResponse: "<@U123|craig> Can't undo `join Banana` because the queue has changed since",
+		},
+		{
+			test: "skip changes that have already been undone",
+			changes: []history.Change{
+				{Actor: "U123", Command: "join Banana", Before: queue.Tokens{}, After: craigOnly},
+				{Actor: "U123", Command: "leave", Before: craigOnly, After: queue.Tokens{}, Undone: true},
+			},
+			startTokens:      craigOnly,
+			user:             "U123",
+			expectedTokens:   queue.Tokens{},
+			expectedResponse: "<@U123|craig> undid `join Banana`, which leaves:\n" + "Nobody has the token, and nobody is waiting",
+		},
+		{
+			test:             "let admins undo changes made by anybody",
+			changes:          []history.Change{{Actor: "U456", Command: "join Potato", Before: craigOnly, After: both}},
+			startTokens:      both,
+			user:             "U789",
+			expectedTokens:   craigOnly,
+			expectedResponse: "<@U789|andrew> undid `join Potato` by <@U456|edward>, which leaves:\n*1: craig (Banana) has the token*",
+		},
+		{
+			test:             "do not let non-admins undo changes made by others",
+			changes:          []history.Change{{Actor: "U789", Command: "join Tomato", Before: both, After: all}},
+			startTokens:      all,
+			user:             "U456",
+			expectedTokens:   all,
+			expectedResponse: "<@U456|edward> You have nothing to undo",
+		},
+		{
+			test: "restore a deleted token",
+			changes: []history.Change{{
+				Actor: "U123", Command: "delete staging",
+				Before: queue.Tokens{}.Add("staging").SetCapacity("staging", 2),
+				After:  queue.Tokens{}}},
+			startTokens:      queue.Tokens{},
+			user:             "U123",
+			expectedTokens:   queue.Tokens{}.Add("staging").SetCapacity("staging", 2),
+			expectedResponse: "<@U123|craig> undid `delete staging`, which leaves:\n`staging` (up to 2 at once)\nNobody has the token, and nobody is waiting",
+		},
+		{
+			test: "remove a created token",
+			changes: []history.Change{{
+				Actor: "U123", Command: "create staging",
+				Before: queue.Tokens{},
+				After:  queue.Tokens{}.Add("staging")}},
+			startTokens:      queue.Tokens{}.Add("staging"),
+			user:             "U123",
+			expectedTokens:   queue.Tokens{},
+			expectedResponse: "<@U123|craig> undid `create staging`, which leaves:\nThe `staging` token no longer exists",
+		},
+	})
+}
+
+func TestUndoWithoutHistory(t *testing.T) {
+	cmd := command.New(id, name, userCache)
+	testChannelCommand(t, cmd.Undo, []TokenTest{{
+		test:             "refuse when no history is kept",
+		startTokens:      queue.Tokens{},
+		channel:          "C1A2B3C",
+		user:             "U123",
+		expectedTokens:   queue.Tokens{},
+		expectedResponse: "<@U123|craig> You have nothing to undo",
+	}})
+}
+
+func TestUndoMarksChangeUndone(t *testing.T) {
+	craigOnly := queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{{ID: "U123", Reason: "Banana"}}}}
+	h := history.New(10)
+	h.Record("C1A2B3C", history.Change{Actor: "U123", Command: "join Banana", Before: queue.Tokens{}, After: craigOnly})
+
+	cmd := command.New(id, name, userCache).WithHistory(h)
+	tokens, _ := cmd.Undo(craigOnly, "C1A2B3C", "U123", "")
+	tokens, r := cmd.Undo(tokens, "C1A2B3C", "U123", "")
+
+	if !h.Changes("C1A2B3C")[0].Undone {
+		t.Error("expected change to be marked undone")
+	}
+	assertResponse(t, "undo twice", "C1A2B3C", "<@U123|craig> You have nothing to undo", r)
+}
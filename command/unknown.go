@@ -0,0 +1,7 @@
+package command
+
+// Unknown tells a user that the bot does not know a command, suggesting a known command that they might have meant
+func (c QueueCommands) Unknown(ch, id, cmd, suggestion string) Notification {
+	c = c.forChannel(ch)
+	return Notification{ch, c.response.Unknown(id, cmd, suggestion)}
+}
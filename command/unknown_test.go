@@ -0,0 +1,17 @@
+package command_test
+
+import (
+	"testing"
+
+	. "github.com/doozr/qbot/command"
+)
+
+func TestUnknown(t *testing.T) {
+	cmd := New(id, name, userCache)
+
+	r := cmd.Unknown("C1234", "U123", "dne", "done")
+	assertResponse(t, "suggestion", "C1234", "<@U123|craig> I don't know `dne`, did you mean `done`? Say `yes` to run it", r)
+
+	r = cmd.Unknown("C1234", "U123", "xyzzy", "")
+	assertResponse(t, "no suggestion", "C1234", "<@U123|craig> I don't know `xyzzy`; say `help` to see what I can do", r)
+}
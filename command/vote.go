@@ -0,0 +1,93 @@
+package command
+
+import "github.com/doozr/qbot/queue"
+
+// isWaiting checks if the user has an entry waiting for the token
+func (c QueueCommands) isWaiting(q queue.Queue, id string) bool {
+	for _, i := range q.WaitingBehind(c.capacity) {
+		if i.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// currentVotes finds the votes to oust the holders of a token that still count
+//
+// Votes stop counting once the window has passed, once the entry voted against no longer holds the token or the voter
+// is no longer waiting for it, and once the holder has said anything in the channel.
+func (c QueueCommands) currentVotes(t queue.Tokens, ch, name string) queue.Votes {
+	q := t.Get(name)
+	votes := queue.Votes{}
+	for _, v := range t.Votes(name).Since(c.clock().Add(-c.window)) {
+		if !q.Holds(v.Item, c.capacity) || !c.isWaiting(q, v.ID) {
+			continue
+		}
+		if c.activity != nil && !v.At.After(c.activity.LastSeen(ch, v.Item.ID)) {
+			continue
+		}
+		votes = append(votes, v)
+	}
+	return votes
+}
+
+// voters lists who cast the votes
+func voters(votes queue.Votes) []string {
+	ids := []string{}
+	for _, v := range votes {
+		ids = append(ids, v.ID)
+	}
+	return ids
+}
+
+// VoteOust counts a vote to oust a token holder, and ousts them once enough people waiting for the token have voted
+//
+// Without votes set up the holder is ousted straight away, as with Oust, and so they are when an admin votes.
+func (c QueueCommands) VoteOust(t queue.Tokens, ch, id, args string) (queue.Tokens, Notification) {
+	c = c.forChannel(ch)
+	if c.votes < 2 || c.isAdmin(ch, id) {
+		return c.Named(QueueCommands.Oust)(t, ch, id, args)
+	}
+
+	name, args, ok := c.parseToken(t, args)
+	if !ok {
+		return t, Notification{ch, c.response.TokenNotFound(id, name)}
+	}
+	c = c.forToken(name, t.Capacity(name))
+	q := t.Get(name)
+	if len(q) == 0 {
+		return t, Notification{ch, ""}
+	}
+
+	i, response, ok := c.findOustTarget(q, id, args)
+	if !ok {
+		return t, Notification{ch, response}
+	}
+
+	if !c.isWaiting(q, id) {
+		return t, Notification{ch, c.response.OustVoteNotWaiting(id)}
+	}
+
+	votes := c.currentVotes(t, ch, name).Add(queue.Vote{ID: id, Item: i, At: c.clock()})
+	cast := votes.For(i)
+	if len(cast) < c.votes {
+		c.logActivity(i.ID, i.Reason, "got a vote to oust from "+c.getNameIDPair(id))
+		return t.SetVotes(name, votes), Notification{ch, c.response.OustVote(id, i, len(cast), c.votes, c.window)}
+	}
+
+	c.logActivity(i.ID, i.Reason, "ousted by vote")
+	nq := q.YieldHolder(i, c.capacity)
+	p := c.promoted(q, nq)
+	c.logPromoted(p)
+	return t.Set(name, nq).SetVotes(name, votes.Clear(i)), Notification{ch, c.response.OustVoted(i, voters(cast), p)}
+}
+
+// OustVoteStarted sends a direct message to a token holder when the first vote to oust them is cast
+func (c QueueCommands) OustVoteStarted(ch, name string, before, after queue.Tokens, i queue.Item) Notification {
+	c = c.forChannel(ch)
+	c = c.forToken(name, after.Capacity(name))
+	if len(c.currentVotes(before, ch, name).For(i)) > 0 || len(c.currentVotes(after, ch, name).For(i)) == 0 {
+		return Notification{i.ID, ""}
+	}
+	return Notification{i.ID, c.response.NotifyOustVote(ch, i, c.votes, c.window)}
+}
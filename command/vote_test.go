@@ -0,0 +1,170 @@
+package command_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/doozr/qbot/activity"
+	"github.com/doozr/qbot/command"
+	"github.com/doozr/qbot/queue"
+)
+
+var (
+	voteCraig  = queue.Item{ID: "U123", Reason: "Active", Key: "aaa"}
+	voteEdward = queue.Item{ID: "U456", Reason: "First", Key: "bbb"}
+	voteAndrew = queue.Item{ID: "U789", Reason: "Last", Key: "ccc"}
+)
+
+var voteNow = time.Date(2017, 3, 14, 12, 0, 0, 0, time.UTC)
+
+func voteTokens(q queue.Queue, vs ...queue.Vote) queue.Tokens {
+	return queue.Tokens{queue.DefaultToken: {Queue: q, Votes: vs}}
+}
+
+func TestVoteOust(t *testing.T) {
+	seen := activity.New()
+	seen.Seen("C1A2B3C", "U123", voteNow.Add(-time.Minute))
+	cmd := command.New(id, name, userCache).
+		WithClock(func() time.Time { return voteNow }).
+		WithOustVotes(2, 10*time.Minute).
+		WithActivity(seen)
+
+	start := queue.Queue{voteCraig, voteEdward, voteAndrew}
+	andrewVote := queue.Vote{ID: "U789", Item: voteCraig, At: voteNow}
+	edwardVote := queue.Vote{ID: "U456", Item: voteCraig, At: voteNow}
+
+	testChannelCommand(t, cmd.VoteOust, []TokenTest{
+		{
+			test:             "count the first vote",
+			startTokens:      voteTokens(start),
+			channel:          "C1A2B3C",
+			user:             "U789",
+			args:             "craig",
+			expectedTokens:   voteTokens(start, andrewVote),
+			expectedResponse: "<@U789|andrew> voted to oust <@U123|craig> (Active) (1 of 2 votes needed within 10m)",
+		},
+		{
+			test:           "oust once enough people have voted",
+			startTokens:    voteTokens(start, andrewVote),
+			channel:        "C1A2B3C",
+			user:           "U456",
+			args:           "#aaa",
+			expectedTokens: voteTokens(queue.Queue{voteEdward, voteCraig, voteAndrew}),
+			expectedResponse: "<@U123|craig> (Active) was voted out by <@U789|andrew> and <@U456|edward>\n" +
+				"*<@U456|edward> (First) now has the token*",
+		},
+		{
+			test:             "only count each person once",
+			startTokens:      voteTokens(start, andrewVote),
+			channel:          "C1A2B3C",
+			user:             "U789",
+			args:             "craig",
+			expectedTokens:   voteTokens(start, andrewVote),
+			expectedResponse: "<@U789|andrew> voted to oust <@U123|craig> (Active) (1 of 2 votes needed within 10m)",
+		},
+		{
+			test:             "drop votes from before the window",
+			startTokens:      voteTokens(start, queue.Vote{ID: "U789", Item: voteCraig, At: voteNow.Add(-11 * time.Minute)}),
+			channel:          "C1A2B3C",
+			user:             "U456",
+			args:             "craig",
+			expectedTokens:   voteTokens(start, edwardVote),
+			expectedResponse: "<@U456|edward> voted to oust <@U123|craig> (Active) (1 of 2 votes needed within 10m)",
+		},
+		{
+			test:             "drop votes from before the holder last spoke",
+			startTokens:      voteTokens(start, queue.Vote{ID: "U789", Item: voteCraig, At: voteNow.Add(-5 * time.Minute)}),
+			channel:          "C1A2B3C",
+			user:             "U456",
+			args:             "craig",
+			expectedTokens:   voteTokens(start, edwardVote),
+			expectedResponse: "<@U456|edward> voted to oust <@U123|craig> (Active) (1 of 2 votes needed within 10m)",
+		},
+		{
+			test:             "only let people waiting for the token vote",
+			startTokens:      voteTokens(start),
+			channel:          "C1A2B3C",
+			user:             "U12345",
+			args:             "craig",
+			expectedTokens:   voteTokens(start),
+			expectedResponse: "<@U12345|the_bot_name> Only people waiting for the token can vote to oust its holder",
+		},
+		{
+			test:             "only vote against the holder",
+			startTokens:      voteTokens(start),
+			channel:          "C1A2B3C",
+			user:             "U789",
+			args:             "edward",
+			expectedTokens:   voteTokens(start),
+			expectedResponse: "<@U789|andrew> You can only oust the token holder",
+		},
+	})
+}
+
+func TestVoteOustWithoutVotesOustsStraightAway(t *testing.T) {
+	cmd := command.New(id, name, userCache)
+	start := queue.Queue{voteCraig, voteEdward, voteAndrew}
+
+	testChannelCommand(t, cmd.VoteOust, []TokenTest{
+		{
+			test:           "oust straight away",
+			startTokens:    voteTokens(start),
+			channel:        "C1A2B3C",
+			user:           "U789",
+			args:           "craig",
+			expectedTokens: voteTokens(queue.Queue{voteEdward, voteCraig, voteAndrew}),
+			expectedResponse: "<@U789|andrew> ousted <@U123|craig> (Active)\n" +
+				"*<@U456|edward> (First) now has the token*",
+		},
+	})
+}
+
+func TestVoteOustByAdminOustsStraightAway(t *testing.T) {
+	cmd := command.New(id, name, userCache).
+		WithClock(func() time.Time { return voteNow }).
+		WithOustVotes(2, 10*time.Minute).
+		WithAdmins([]string{"U789"})
+	start := queue.Queue{voteCraig, voteEdward, voteAndrew}
+
+	testChannelCommand(t, cmd.VoteOust, []TokenTest{
+		{
+			test:           "oust straight away",
+			startTokens:    voteTokens(start),
+			channel:        "C1A2B3C",
+			user:           "U789",
+			args:           "craig",
+			expectedTokens: voteTokens(queue.Queue{voteEdward, voteCraig, voteAndrew}),
+			expectedResponse: "<@U789|andrew> ousted <@U123|craig> (Active)\n" +
+				"*<@U456|edward> (First) now has the token*",
+		},
+		{
+			test:             "count a vote from anybody else",
+			startTokens:      voteTokens(start),
+			channel:          "C1A2B3C",
+			user:             "U456",
+			args:             "craig",
+			expectedTokens:   voteTokens(start, queue.Vote{ID: "U456", Item: voteCraig, At: voteNow}),
+			expectedResponse: "<@U456|edward> voted to oust <@U123|craig> (Active) (1 of 2 votes needed within 10m)",
+		},
+	})
+}
+
+func TestOustVoteStarted(t *testing.T) {
+	cmd := command.New(id, name, userCache).
+		WithClock(func() time.Time { return voteNow }).
+		WithOustVotes(3, 10*time.Minute)
+
+	start := queue.Queue{voteCraig, voteEdward, voteAndrew}
+	andrewVote := queue.Vote{ID: "U789", Item: voteCraig, At: voteNow}
+	edwardVote := queue.Vote{ID: "U456", Item: voteCraig, At: voteNow}
+
+	r := cmd.OustVoteStarted("C1A2B3C", queue.DefaultToken, voteTokens(start), voteTokens(start, andrewVote), voteCraig)
+	assertResponse(t, "warn holder on first vote", "U123", "Somebody has voted to oust you from the token in <#C1A2B3C> "+
+		"(Active). Say something in <#C1A2B3C> if you still need it, otherwise you will be ousted if 3 people vote within 10m", r)
+
+	r = cmd.OustVoteStarted("C1A2B3C", queue.DefaultToken, voteTokens(start, andrewVote), voteTokens(start, andrewVote, edwardVote), voteCraig)
+	assertResponse(t, "do not warn again", "U123", "", r)
+
+	r = cmd.OustVoteStarted("C1A2B3C", queue.DefaultToken, voteTokens(start), voteTokens(start), voteCraig)
+	assertResponse(t, "no votes", "U123", "", r)
+}
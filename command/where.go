@@ -0,0 +1,114 @@
+package command
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/doozr/qbot/journal"
+	"github.com/doozr/qbot/queue"
+)
+
+// estimatePeriod is how far back hold times are looked at to estimate how long people will wait
+const estimatePeriod = 30 * 24 * time.Hour
+
+// usualHold is the median time a token in a channel was held for recently, or zero if nobody has held it
+//
+// Only the holds of that token are looked at, as different tokens in the same channel are often held for very
+// different lengths of time.
+func (c QueueCommands) usualHold(channel, name string) time.Duration {
+	if c.events == nil {
+		return 0
+	}
+
+	events := []journal.Event{}
+	for _, e := range c.events.Events() {
+		if e.Token == name {
+			events = append(events, e)
+		}
+	}
+	s := journal.Summarise(events, channel, c.clock().Add(-estimatePeriod))
+	return journal.Percentile(s.Holds, 50)
+}
+
+// estimate works out how long the entry at a position in a queue, counting from one, should wait for the token
+//
+// Each holder is expected to keep the token for the usual hold time, less however long they have had it so far, and
+// each entry ahead in the waiting list to keep it for the usual hold time after that. Reservations are not taken into
+// account.
+func estimate(q queue.Queue, capacity, position int, hold time.Duration, now time.Time) time.Duration {
+	free := []time.Duration{}
+	for _, i := range q.Holders(capacity) {
+		remaining := hold
+		if !i.ActiveSince.IsZero() {
+			remaining -= now.Sub(i.ActiveSince)
+		}
+		if remaining < 0 {
+			remaining = 0
+		}
+		free = append(free, remaining)
+	}
+
+	for ix := capacity + 1; ix < position; ix++ {
+		sort.Slice(free, func(a, b int) bool { return free[a] < free[b] })
+		free[0] += hold
+	}
+
+	sort.Slice(free, func(a, b int) bool { return free[a] < free[b] })
+	return free[0]
+}
+
+// where describes each of a user's entries in the tokens of a channel
+func (c QueueCommands) where(t queue.Tokens, channel, id string) []string {
+	lines := []string{}
+	now := c.clock()
+
+	for _, name := range t.Names() {
+		tc := c.forToken(name, t.Capacity(name))
+		q := t.Get(name)
+		hold := time.Duration(-1)
+		for ix, i := range q {
+			if i.ID != id {
+				continue
+			}
+
+			position := ix + 1
+			if position <= tc.capacity {
+				lines = append(lines, tc.response.WhereHolding(channel, i, now))
+				continue
+			}
+
+			if hold < 0 {
+				hold = c.usualHold(channel, name)
+			}
+
+			wait := time.Duration(-1)
+			if hold > 0 {
+				wait = estimate(q, tc.capacity, position, hold, now)
+			}
+			lines = append(lines, tc.response.WhereWaiting(channel, i, position, now, wait))
+		}
+	}
+	return lines
+}
+
+// Where tells a user where each of their entries is in every queue, how long they have waited and how much longer
+// they should have to wait
+func (c QueueCommands) Where(qs queue.Channels, ch, id, args string) Notification {
+	c = c.forChannel(ch)
+	channels := []string{}
+	for channel := range qs {
+		channels = append(channels, channel)
+	}
+	sort.Strings(channels)
+
+	lines := []string{}
+	for _, channel := range channels {
+		lines = append(lines, c.where(qs[channel], channel, id)...)
+	}
+
+	if len(lines) == 0 {
+		return Notification{ch, c.response.WhereNothing()}
+	}
+	return Notification{ch, strings.Join(lines, "\n")}
+}
@@ -0,0 +1,98 @@
+package command_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/doozr/qbot/command"
+	"github.com/doozr/qbot/journal"
+	"github.com/doozr/qbot/queue"
+)
+
+func TestWhere(t *testing.T) {
+	cmd := command.New(id, name, userCache).
+		WithClock(func() time.Time { return statsNow }).
+		WithEvents(journal.NewLog(statsEvents(), 31*24*time.Hour))
+
+	ago := func(d time.Duration) time.Time { return statsNow.Add(-d) }
+	qs := queue.Channels{
+		"C1A2B3C": queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{
+			{ID: "U789", Reason: "Tomato", Key: "jox", JoinedAt: ago(time.Hour), ActiveSince: ago(20 * time.Minute)},
+			{ID: "U123", Reason: "Banana", Key: "fp8", JoinedAt: ago(10 * time.Minute)},
+			{ID: "U456", Reason: "Potato", Key: "lqy", JoinedAt: ago(8 * time.Minute)},
+			{ID: "U123", Reason: "Kumquat", Key: "ecf", JoinedAt: ago(5 * time.Minute)},
+		}}},
+		"C4D5E6F": queue.Tokens{"staging": {Capacity: 2, Queue: queue.Queue{
+			{ID: "U123", Reason: "Release", Key: "a2y", JoinedAt: ago(time.Hour), ActiveSince: ago(15 * time.Minute)},
+		}}},
+		"C7G8H9I": queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{
+			{ID: "U456", Reason: "Potato", Key: "lqy", JoinedAt: ago(time.Hour), ActiveSince: ago(time.Hour)},
+			{ID: "U123", Reason: "Grape", Key: "b4z"},
+		}}},
+	}
+
+	r := cmd.Where(qs, "D1234", "U123", "")
+	assertResponse(t, "list entries with estimates", "D1234",
+		"You are 2nd in line for the token in <#C1A2B3C> (Banana) `#fp8`, have waited 10m and should get it in about 40m\n"+
+			"You are 4th in line for the token in <#C1A2B3C> (Kumquat) `#ecf`, have waited 5m and should get it in about 2h 40m\n"+
+			"*You have the `staging` token in <#C4D5E6F>* (Release) `#a2y` and have had it for 15m\n"+
+			"You are 2nd in line for the token in <#C7G8H9I> (Grape) `#b4z` but there is not enough history yet to say how much longer it will be", r)
+
+	r = cmd.Where(qs, "D1234", "U789", "")
+	assertResponse(t, "holder", "D1234",
+		"*You have the token in <#C1A2B3C>* (Tomato) `#jox` and have had it for 20m", r)
+
+	r = cmd.Where(queue.Channels{}, "D1234", "U123", "")
+	assertResponse(t, "not in any queue", "D1234", "You are not in any queues", r)
+}
+
+func TestWhereHolderOverrunning(t *testing.T) {
+	cmd := command.New(id, name, userCache).
+		WithClock(func() time.Time { return statsNow }).
+		WithEvents(journal.NewLog(statsEvents(), 31*24*time.Hour))
+
+	qs := queue.Channels{"C1A2B3C": queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{
+		{ID: "U789", Reason: "Tomato", Key: "jox", ActiveSince: statsNow.Add(-3 * time.Hour)},
+		{ID: "U123", Reason: "Banana", Key: "fp8"},
+	}}}}
+
+	r := cmd.Where(qs, "D1234", "U123", "")
+	assertResponse(t, "holder has had it longer than usual", "D1234",
+		"You are 2nd in line for the token in <#C1A2B3C> (Banana) `#fp8` and should get it any time now", r)
+}
+
+func TestWhereEstimatesFromHoldsOfTheSameToken(t *testing.T) {
+	start := statsNow.Add(-10 * time.Hour)
+	held := func(token, key string, from time.Time, hold time.Duration) []journal.Event {
+		i := queue.Item{ID: "U789", Reason: "Tomato", Key: key, JoinedAt: from, ActiveSince: from}
+		return []journal.Event{
+			{Time: from, Actor: "U789", Command: "join", Type: journal.EntryEvent, Channel: "C1A2B3C", Token: token,
+				Item: &i, Before: 0, After: 1},
+			{Time: from.Add(hold), Actor: "U789", Command: "done", Type: journal.EntryEvent, Channel: "C1A2B3C",
+				Token: token, Item: &i, Before: 1, After: 0},
+		}
+	}
+	events := append(held("staging", "jox", start, 10*time.Minute), held("prod", "lqy", start, 4*time.Hour)...)
+
+	cmd := command.New(id, name, userCache).
+		WithClock(func() time.Time { return statsNow }).
+		WithEvents(journal.NewLog(events, 31*24*time.Hour))
+
+	qs := queue.Channels{"C1A2B3C": queue.Tokens{
+		"staging": {Queue: queue.Queue{
+			{ID: "U456", Reason: "Potato", Key: "a2y", ActiveSince: statsNow},
+			{ID: "U123", Reason: "Banana", Key: "fp8", JoinedAt: statsNow},
+		}},
+		"prod": {Queue: queue.Queue{
+			{ID: "U456", Reason: "Potato", Key: "b4z", ActiveSince: statsNow},
+			{ID: "U123", Reason: "Kumquat", Key: "ecf", JoinedAt: statsNow},
+		}},
+	}}
+
+	r := cmd.Where(qs, "D1234", "U123", "")
+	assertResponse(t, "estimate each token from its own holds", "D1234",
+		"You are 2nd in line for the `prod` token in <#C1A2B3C> (Kumquat) `#ecf`, "+
+			"have waited less than a minute and should get it in about 4h\n"+
+			"You are 2nd in line for the `staging` token in <#C1A2B3C> (Banana) `#fp8`, "+
+			"have waited less than a minute and should get it in about 10m", r)
+}
@@ -2,21 +2,21 @@ package command
 
 import "github.com/doozr/qbot/queue"
 
-// Yield allows the second place ahead of the active user
+// Yield allows the first person waiting to take the user's place as a token holder
 func (c QueueCommands) Yield(q queue.Queue, ch, id, args string) (queue.Queue, Notification) {
 	if len(q) == 0 {
 		return q, Notification{ch, ""}
 	}
-	i := q.Active()
-	if i.ID != id {
+	i, ok := c.findHolder(q, id)
+	if !ok {
 		return q, Notification{ch, c.response.YieldNotActive(queue.Item{ID: id, Reason: ""})}
 	}
-	if len(q) < 2 {
+	if len(q) <= c.capacity {
 		return q, Notification{ch, c.response.YieldNoOthers(i)}
 	}
-	q = q.Yield()
-	n := q.Active()
+	nq := q.YieldHolder(i, c.capacity)
+	p := c.promoted(q, nq)
 	c.logActivity(id, i.Reason, "yielded")
-	c.logActivity(n.ID, n.Reason, "is active")
-	return q, Notification{ch, c.response.Yield(i, q)}
+	c.logPromoted(p)
+	return nq, Notification{ch, c.response.Yield(i, p)}
 }
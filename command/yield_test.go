@@ -44,3 +44,27 @@ func TestYield(t *testing.T) {
 		},
 	})
 }
+
+func TestYieldSharedToken(t *testing.T) {
+	cmd := command.New(id, name, userCache)
+	testChannelCommand(t, cmd.Named(command.QueueCommands.Yield), []TokenTest{
+		{
+			test:             "second holder swaps places with next in line",
+			startTokens:      queue.Tokens{"envs": {Capacity: 2, Queue: queue.Queue{{ID: "U123", Reason: "Banana"}, {ID: "U456", Reason: "Apple"}, {ID: "U789", Reason: "Next up"}}}},
+			channel:          "C1A2B3C",
+			user:             "U456",
+			args:             "envs",
+			expectedTokens:   queue.Tokens{"envs": {Capacity: 2, Queue: queue.Queue{{ID: "U123", Reason: "Banana"}, {ID: "U789", Reason: "Next up"}, {ID: "U456", Reason: "Apple"}}}},
+			expectedResponse: "<@U456|edward> (Apple) has yielded the `envs` token\n*<@U789|andrew> (Next up) now has the `envs` token*",
+		},
+		{
+			test:             "cannot yield if nobody is waiting",
+			startTokens:      queue.Tokens{"envs": {Capacity: 2, Queue: queue.Queue{{ID: "U123", Reason: "Banana"}, {ID: "U456", Reason: "Apple"}}}},
+			channel:          "C1A2B3C",
+			user:             "U456",
+			args:             "envs",
+			expectedTokens:   queue.Tokens{"envs": {Capacity: 2, Queue: queue.Queue{{ID: "U123", Reason: "Banana"}, {ID: "U456", Reason: "Apple"}}}},
+			expectedResponse: "<@U456|edward> You cannot yield if there is nobody waiting",
+		},
+	})
+}
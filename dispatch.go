@@ -32,10 +32,18 @@ func Dispatch(dispatcher Dispatcher, events guac.EventChan, done DoneChan, waitG
 // Dispatcher sends incoming messages to the correct recipient.
 type Dispatcher func(guac.EventChan, DoneChan) error
 
+// Job is work on the queues from outside Slack, such as a webhook, that the dispatcher does in turn with events.
+type Job func(queue.Channels) (queue.Channels, error)
+
 // CreateDispatcher creates a new Dispatcher instance.
-func CreateDispatcher(q queue.Queue, timeout time.Duration, handleMessage MessageHandler, handleUserChange UserChangeHandler) Dispatcher {
+//
+// Each time a tick arrives the tick handler is given the chance to act on the queues, and each job that arrives is done
+// on the queues. Neither counts as activity, so the dispatcher still times out if no events are received.
+func CreateDispatcher(qs queue.Channels, timeout time.Duration, handleMessage MessageHandler, handleUserChange UserChangeHandler,
+	ticks <-chan time.Time, handleTick TickHandler, jobs <-chan Job) Dispatcher {
 
 	return func(events guac.EventChan, done DoneChan) (err error) {
+		expired := time.After(timeout)
 		for {
 			jot.Print("dispatcher awaiting event")
 			select {
@@ -48,11 +56,12 @@ func CreateDispatcher(q queue.Queue, timeout time.Duration, handleMessage Messag
 					jot.Print("dispatcher: closing abort channel")
 					return
 				}
+				expired = time.After(timeout)
 
 				switch m := event.(type) {
 				case guac.MessageEvent:
 					jot.Print("dispatcher received message: ", m)
-					q, err = handleMessage(q, m)
+					qs, err = handleMessage(qs, m)
 
 				case guac.UserChangeEvent:
 					handleUserChange(m.UserInfo)
@@ -61,7 +70,15 @@ func CreateDispatcher(q queue.Queue, timeout time.Duration, handleMessage Messag
 					jot.Print("dispatcher: pong")
 				}
 
-			case <-time.After(timeout):
+			case now := <-ticks:
+				jot.Print("dispatcher: tick ", now)
+				qs, err = handleTick(qs, now)
+
+			case job := <-jobs:
+				jot.Print("dispatcher: job")
+				qs, err = job(qs)
+
+			case <-expired:
 				err = fmt.Errorf("No activity for %s - shutting down", timeout)
 			}
 
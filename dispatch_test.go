@@ -57,15 +57,15 @@ func testMessageDispatch(handleMessage MessageHandler, handleUserChange UserChan
 		close(done)
 	}()
 
-	dispatcher := CreateDispatcher(queue.Queue{}, 1*time.Millisecond, handleMessage, handleUserChange)
+	dispatcher := CreateDispatcher(queue.Channels{}, 1*time.Millisecond, handleMessage, handleUserChange, nil, nil, nil)
 	return dispatcher(events, done)
 }
 
 func TestDispatcherSendsMessagesToMessageHandler(t *testing.T) {
 	var received *guac.MessageEvent
-	handleMessage := func(q queue.Queue, event guac.MessageEvent) (queue.Queue, error) {
+	handleMessage := func(qs queue.Channels, event guac.MessageEvent) (queue.Channels, error) {
 		received = &event
-		return q, nil
+		return qs, nil
 	}
 	handleUserChange := func(event guac.UserInfo) {
 	}
@@ -78,9 +78,9 @@ func TestDispatcherSendsMessagesToMessageHandler(t *testing.T) {
 
 func TestDispatcherSendsMessagesOnlyOnce(t *testing.T) {
 	calls := 0
-	handleMessage := func(q queue.Queue, event guac.MessageEvent) (queue.Queue, error) {
+	handleMessage := func(qs queue.Channels, event guac.MessageEvent) (queue.Channels, error) {
 		calls++
-		return q, nil
+		return qs, nil
 	}
 	handleUserChange := func(event guac.UserInfo) {
 	}
@@ -93,8 +93,8 @@ func TestDispatcherSendsMessagesOnlyOnce(t *testing.T) {
 
 func TestDispatcherDoesNotSendMessageToUserChangeHandler(t *testing.T) {
 	calls := 0
-	handleMessage := func(q queue.Queue, event guac.MessageEvent) (queue.Queue, error) {
-		return q, nil
+	handleMessage := func(qs queue.Channels, event guac.MessageEvent) (queue.Channels, error) {
+		return qs, nil
 	}
 	handleUserChange := func(event guac.UserInfo) {
 		calls++
@@ -107,8 +107,8 @@ func TestDispatcherDoesNotSendMessageToUserChangeHandler(t *testing.T) {
 }
 
 func TestDispatcherReturnsErrorIfMessageFails(t *testing.T) {
-	handleMessage := func(q queue.Queue, event guac.MessageEvent) (queue.Queue, error) {
-		return q, fmt.Errorf("Error!")
+	handleMessage := func(qs queue.Channels, event guac.MessageEvent) (queue.Channels, error) {
+		return qs, fmt.Errorf("Error!")
 	}
 	handleUserChange := func(event guac.UserInfo) {
 	}
@@ -130,14 +130,14 @@ func testUserChangeDispatch(handleMessage MessageHandler, handleUserChange UserC
 		close(done)
 	}()
 
-	dispatcher := CreateDispatcher(queue.Queue{}, 1*time.Millisecond, handleMessage, handleUserChange)
+	dispatcher := CreateDispatcher(queue.Channels{}, 1*time.Millisecond, handleMessage, handleUserChange, nil, nil, nil)
 	return dispatcher(events, done)
 }
 
 func TestDispatcherSendsUserChangesToUserChangeHandler(t *testing.T) {
 	var received *guac.UserInfo
-	handleMessage := func(q queue.Queue, event guac.MessageEvent) (queue.Queue, error) {
-		return q, nil
+	handleMessage := func(qs queue.Channels, event guac.MessageEvent) (queue.Channels, error) {
+		return qs, nil
 	}
 	handleUserChange := func(event guac.UserInfo) {
 		received = &event
@@ -151,9 +151,9 @@ func TestDispatcherSendsUserChangesToUserChangeHandler(t *testing.T) {
 
 func TestDispatcherDoesNotSendUserChangeToMessageHandler(t *testing.T) {
 	calls := 0
-	handleMessage := func(q queue.Queue, event guac.MessageEvent) (queue.Queue, error) {
+	handleMessage := func(qs queue.Channels, event guac.MessageEvent) (queue.Channels, error) {
 		calls++
-		return q, nil
+		return qs, nil
 	}
 	handleUserChange := func(event guac.UserInfo) {
 	}
@@ -166,8 +166,8 @@ func TestDispatcherDoesNotSendUserChangeToMessageHandler(t *testing.T) {
 
 func TestDispatcherSendsUserChangeOnlyOnce(t *testing.T) {
 	calls := 0
-	handleMessage := func(q queue.Queue, event guac.MessageEvent) (queue.Queue, error) {
-		return q, nil
+	handleMessage := func(qs queue.Channels, event guac.MessageEvent) (queue.Channels, error) {
+		return qs, nil
 	}
 	handleUserChange := func(event guac.UserInfo) {
 		calls++
@@ -183,12 +183,12 @@ func TestDispatcherReturnsErrorOnTimeout(t *testing.T) {
 	done := make(DoneChan)
 	events := make(guac.EventChan)
 
-	handleMessage := func(q queue.Queue, event guac.MessageEvent) (queue.Queue, error) {
-		return q, nil
+	handleMessage := func(qs queue.Channels, event guac.MessageEvent) (queue.Channels, error) {
+		return qs, nil
 	}
 	handleUserChange := func(event guac.UserInfo) {
 	}
-	dispatcher := CreateDispatcher(queue.Queue{}, 1*time.Millisecond, handleMessage, handleUserChange)
+	dispatcher := CreateDispatcher(queue.Channels{}, 1*time.Millisecond, handleMessage, handleUserChange, nil, nil, nil)
 
 	err := dispatcher(events, done)
 	if err == nil {
@@ -200,12 +200,12 @@ func TestDispatcherReturnsNoErrorIfDone(t *testing.T) {
 	done := make(DoneChan)
 	events := make(guac.EventChan)
 
-	handleMessage := func(q queue.Queue, event guac.MessageEvent) (queue.Queue, error) {
-		return q, nil
+	handleMessage := func(qs queue.Channels, event guac.MessageEvent) (queue.Channels, error) {
+		return qs, nil
 	}
 	handleUserChange := func(event guac.UserInfo) {
 	}
-	dispatcher := CreateDispatcher(queue.Queue{}, 1*time.Millisecond, handleMessage, handleUserChange)
+	dispatcher := CreateDispatcher(queue.Channels{}, 1*time.Millisecond, handleMessage, handleUserChange, nil, nil, nil)
 
 	close(done)
 	err := dispatcher(events, done)
@@ -218,14 +218,14 @@ func TestDispatcherSwallowsUnknownEvents(t *testing.T) {
 	done := make(DoneChan)
 	events := make(guac.EventChan)
 
-	handleMessage := func(q queue.Queue, event guac.MessageEvent) (queue.Queue, error) {
+	handleMessage := func(qs queue.Channels, event guac.MessageEvent) (queue.Channels, error) {
 		t.Fatal("Unexpected call to MessageHandler")
-		return q, nil
+		return qs, nil
 	}
 	handleUserChange := func(event guac.UserInfo) {
 		t.Fatal("Unexpected call to MessageHandler")
 	}
-	dispatcher := CreateDispatcher(queue.Queue{}, 1*time.Second, handleMessage, handleUserChange)
+	dispatcher := CreateDispatcher(queue.Channels{}, 1*time.Second, handleMessage, handleUserChange, nil, nil, nil)
 
 	// events is blocking so these things must be read in sequence
 	go func() {
@@ -244,22 +244,22 @@ func TestDispatcherSwallowsUnknownEvents(t *testing.T) {
 func TestDispatcherPassesUpdatedQueueToMessageHandler(t *testing.T) {
 	done := make(DoneChan)
 	events := make(guac.EventChan)
-	expectedQueue := queue.Queue([]queue.Item{{ID: "U123", Reason: "Tomato"}})
-	var receivedQueue queue.Queue
+	expectedQueue := queue.Channels{"C1A2B3C": queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{{ID: "U123", Reason: "Tomato"}}}}}
+	var receivedQueue queue.Channels
 
 	called := false
-	handleMessage := func(q queue.Queue, event guac.MessageEvent) (queue.Queue, error) {
+	handleMessage := func(qs queue.Channels, event guac.MessageEvent) (queue.Channels, error) {
 		if !called {
 			called = true
 			return expectedQueue, nil
 		}
-		receivedQueue = q
-		return q, nil
+		receivedQueue = qs
+		return qs, nil
 	}
 	handleUserChange := func(event guac.UserInfo) {
 		t.Fatal("Unexpected call to MessageHandler")
 	}
-	dispatcher := CreateDispatcher(queue.Queue{}, 1*time.Second, handleMessage, handleUserChange)
+	dispatcher := CreateDispatcher(queue.Channels{}, 1*time.Second, handleMessage, handleUserChange, nil, nil, nil)
 
 	// events is blocking so these things must be read in sequence
 	go func() {
@@ -277,3 +277,138 @@ func TestDispatcherPassesUpdatedQueueToMessageHandler(t *testing.T) {
 		t.Fatal("Unexpected queue received on second call", expectedQueue, receivedQueue)
 	}
 }
+
+func TestDispatcherSendsTicksToTickHandler(t *testing.T) {
+	done := make(DoneChan)
+	events := make(guac.EventChan)
+	ticks := make(chan time.Time)
+	now := time.Date(2017, 3, 14, 10, 0, 0, 0, time.UTC)
+	expectedQueue := queue.Channels{"C1A2B3C": queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{{ID: "U123", Reason: "Tomato"}}}}}
+
+	var received time.Time
+	handleTick := func(qs queue.Channels, t time.Time) (queue.Channels, error) {
+		received = t
+		return expectedQueue, nil
+	}
+
+	var receivedQueue queue.Channels
+	handleMessage := func(qs queue.Channels, event guac.MessageEvent) (queue.Channels, error) {
+		receivedQueue = qs
+		return qs, nil
+	}
+	handleUserChange := func(event guac.UserInfo) {
+	}
+	dispatcher := CreateDispatcher(queue.Channels{}, 1*time.Second, handleMessage, handleUserChange, ticks, handleTick, nil)
+
+	go func() {
+		ticks <- now
+		events <- guac.MessageEvent{
+			Text: "test event",
+		}
+		close(done)
+	}()
+	dispatcher(events, done)
+
+	if received != now {
+		t.Fatal("Expected tick to be received ", now, received)
+	}
+
+	if !receivedQueue.Equal(expectedQueue) {
+		t.Fatal("Unexpected queue received after tick", expectedQueue, receivedQueue)
+	}
+}
+
+func TestDispatcherReturnsErrorIfTickFails(t *testing.T) {
+	done := make(DoneChan)
+	events := make(guac.EventChan)
+	ticks := make(chan time.Time, 1)
+	ticks <- time.Now()
+
+	handleTick := func(qs queue.Channels, t time.Time) (queue.Channels, error) {
+		return qs, fmt.Errorf("Error!")
+	}
+	handleMessage := func(qs queue.Channels, event guac.MessageEvent) (queue.Channels, error) {
+		return qs, nil
+	}
+	handleUserChange := func(event guac.UserInfo) {
+	}
+	dispatcher := CreateDispatcher(queue.Channels{}, 1*time.Second, handleMessage, handleUserChange, ticks, handleTick, nil)
+
+	err := dispatcher(events, done)
+	if err == nil {
+		t.Fatal("Expected error")
+	}
+}
+
+func TestDispatcherTimesOutDespiteTicks(t *testing.T) {
+	done := make(DoneChan)
+	events := make(guac.EventChan)
+	ticks := make(chan time.Time)
+	defer close(done)
+
+	handleTick := func(qs queue.Channels, t time.Time) (queue.Channels, error) {
+		return qs, nil
+	}
+	handleMessage := func(qs queue.Channels, event guac.MessageEvent) (queue.Channels, error) {
+		return qs, nil
+	}
+	handleUserChange := func(event guac.UserInfo) {
+	}
+	dispatcher := CreateDispatcher(queue.Channels{}, 50*time.Millisecond, handleMessage, handleUserChange, ticks, handleTick, nil)
+
+	go func() {
+		for {
+			select {
+			case ticks <- time.Now():
+				time.Sleep(5 * time.Millisecond)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	result := make(chan error)
+	go func() {
+		result <- dispatcher(events, done)
+	}()
+
+	select {
+	case err := <-result:
+		if err == nil {
+			t.Fatal("Expected timeout error")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected dispatcher to time out within 2 seconds")
+	}
+}
+
+func TestDispatcherDoesJobsInTurnWithEvents(t *testing.T) {
+	done := make(DoneChan)
+	events := make(guac.EventChan)
+	jobs := make(chan Job)
+
+	var seen queue.Channels
+	handleMessage := func(qs queue.Channels, event guac.MessageEvent) (queue.Channels, error) {
+		seen = qs
+		return qs, nil
+	}
+	handleUserChange := func(event guac.UserInfo) {
+	}
+	dispatcher := CreateDispatcher(queue.Channels{}, 1*time.Second, handleMessage, handleUserChange, nil, nil, jobs)
+
+	go func() {
+		jobs <- func(qs queue.Channels) (queue.Channels, error) {
+			return qs.Set("C1234", queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{{ID: "U123", Reason: "Banana"}}}}), nil
+		}
+		events <- guac.MessageEvent{Text: "test event"}
+		close(done)
+	}()
+
+	err := dispatcher(events, done)
+	if err != nil {
+		t.Fatal("Unexpected error ", err)
+	}
+	if len(seen.Get("C1234").Get(queue.DefaultToken)) != 1 {
+		t.Fatal("Expected message to see the change made by the job, got ", seen)
+	}
+}
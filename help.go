@@ -0,0 +1,10 @@
+package qbot
+
+import "github.com/doozr/qbot/command"
+
+// help explains the commands in the public and private command maps, so that it only explains what the bot will do.
+func help(commands command.QueueCommands) command.Command {
+	return commands.Help(
+		append(publicCommands(commands).Names(), "help"),
+		append(privateCommands(commands).Names(), "help"))
+}
@@ -0,0 +1,36 @@
+package qbot_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/doozr/qbot/command"
+	"github.com/doozr/qbot/queue"
+
+	. "github.com/doozr/qbot"
+)
+
+func TestEveryCommandIsDocumented(t *testing.T) {
+	for _, name := range PublicCommands(timerCommands).Names() {
+		if !command.Documented(name) {
+			t.Fatal("Expected help for public command ", name)
+		}
+	}
+	for _, name := range PrivateCommands(timerCommands).Names() {
+		if !command.Documented(name) {
+			t.Fatal("Expected help for private command ", name)
+		}
+	}
+}
+
+func TestHelpExplainsCommandsInBothMaps(t *testing.T) {
+	_, n := PublicCommands(timerCommands)["help"](queue.Tokens{}, "C1234", "U123", "")
+	if !strings.Contains(n.Message, "`join <reason>`") || !strings.Contains(n.Message, "`where` - ") {
+		t.Fatal("Expected public and private commands, got ", n.Message)
+	}
+
+	n = PrivateCommands(timerCommands)["help"](queue.Channels{}, "D1234", "U123", "notify")
+	if !strings.HasPrefix(n.Message, "`notify on|next|off`\n") {
+		t.Fatal("Expected detail about notify, got ", n.Message)
+	}
+}
@@ -0,0 +1,120 @@
+package history
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/doozr/qbot/queue"
+)
+
+// Change is a change made to the tokens of a channel by a single command
+type Change struct {
+	ID      int
+	Actor   string
+	Command string
+	Time    time.Time
+	Before  queue.Tokens
+	After   queue.Tokens
+	Undone  bool
+}
+
+// Changed returns the names of the tokens that the change created, deleted or altered
+func (c Change) Changed() []string {
+	changed := []string{}
+	for _, name := range tokenNames(c.Before, c.After) {
+		if !Only(c.Before, name).Equal(Only(c.After, name)) {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// Touches checks if the change created, deleted or altered any of the named tokens
+func (c Change) Touches(names []string) bool {
+	for _, changed := range c.Changed() {
+		for _, name := range names {
+			if changed == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Only returns just the named token, or no tokens at all if it does not exist
+func Only(t queue.Tokens, name string) queue.Tokens {
+	if tok, ok := t[name]; ok {
+		return queue.Tokens{name: tok}
+	}
+	return queue.Tokens{}
+}
+
+func tokenNames(before, after queue.Tokens) []string {
+	seen := map[string]bool{}
+	for name := range before {
+		seen[name] = true
+	}
+	for name := range after {
+		seen[name] = true
+	}
+
+	names := []string{}
+	for name := range seen {
+		names = append(names, name)
+	}
+	return names
+}
+
+// History remembers the most recent changes made in each channel
+type History interface {
+	Record(channel string, change Change)
+	Changes(channel string) []Change
+	MarkUndone(channel string, id int)
+}
+
+// history contains a mutex controlled list of changes keyed on channel
+type history struct {
+	Mux     sync.Mutex
+	Limit   int
+	NextID  int
+	Channel map[string][]Change
+}
+
+// New creates an instance of History that remembers up to limit changes in each channel
+func New(limit int) History {
+	return &history{Limit: limit, NextID: 1, Channel: map[string][]Change{}}
+}
+
+// Record adds a change to the history of a channel, forgetting the oldest if there are too many
+func (h *history) Record(channel string, change Change) {
+	h.Mux.Lock()
+	change.ID = h.NextID
+	h.NextID++
+	changes := append(h.Channel[channel], change)
+	if len(changes) > h.Limit {
+		changes = changes[len(changes)-h.Limit:]
+	}
+	h.Channel[channel] = changes
+	h.Mux.Unlock()
+}
+
+// Changes returns a copy of the changes made in a channel, oldest first
+func (h *history) Changes(channel string) []Change {
+	h.Mux.Lock()
+	changes := append([]Change{}, h.Channel[channel]...)
+	h.Mux.Unlock()
+	return changes
+}
+
+// MarkUndone marks a change as undone so that it cannot be undone again
+func (h *history) MarkUndone(channel string, id int) {
+	h.Mux.Lock()
+	for ix := range h.Channel[channel] {
+		if h.Channel[channel][ix].ID == id {
+			h.Channel[channel][ix].Undone = true
+		}
+	}
+	h.Mux.Unlock()
+}
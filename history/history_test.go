@@ -0,0 +1,75 @@
+package history_test
+
+import (
+	"testing"
+
+	. "github.com/doozr/qbot/history"
+	"github.com/doozr/qbot/queue"
+)
+
+func TestRecordsChangesInOrder(t *testing.T) {
+	h := New(10)
+	h.Record("C1", Change{Command: "first"})
+	h.Record("C1", Change{Command: "second"})
+	h.Record("C2", Change{Command: "elsewhere"})
+
+	changes := h.Changes("C1")
+	if len(changes) != 2 || changes[0].Command != "first" || changes[1].Command != "second" {
+		t.Fatal("Unexpected changes ", changes)
+	}
+}
+
+func TestForgetsOldestChangesOverLimit(t *testing.T) {
+	h := New(2)
+	h.Record("C1", Change{Command: "first"})
+	h.Record("C1", Change{Command: "second"})
+	h.Record("C1", Change{Command: "third"})
+
+	changes := h.Changes("C1")
+	if len(changes) != 2 || changes[0].Command != "second" {
+		t.Fatal("Unexpected changes ", changes)
+	}
+}
+
+func TestMarksChangeUndone(t *testing.T) {
+	h := New(10)
+	h.Record("C1", Change{Command: "first"})
+	h.Record("C1", Change{Command: "second"})
+
+	h.MarkUndone("C1", h.Changes("C1")[0].ID)
+
+	changes := h.Changes("C1")
+	if !changes[0].Undone || changes[1].Undone {
+		t.Fatal("Unexpected changes ", changes)
+	}
+}
+
+func TestChangesAreCopies(t *testing.T) {
+	h := New(10)
+	h.Record("C1", Change{Command: "first"})
+	h.Changes("C1")[0].Undone = true
+
+	if h.Changes("C1")[0].Undone {
+		t.Fatal("Changes should not be modifiable")
+	}
+}
+
+func TestChangedNamesAlteredTokens(t *testing.T) {
+	before := queue.Tokens{}.Add("staging").Set(queue.DefaultToken, queue.Queue{{ID: "U1", Reason: "A"}})
+	after := queue.Tokens{}.Add("envs").Set(queue.DefaultToken, queue.Queue{{ID: "U1", Reason: "A"}})
+
+	changed := Change{Before: before, After: after}.Changed()
+	if len(changed) != 2 || changed[0] != "envs" || changed[1] != "staging" {
+		t.Fatal("Unexpected changed tokens ", changed)
+	}
+}
+
+func TestTouchesChangedTokensOnly(t *testing.T) {
+	c := Change{Before: queue.Tokens{}, After: queue.Tokens{}.Add("staging")}
+	if !c.Touches([]string{"staging"}) {
+		t.Fatal("Expected change to touch staging")
+	}
+	if c.Touches([]string{queue.DefaultToken}) {
+		t.Fatal("Expected change not to touch default token")
+	}
+}
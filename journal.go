@@ -0,0 +1,57 @@
+package qbot
+
+import (
+	"log"
+
+	"github.com/doozr/jot"
+	"github.com/doozr/qbot/journal"
+)
+
+// Journal records changes to the queues as they happen.
+type Journal func([]journal.Event) error
+
+// AppendFile is a type to allow replacement of the function that appends to a file.
+type AppendFile func(string, []byte) error
+
+// CreateJournal creates a new Journal that appends events to a file one per line.
+func CreateJournal(appendFile AppendFile, filename string) Journal {
+	return func(events []journal.Event) (err error) {
+		if len(events) == 0 {
+			return
+		}
+
+		j, err := journal.Marshal(events)
+		if err != nil {
+			log.Print("Error serialising events: ", err)
+			return
+		}
+
+		jot.Printf("journal: appending %d events to %s", len(events), filename)
+		err = appendFile(filename, j)
+		if err != nil {
+			log.Printf("Error appending to journal %s: %s", filename, err)
+		}
+		return
+	}
+}
+
+// CreateLogJournal creates a new Journal that keeps events in memory so that they can be looked back over.
+func CreateLogJournal(l journal.Log) Journal {
+	return func(events []journal.Event) error {
+		l.Append(events)
+		return nil
+	}
+}
+
+// ChainJournals creates a Journal that records events in each journal in turn, stopping at the first error.
+func ChainJournals(journals ...Journal) Journal {
+	return func(events []journal.Event) (err error) {
+		for _, record := range journals {
+			err = record(events)
+			if err != nil {
+				break
+			}
+		}
+		return
+	}
+}
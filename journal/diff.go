@@ -0,0 +1,151 @@
+package journal
+
+import (
+	"sort"
+	"time"
+
+	"github.com/doozr/qbot/queue"
+)
+
+// Diff works out the events that turn one set of queues into another
+//
+// Every event is attributed to the same actor and command, and given the same time.
+func Diff(before, after queue.Channels, actor, command string, now time.Time) []Event {
+	events := []Event{}
+	for _, channel := range channelNames(before, after) {
+		bt, at := before.Get(channel), after.Get(channel)
+		for _, name := range tokenNames(bt, at) {
+			e := Event{Time: now, Actor: actor, Command: command, Channel: channel, Token: name}
+			events = append(events, diffToken(e, bt, at)...)
+		}
+	}
+	return events
+}
+
+func channelNames(before, after queue.Channels) []string {
+	seen := map[string]bool{}
+	for channel := range before {
+		seen[channel] = true
+	}
+	for channel := range after {
+		seen[channel] = true
+	}
+	return sortedKeys(seen)
+}
+
+func tokenNames(before, after queue.Tokens) []string {
+	seen := map[string]bool{}
+	for name := range before {
+		seen[name] = true
+	}
+	for name := range after {
+		seen[name] = true
+	}
+	return sortedKeys(seen)
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// diffToken works out the events for a single token, starting from a template event
+//
+// A token is set up before any entries are moved so that its capacity is known, and only deleted once its queue is
+// empty.
+func diffToken(e Event, before, after queue.Tokens) []Event {
+	events := []Event{}
+	name := e.Token
+	_, existed := before[name]
+	_, exists := after[name]
+
+	deleted := existed && !exists && name != queue.DefaultToken
+	created := !existed && exists && name != queue.DefaultToken
+	changed := before.Capacity(name) != after.Capacity(name) ||
+		!before.Reservations(name).Equal(after.Reservations(name)) ||
+		!before.Requests(name).Equal(after.Requests(name)) ||
+		!before.Votes(name).Equal(after.Votes(name))
+
+	if created || (changed && !deleted) {
+		te := e
+		te.Type = TokenEvent
+		te.Capacity = after.Capacity(name)
+		te.Reservations = after.Reservations(name)
+		te.Requests = after.Requests(name)
+		te.Votes = after.Votes(name)
+		events = append(events, te)
+	}
+
+	events = append(events, diffQueue(e, before.Get(name), after.Get(name))...)
+
+	if deleted {
+		de := e
+		de.Type = DeleteTokenEvent
+		events = append(events, de)
+	}
+	return events
+}
+
+// diffQueue works out the entry events that turn one queue into another
+//
+// Entries that have gone are removed from the back first so that the positions of the others stay put. Then each
+// position is filled in turn with the entry that belongs there, moving it up from further back or adding it if it is
+// new. An entry that changes but stays in the same place is recorded with the same position before and after.
+func diffQueue(e Event, before, after queue.Queue) []Event {
+	events := []Event{}
+	add := func(i queue.Item, from, to int) {
+		ie := e
+		ie.Type = EntryEvent
+		ie.Item = &i
+		ie.Before = from
+		ie.After = to
+		events = append(events, ie)
+	}
+
+	current := append(queue.Queue{}, before...)
+	for ix := len(current) - 1; ix >= 0; ix-- {
+		if !after.Contains(current[ix]) {
+			add(current[ix], ix+1, 0)
+			current = move(current, current[ix], ix+1, 0)
+		}
+	}
+
+	for ix, i := range after {
+		if ix < len(current) && current[ix] == i {
+			continue
+		}
+
+		from := 0
+		for cx, c := range current {
+			if c.Is(i) {
+				from = cx + 1
+				break
+			}
+		}
+
+		add(i, from, ix+1)
+		current = move(current, i, from, ix+1)
+	}
+
+	return events
+}
+
+// move takes the entry at one position out of a queue and puts the given item at another
+func move(q queue.Queue, i queue.Item, from, to int) queue.Queue {
+	nq := append(queue.Queue{}, q...)
+	if from > 0 && from <= len(nq) {
+		nq = append(nq[:from-1], nq[from:]...)
+	}
+
+	if to > 0 {
+		if to > len(nq) {
+			to = len(nq) + 1
+		}
+		nq = append(nq[:to-1], append(queue.Queue{i}, nq[to-1:]...)...)
+	}
+	return nq
+}
@@ -0,0 +1,42 @@
+package journal
+
+import (
+	"time"
+
+	"github.com/doozr/qbot/queue"
+)
+
+// EventType says what part of the queues an Event changes
+type EventType string
+
+const (
+	// EntryEvent moves an entry from one position in a queue to another, adds it or removes it
+	EntryEvent EventType = "entry"
+
+	// TokenEvent creates a token or changes how many can hold it, who has reserved it, who has asked to move and who has
+	// voted to oust its holders
+	TokenEvent EventType = "token"
+
+	// DeleteTokenEvent removes a named token
+	DeleteTokenEvent EventType = "delete-token"
+)
+
+// Event records a single change to the queues, along with who made it and with which command
+//
+// Positions count from one, and a position of zero means the entry was not in the queue. Before and After are the
+// positions immediately before and after this event, so applying each event in turn reproduces every change.
+type Event struct {
+	Time         time.Time
+	Actor        string
+	Command      string
+	Type         EventType
+	Channel      string
+	Token        string
+	Item         *queue.Item        `json:",omitempty"`
+	Before       int                `json:",omitempty"`
+	After        int                `json:",omitempty"`
+	Capacity     int                `json:",omitempty"`
+	Reservations queue.Reservations `json:",omitempty"`
+	Requests     queue.Requests     `json:",omitempty"`
+	Votes        queue.Votes        `json:",omitempty"`
+}
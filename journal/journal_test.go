@@ -0,0 +1,175 @@
+package journal_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	. "github.com/doozr/qbot/journal"
+	"github.com/doozr/qbot/queue"
+	"github.com/stretchr/testify/assert"
+)
+
+var now = time.Date(2017, 3, 14, 12, 0, 0, 0, time.UTC)
+
+var (
+	mick  = queue.Item{ID: "mick", Reason: "refactoring", Key: "a2y"}
+	john  = queue.Item{ID: "john", Reason: "done some coding", Key: "o67"}
+	jimmy = queue.Item{ID: "jimmy", Reason: "fix some bugs", Key: "t8n"}
+	colin = queue.Item{ID: "colin", Reason: "adding bugs", Key: "qq6"}
+)
+
+func channels(t queue.Tokens) queue.Channels {
+	return queue.Channels{}.Set("C1", t)
+}
+
+func tokens(q queue.Queue) queue.Tokens {
+	return queue.Tokens{}.Set(queue.DefaultToken, q)
+}
+
+func assertRoundTrip(t *testing.T, before, after queue.Channels) []Event {
+	events := Diff(before, after, "U1", "cmd", now)
+	assert.True(t, after.Equal(Replay(before, events)), "replayed %v, expected %v", Replay(before, events), after)
+	return events
+}
+
+func TestDiffOfIdenticalQueuesIsEmpty(t *testing.T) {
+	qs := channels(tokens(queue.Queue{mick, john}))
+	assert.Len(t, Diff(qs, qs, "U1", "cmd", now), 0)
+}
+
+func TestJoinIsRecordedAsAnEntryWithNoPreviousPosition(t *testing.T) {
+	events := assertRoundTrip(t,
+		channels(tokens(queue.Queue{mick})),
+		channels(tokens(queue.Queue{mick, john})))
+
+	assert.Equal(t, []Event{{
+		Time: now, Actor: "U1", Command: "cmd", Type: EntryEvent,
+		Channel: "C1", Token: queue.DefaultToken, Item: &john, Before: 0, After: 2,
+	}}, events)
+}
+
+func TestLeaveIsRecordedAsAnEntryWithNoNewPosition(t *testing.T) {
+	events := assertRoundTrip(t,
+		channels(tokens(queue.Queue{mick, john, jimmy})),
+		channels(tokens(queue.Queue{mick, jimmy})))
+
+	assert.Len(t, events, 1)
+	assert.Equal(t, &john, events[0].Item)
+	assert.Equal(t, 2, events[0].Before)
+	assert.Equal(t, 0, events[0].After)
+}
+
+func TestDoneEmptiesTheChannel(t *testing.T) {
+	assertRoundTrip(t, channels(tokens(queue.Queue{mick})), queue.Channels{})
+}
+
+func TestBargeIsRecordedAsAMove(t *testing.T) {
+	events := assertRoundTrip(t,
+		channels(tokens(queue.Queue{mick, john, jimmy, colin})),
+		channels(tokens(queue.Queue{mick, colin, john, jimmy})))
+
+	assert.Len(t, events, 1)
+	assert.Equal(t, &colin, events[0].Item)
+	assert.Equal(t, 4, events[0].Before)
+	assert.Equal(t, 2, events[0].After)
+}
+
+func TestYieldRoundTrips(t *testing.T) {
+	assertRoundTrip(t,
+		channels(tokens(queue.Queue{mick, john, jimmy})),
+		channels(tokens(queue.Queue{john, mick, jimmy})))
+}
+
+func TestChangedEntryIsRecordedInPlace(t *testing.T) {
+	active := john
+	active.ActiveSince = now
+	events := assertRoundTrip(t,
+		channels(tokens(queue.Queue{mick, john})),
+		channels(tokens(queue.Queue{active})))
+
+	assert.Len(t, events, 2)
+	assert.Equal(t, 1, events[1].Before)
+	assert.Equal(t, 1, events[1].After)
+}
+
+func TestReplaceRoundTrips(t *testing.T) {
+	replaced := queue.Item{ID: "colin", Reason: "refactoring", Key: "a2y"}
+	assertRoundTrip(t,
+		channels(tokens(queue.Queue{mick, john})),
+		channels(tokens(queue.Queue{replaced, john})))
+}
+
+func TestCreatingTokenIsRecordedBeforeItsEntries(t *testing.T) {
+	after := channels(queue.Tokens{}.Add("envs").SetCapacity("envs", 2).Set("envs", queue.Queue{mick}))
+	events := assertRoundTrip(t, queue.Channels{}, after)
+
+	assert.Len(t, events, 2)
+	assert.Equal(t, TokenEvent, events[0].Type)
+	assert.Equal(t, 2, events[0].Capacity)
+	assert.Equal(t, EntryEvent, events[1].Type)
+}
+
+func TestReservationsRoundTrip(t *testing.T) {
+	r := queue.Reservation{ID: "mick", Reason: "release", Start: now, End: now.Add(time.Hour)}
+	before := channels(tokens(queue.Queue{john}))
+	after := channels(tokens(queue.Queue{john}).SetReservations(queue.DefaultToken, queue.Reservations{r}))
+	assertRoundTrip(t, before, after)
+	assertRoundTrip(t, after, before)
+}
+
+func TestDeletingTokenIsRecordedAfterItsEntries(t *testing.T) {
+	before := channels(queue.Tokens{}.Add("envs").Set("envs", queue.Queue{mick, john}))
+	events := assertRoundTrip(t, before, queue.Channels{})
+
+	assert.Len(t, events, 3)
+	assert.Equal(t, DeleteTokenEvent, events[2].Type)
+}
+
+func TestSeveralChannelsRoundTrip(t *testing.T) {
+	before := queue.Channels{}.
+		Set("C1", tokens(queue.Queue{mick, john})).
+		Set("C2", queue.Tokens{}.Add("envs").Set("envs", queue.Queue{jimmy}))
+	after := queue.Channels{}.
+		Set("C1", tokens(queue.Queue{john, colin})).
+		Set("C3", tokens(queue.Queue{mick}))
+	assertRoundTrip(t, before, after)
+}
+
+func TestReplayingEveryChangeFromEmptyReproducesTheQueues(t *testing.T) {
+	states := []queue.Channels{
+		channels(tokens(queue.Queue{mick})),
+		channels(tokens(queue.Queue{mick, john, jimmy})),
+		channels(tokens(queue.Queue{mick, jimmy, john})),
+		channels(tokens(queue.Queue{jimmy, john}).Add("envs").Set("envs", queue.Queue{colin})),
+		channels(queue.Tokens{}.Add("envs").SetCapacity("envs", 2).Set("envs", queue.Queue{colin, mick})),
+	}
+
+	events := []Event{}
+	previous := queue.Channels{}
+	for _, s := range states {
+		events = append(events, Diff(previous, s, "U1", "cmd", now)...)
+		previous = s
+	}
+
+	assert.True(t, previous.Equal(Replay(queue.Channels{}, events)))
+}
+
+func TestEventsSurviveBeingWrittenAndRead(t *testing.T) {
+	r := queue.Reservation{ID: "mick", Reason: "release", Start: now, End: now.Add(time.Hour)}
+	after := channels(queue.Tokens{}.Add("envs").SetReservations("envs", queue.Reservations{r}).Set("envs", queue.Queue{mick, john}))
+	events := Diff(queue.Channels{}, after, "U1", "cmd", now)
+
+	b, err := Marshal(events)
+	assert.NoError(t, err)
+	assert.Equal(t, len(events), bytes.Count(b, []byte("\n")))
+
+	read, err := Read(bytes.NewReader(b))
+	assert.NoError(t, err)
+	assert.True(t, after.Equal(Replay(queue.Channels{}, read)))
+}
+
+func TestReadRejectsBadLines(t *testing.T) {
+	_, err := Read(bytes.NewReader([]byte("{\"Type\":\"entry\"}\nnot json\n")))
+	assert.Error(t, err)
+}
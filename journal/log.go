@@ -0,0 +1,49 @@
+package journal
+
+import (
+	"sync"
+	"time"
+)
+
+// Log keeps the most recent events in memory so that they can be looked back over
+type Log interface {
+	Append([]Event)
+	Events() []Event
+}
+
+// memoryLog contains a mutex controlled list of events, oldest first
+type memoryLog struct {
+	Mux     sync.Mutex
+	Retain  time.Duration
+	Entries []Event
+}
+
+// NewLog creates an instance of Log holding the given events, which forgets events once they are older than retain
+func NewLog(events []Event, retain time.Duration) Log {
+	l := &memoryLog{Retain: retain}
+	l.Append(events)
+	return l
+}
+
+// Append adds events to the end of the log, forgetting any that are now too old
+func (l *memoryLog) Append(events []Event) {
+	l.Mux.Lock()
+	l.Entries = append(l.Entries, events...)
+	if len(l.Entries) > 0 {
+		cutoff := l.Entries[len(l.Entries)-1].Time.Add(-l.Retain)
+		ix := 0
+		for ix < len(l.Entries) && l.Entries[ix].Time.Before(cutoff) {
+			ix++
+		}
+		l.Entries = append([]Event{}, l.Entries[ix:]...)
+	}
+	l.Mux.Unlock()
+}
+
+// Events returns a copy of the events in the log, oldest first
+func (l *memoryLog) Events() []Event {
+	l.Mux.Lock()
+	events := append([]Event{}, l.Entries...)
+	l.Mux.Unlock()
+	return events
+}
@@ -0,0 +1,74 @@
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+
+	"github.com/doozr/qbot/queue"
+)
+
+// Apply makes the change recorded by an event
+func Apply(qs queue.Channels, e Event) queue.Channels {
+	t := qs.Get(e.Channel)
+
+	switch e.Type {
+	case EntryEvent:
+		if e.Item == nil {
+			return qs
+		}
+		t = t.Set(e.Token, move(t.Get(e.Token), *e.Item, e.Before, e.After))
+
+	case TokenEvent:
+		t = t.Add(e.Token).SetCapacity(e.Token, e.Capacity).SetReservations(e.Token, e.Reservations).SetRequests(e.Token, e.Requests).
+			SetVotes(e.Token, e.Votes)
+
+	case DeleteTokenEvent:
+		t = t.Remove(e.Token)
+	}
+
+	return qs.Set(e.Channel, t)
+}
+
+// Replay applies a list of events in order, starting from the given queues
+func Replay(qs queue.Channels, events []Event) queue.Channels {
+	for _, e := range events {
+		qs = Apply(qs, e)
+	}
+	return qs
+}
+
+// Read reads events written one per line as JSON
+func Read(r io.Reader) (events []Event, err error) {
+	events = []Event{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var e Event
+		err = json.Unmarshal(line, &e)
+		if err != nil {
+			return
+		}
+		events = append(events, e)
+	}
+	err = scanner.Err()
+	return
+}
+
+// Marshal writes events one per line as JSON
+func Marshal(events []Event) (b []byte, err error) {
+	for _, e := range events {
+		var j []byte
+		j, err = json.Marshal(e)
+		if err != nil {
+			return
+		}
+		b = append(append(b, j...), '\n')
+	}
+	return
+}
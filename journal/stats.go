@@ -0,0 +1,130 @@
+package journal
+
+import (
+	"sort"
+	"time"
+
+	"github.com/doozr/qbot/queue"
+)
+
+// UserStats totals up how long one user has waited for and held tokens
+type UserStats struct {
+	Holds  int
+	Held   time.Duration
+	Waited time.Duration
+}
+
+// Stats sums up how long people waited for and held tokens, and how often they pushed in or pushed others out
+type Stats struct {
+	Waits  []time.Duration
+	Holds  []time.Duration
+	Barges int
+	Ousts  int
+	Users  map[string]UserStats
+}
+
+// invocation identifies the single use of a command that caused a group of events
+type invocation struct {
+	At      int64
+	Actor   string
+	Channel string
+	Command string
+}
+
+// oustCommands are the commands that take the token away from its holder
+var oustCommands = map[string]bool{"oust": true, "hold": true}
+
+// entryKey identifies an entry across events, even if it had no key of its own yet
+func entryKey(e Event) string {
+	key := e.Item.Key
+	if key == "" {
+		key = e.Item.ID + "/" + e.Item.Reason
+	}
+	return e.Channel + "/" + e.Token + "/" + key
+}
+
+// Summarise works out the stats for a channel, or for every channel if none is given, from the events since a time
+//
+// A wait is counted when an entry acquires the token and a hold when it gives the token up, so entries still waiting
+// or holding the token are left out. When an entry is delegated to somebody else, the hold so far belongs to whoever
+// delegated it, and the new owner has not waited for the token.
+func Summarise(events []Event, channel string, since time.Time) Stats {
+	s := Stats{Waits: []time.Duration{}, Holds: []time.Duration{}, Users: map[string]UserStats{}}
+	entries := map[string]queue.Item{}
+	commands := map[invocation]bool{}
+
+	for _, e := range events {
+		if e.Type != EntryEvent || e.Item == nil || (channel != "" && e.Channel != channel) {
+			continue
+		}
+
+		key := entryKey(e)
+		prev, known := entries[key]
+		if e.After == 0 {
+			delete(entries, key)
+		} else {
+			entries[key] = *e.Item
+		}
+
+		if e.Time.Before(since) {
+			continue
+		}
+
+		command := invocation{At: e.Time.UnixNano(), Actor: e.Actor, Channel: e.Channel, Command: e.Command}
+		if !commands[command] {
+			commands[command] = true
+			if e.Command == "barge" {
+				s.Barges++
+			}
+			if oustCommands[e.Command] {
+				s.Ousts++
+			}
+		}
+
+		i := *e.Item
+		handover := known && e.After > 0 && prev.ID != i.ID
+
+		acquired := !i.ActiveSince.IsZero() && (!known || !prev.ActiveSince.Equal(i.ActiveSince))
+		if e.After > 0 && acquired && !handover && !i.JoinedAt.IsZero() {
+			wait := i.ActiveSince.Sub(i.JoinedAt)
+			s.Waits = append(s.Waits, wait)
+			u := s.Users[i.ID]
+			u.Waited += wait
+			s.Users[i.ID] = u
+		}
+
+		holder, held := i.ID, i.ActiveSince
+		if e.After > 0 {
+			held = time.Time{}
+			if known && !prev.ActiveSince.IsZero() && !prev.ActiveSince.Equal(i.ActiveSince) {
+				holder, held = prev.ID, prev.ActiveSince
+			}
+		}
+		if !held.IsZero() {
+			hold := e.Time.Sub(held)
+			s.Holds = append(s.Holds, hold)
+			u := s.Users[holder]
+			u.Holds++
+			u.Held += hold
+			s.Users[holder] = u
+		}
+	}
+
+	return s
+}
+
+// Percentile returns the duration that the given percentage of durations are no longer than
+func Percentile(ds []time.Duration, percent int) time.Duration {
+	if len(ds) == 0 {
+		return 0
+	}
+
+	sorted := append([]time.Duration{}, ds...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	rank := (percent*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
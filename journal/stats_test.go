@@ -0,0 +1,147 @@
+package journal_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/doozr/qbot/journal"
+	"github.com/doozr/qbot/queue"
+	"github.com/stretchr/testify/assert"
+)
+
+// step is a state of the queue, as left by the given actor and command at the given time
+type step struct {
+	at      time.Duration
+	actor   string
+	command string
+	queue   queue.Queue
+}
+
+// play records the events for a series of steps, carrying over when each entry joined and acquired the token
+//
+// An entry with the key of an entry owned by somebody else is delegated to them.
+func play(steps []step) []Event {
+	events := []Event{}
+	previous := queue.Channels{}
+	for _, s := range steps {
+		t := now.Add(s.at)
+		q := queue.Queue{}
+		for _, i := range s.queue {
+			for _, p := range previous.Get("C1").Get(queue.DefaultToken) {
+				if p.Is(i) && p.ID == i.ID {
+					i = p
+				} else if p.Is(i) {
+					i = queue.Queue{p}.Delegate(p, i)[0]
+				}
+			}
+			q = append(q, i)
+		}
+		next := channels(tokens(q)).Stamp(t)
+		events = append(events, Diff(previous, next, s.actor, s.command, t)...)
+		previous = next
+	}
+	return events
+}
+
+func TestSummariseWaitsAndHolds(t *testing.T) {
+	events := play([]step{
+		{0, "mick", "join", queue.Queue{mick}},
+		{10 * time.Minute, "john", "join", queue.Queue{mick, john}},
+		{30 * time.Minute, "mick", "done", queue.Queue{john}},
+		{90 * time.Minute, "john", "done", queue.Queue{}},
+	})
+
+	s := Summarise(events, "", now)
+	assert.Equal(t, []time.Duration{0, 20 * time.Minute}, s.Waits)
+	assert.Equal(t, []time.Duration{30 * time.Minute, time.Hour}, s.Holds)
+	assert.Equal(t, UserStats{Holds: 1, Held: 30 * time.Minute}, s.Users["mick"])
+	assert.Equal(t, UserStats{Holds: 1, Held: time.Hour, Waited: 20 * time.Minute}, s.Users["john"])
+}
+
+func TestSummariseCountsHoldEndedByYield(t *testing.T) {
+	events := play([]step{
+		{0, "mick", "join", queue.Queue{mick}},
+		{0, "john", "join", queue.Queue{mick, john}},
+		{time.Hour, "mick", "yield", queue.Queue{john, mick}},
+	})
+
+	s := Summarise(events, "", now)
+	assert.Equal(t, []time.Duration{time.Hour}, s.Holds)
+	assert.Equal(t, 1, s.Users["mick"].Holds)
+}
+
+func TestSummariseCreditsHoldToDelegator(t *testing.T) {
+	delegated := colin
+	delegated.Key = mick.Key
+
+	events := play([]step{
+		{0, "mick", "join", queue.Queue{mick}},
+		{time.Hour, "mick", "delegate", queue.Queue{delegated}},
+		{90 * time.Minute, "colin", "done", queue.Queue{}},
+	})
+
+	s := Summarise(events, "", now)
+	assert.Equal(t, []time.Duration{0}, s.Waits)
+	assert.Equal(t, []time.Duration{time.Hour, 30 * time.Minute}, s.Holds)
+	assert.Equal(t, UserStats{Holds: 1, Held: time.Hour}, s.Users["mick"])
+	assert.Equal(t, UserStats{Holds: 1, Held: 30 * time.Minute}, s.Users["colin"])
+}
+
+func TestSummariseCountsBargesAndOustsOncePerCommand(t *testing.T) {
+	events := play([]step{
+		{0, "mick", "join", queue.Queue{mick}},
+		{0, "john", "join", queue.Queue{mick, john}},
+		{0, "jimmy", "join", queue.Queue{mick, john, jimmy}},
+		{time.Minute, "jimmy", "barge", queue.Queue{mick, jimmy, john}},
+		{2 * time.Minute, "john", "oust", queue.Queue{jimmy, mick, john}},
+		{3 * time.Minute, "UBOT", "hold", queue.Queue{mick, jimmy, john}},
+	})
+
+	s := Summarise(events, "", now)
+	assert.Equal(t, 1, s.Barges)
+	assert.Equal(t, 2, s.Ousts)
+}
+
+func TestSummariseLeavesOutEventsBeforeTheStart(t *testing.T) {
+	events := play([]step{
+		{0, "mick", "join", queue.Queue{mick}},
+		{time.Hour, "mick", "done", queue.Queue{}},
+		{2 * time.Hour, "john", "join", queue.Queue{john}},
+		{3 * time.Hour, "john", "done", queue.Queue{}},
+	})
+
+	s := Summarise(events, "", now.Add(90*time.Minute))
+	assert.Equal(t, []time.Duration{time.Hour}, s.Holds)
+	assert.Len(t, s.Users, 1)
+	assert.Equal(t, time.Hour, s.Users["john"].Held)
+}
+
+func TestSummariseOnlyIncludesTheGivenChannel(t *testing.T) {
+	events := play([]step{
+		{0, "mick", "join", queue.Queue{mick}},
+		{time.Hour, "mick", "done", queue.Queue{}},
+	})
+
+	assert.Len(t, Summarise(events, "C2", now).Holds, 0)
+	assert.Len(t, Summarise(events, "C1", now).Holds, 1)
+}
+
+func TestPercentile(t *testing.T) {
+	ds := []time.Duration{5, 1, 4, 2, 3, 6, 7, 8, 9, 10}
+	assert.Equal(t, time.Duration(0), Percentile([]time.Duration{}, 50))
+	assert.Equal(t, time.Duration(5), Percentile(ds, 50))
+	assert.Equal(t, time.Duration(9), Percentile(ds, 90))
+	assert.Equal(t, time.Duration(1), Percentile(ds, 0))
+	assert.Equal(t, time.Duration(10), Percentile(ds, 100))
+}
+
+func TestLogForgetsOldEvents(t *testing.T) {
+	l := NewLog([]Event{{Time: now, Command: "old"}}, time.Hour)
+	l.Append([]Event{{Time: now.Add(30 * time.Minute), Command: "recent"}})
+	assert.Len(t, l.Events(), 2)
+
+	l.Append([]Event{{Time: now.Add(90 * time.Minute), Command: "new"}})
+	events := l.Events()
+	assert.Len(t, events, 2)
+	assert.Equal(t, "recent", events[0].Command)
+}
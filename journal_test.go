@@ -0,0 +1,116 @@
+package qbot_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/doozr/qbot"
+	"github.com/doozr/qbot/journal"
+)
+
+func TestJournalAppendsEventsOnePerLine(t *testing.T) {
+	var fileWritten string
+	var contentWritten []byte
+	appendFile := func(f string, c []byte) error {
+		fileWritten = f
+		contentWritten = c
+		return nil
+	}
+
+	record := CreateJournal(appendFile, "journal.jsonl")
+	err := record([]journal.Event{
+		{Actor: "U123", Command: "join", Type: journal.EntryEvent},
+		{Actor: "U123", Command: "join", Type: journal.EntryEvent},
+	})
+
+	if err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+
+	if fileWritten != "journal.jsonl" {
+		t.Fatal("Incorrect file written: ", fileWritten)
+	}
+
+	if strings.Count(string(contentWritten), "\n") != 2 {
+		t.Fatal("Expected 2 lines, got ", string(contentWritten))
+	}
+}
+
+func TestJournalDoesNotWriteWithoutEvents(t *testing.T) {
+	calls := 0
+	appendFile := func(f string, c []byte) error {
+		calls++
+		return nil
+	}
+
+	record := CreateJournal(appendFile, "journal.jsonl")
+	record([]journal.Event{})
+
+	if calls != 0 {
+		t.Fatal("Expected no calls to append, got ", calls)
+	}
+}
+
+func TestJournalReturnsAppendError(t *testing.T) {
+	appendFile := func(f string, c []byte) error {
+		return fmt.Errorf("Error!")
+	}
+
+	record := CreateJournal(appendFile, "journal.jsonl")
+	err := record([]journal.Event{{Type: journal.EntryEvent}})
+
+	if err == nil {
+		t.Fatal("Expected error")
+	}
+}
+
+func TestLogJournalKeepsEvents(t *testing.T) {
+	l := journal.NewLog([]journal.Event{}, time.Hour)
+	record := CreateLogJournal(l)
+	record([]journal.Event{{Command: "join"}})
+
+	if len(l.Events()) != 1 || l.Events()[0].Command != "join" {
+		t.Fatal("Unexpected events", l.Events())
+	}
+}
+
+func TestChainJournalsRecordsInEachJournal(t *testing.T) {
+	calls := []string{}
+	first := func(events []journal.Event) error {
+		calls = append(calls, "first")
+		return nil
+	}
+	second := func(events []journal.Event) error {
+		calls = append(calls, "second")
+		return nil
+	}
+
+	ChainJournals(first, second)([]journal.Event{{Command: "join"}})
+
+	if len(calls) != 2 || calls[0] != "first" || calls[1] != "second" {
+		t.Fatal("Unexpected calls", calls)
+	}
+}
+
+func TestChainJournalsStopsAtFirstError(t *testing.T) {
+	calls := 0
+	failing := func(events []journal.Event) error {
+		return fmt.Errorf("Error!")
+	}
+	counting := func(events []journal.Event) error {
+		calls++
+		return nil
+	}
+
+	err := ChainJournals(failing, counting)([]journal.Event{{Command: "join"}})
+
+	if err == nil {
+		t.Fatal("Expected error")
+	}
+
+	if calls != 0 {
+		t.Fatal("Expected no calls after error, got ", calls)
+	}
+}
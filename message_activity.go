@@ -0,0 +1,25 @@
+package qbot
+
+import (
+	"strings"
+
+	"github.com/doozr/guac"
+	"github.com/doozr/qbot/activity"
+	"github.com/doozr/qbot/queue"
+)
+
+// CreateActivityMessageHandler creates a message handler that calls another and remembers when each user last said
+// anything in a public channel.
+func CreateActivityMessageHandler(fn MessageHandler, a activity.Activity, now Clock) MessageHandler {
+	return func(oqs queue.Channels, m guac.MessageEvent) (qs queue.Channels, err error) {
+		qs, err = fn(oqs, m)
+		if err != nil {
+			return
+		}
+
+		if m.User != "" && !strings.HasPrefix(m.Channel, "D") {
+			a.Seen(m.Channel, m.User, now())
+		}
+		return
+	}
+}
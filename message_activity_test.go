@@ -0,0 +1,61 @@
+package qbot_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/doozr/guac"
+	. "github.com/doozr/qbot"
+	"github.com/doozr/qbot/activity"
+	"github.com/doozr/qbot/queue"
+)
+
+func TestActivityMessageHandlerRemembersWhoSpoke(t *testing.T) {
+	now := time.Date(2017, 3, 14, 12, 0, 0, 0, time.UTC)
+	fn := func(qs queue.Channels, m guac.MessageEvent) (queue.Channels, error) {
+		return qs, nil
+	}
+
+	a := activity.New()
+	handler := CreateActivityMessageHandler(fn, a, func() time.Time { return now })
+	handler(queue.Channels{}, makeTestEvent("just chatting"))
+
+	if !a.LastSeen("C1234", "U1234").Equal(now) {
+		t.Fatal("Expected user to be seen, got ", a.LastSeen("C1234", "U1234"))
+	}
+}
+
+func TestActivityMessageHandlerIgnoresDirectMessages(t *testing.T) {
+	fn := func(qs queue.Channels, m guac.MessageEvent) (queue.Channels, error) {
+		return qs, nil
+	}
+
+	a := activity.New()
+	handler := CreateActivityMessageHandler(fn, a, time.Now)
+	m := makeTestEvent("list")
+	m.Channel = "D1234"
+	handler(queue.Channels{}, m)
+
+	if !a.LastSeen("D1234", "U1234").IsZero() {
+		t.Fatal("Expected user not to be seen, got ", a.LastSeen("D1234", "U1234"))
+	}
+}
+
+func TestActivityMessageHandlerDoesNotRememberOnError(t *testing.T) {
+	fn := func(qs queue.Channels, m guac.MessageEvent) (queue.Channels, error) {
+		return nil, fmt.Errorf("Error!")
+	}
+
+	a := activity.New()
+	handler := CreateActivityMessageHandler(fn, a, time.Now)
+	_, err := handler(queue.Channels{}, makeTestEvent("join Tomato"))
+
+	if err == nil {
+		t.Fatal("Expected error")
+	}
+
+	if !a.LastSeen("C1234", "U1234").IsZero() {
+		t.Fatal("Expected user not to be seen, got ", a.LastSeen("C1234", "U1234"))
+	}
+}
@@ -18,15 +18,15 @@ func CreateMessageDirector(id string, name string, publicHandler MessageHandler,
 		return strings.HasPrefix(channel, "D")
 	}
 
-	return func(oq queue.Queue, m guac.MessageEvent) (q queue.Queue, err error) {
-		q = oq
+	return func(oqs queue.Channels, m guac.MessageEvent) (qs queue.Channels, err error) {
+		qs = oqs
 		if isPrivateChannel(m.Channel) {
 			// Private channels should never cause state change
-			_, err = privateHandler(q, m)
+			_, err = privateHandler(qs, m)
 		} else if isDirectedAtUs(m.Text) {
 			// Public channels can cause state change
 			_, m.Text = util.StringPop(m.Text)
-			q, err = publicHandler(q, m)
+			qs, err = publicHandler(qs, m)
 		}
 		return
 	}
@@ -22,18 +22,18 @@ func getTestMessageEvent(user, channel, text string) guac.MessageEvent {
 
 func TestPrivateMessageIsRouted(t *testing.T) {
 	var received guac.MessageEvent
-	privateHandler := func(q queue.Queue, m guac.MessageEvent) (queue.Queue, error) {
+	privateHandler := func(qs queue.Channels, m guac.MessageEvent) (queue.Channels, error) {
 		received = m
-		return q, nil
+		return qs, nil
 	}
-	publicHandler := func(q queue.Queue, m guac.MessageEvent) (queue.Queue, error) {
+	publicHandler := func(qs queue.Channels, m guac.MessageEvent) (queue.Channels, error) {
 		t.Fatal("Unexpected call to public handler")
-		return q, nil
+		return qs, nil
 	}
 
 	event := getTestMessageEvent("U4321", "D1A2B3C", "This is a message")
 	director := CreateMessageDirector("U123", "myname", publicHandler, privateHandler)
-	director(queue.Queue{}, event)
+	director(queue.Channels{}, event)
 
 	if !reflect.DeepEqual(event, received) {
 		t.Fatal("Event does not match ", event, received)
@@ -41,13 +41,13 @@ func TestPrivateMessageIsRouted(t *testing.T) {
 }
 
 func TestPrivateMessageDoesNotGetNewQueue(t *testing.T) {
-	var expected = queue.Queue{}
-	privateHandler := func(q queue.Queue, m guac.MessageEvent) (queue.Queue, error) {
-		return queue.Queue([]queue.Item{{ID: "U123", Reason: "Tomato"}}), nil
+	var expected = queue.Channels{}
+	privateHandler := func(qs queue.Channels, m guac.MessageEvent) (queue.Channels, error) {
+		return queue.Channels{"C1A2B3C": queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{{ID: "U123", Reason: "Tomato"}}}}}, nil
 	}
-	publicHandler := func(q queue.Queue, m guac.MessageEvent) (queue.Queue, error) {
+	publicHandler := func(qs queue.Channels, m guac.MessageEvent) (queue.Channels, error) {
 		t.Fatal("Unexpected call to public handler")
-		return q, nil
+		return qs, nil
 	}
 
 	event := getTestMessageEvent("U4321", "D1A2B3C", "This is a message")
@@ -60,17 +60,17 @@ func TestPrivateMessageDoesNotGetNewQueue(t *testing.T) {
 }
 
 func TestErrorReturnedWhenPrivateMessageFails(t *testing.T) {
-	privateHandler := func(q queue.Queue, m guac.MessageEvent) (queue.Queue, error) {
-		return q, fmt.Errorf("Error!")
+	privateHandler := func(qs queue.Channels, m guac.MessageEvent) (queue.Channels, error) {
+		return qs, fmt.Errorf("Error!")
 	}
-	publicHandler := func(q queue.Queue, m guac.MessageEvent) (queue.Queue, error) {
+	publicHandler := func(qs queue.Channels, m guac.MessageEvent) (queue.Channels, error) {
 		t.Fatal("Unexpected call to public handler")
-		return q, nil
+		return qs, nil
 	}
 
 	event := getTestMessageEvent("U4321", "D1A2B3C", "This is a message")
 	director := CreateMessageDirector("U123", "myname", publicHandler, privateHandler)
-	_, err := director(queue.Queue{}, event)
+	_, err := director(queue.Channels{}, event)
 	if err == nil {
 		t.Fatal("Expected error")
 	}
@@ -78,18 +78,18 @@ func TestErrorReturnedWhenPrivateMessageFails(t *testing.T) {
 
 func TestPublicMessageWithNameIsRouted(t *testing.T) {
 	var received guac.MessageEvent
-	privateHandler := func(q queue.Queue, m guac.MessageEvent) (queue.Queue, error) {
+	privateHandler := func(qs queue.Channels, m guac.MessageEvent) (queue.Channels, error) {
 		t.Fatal("Unexpected call to private handler")
-		return q, nil
+		return qs, nil
 	}
-	publicHandler := func(q queue.Queue, m guac.MessageEvent) (queue.Queue, error) {
+	publicHandler := func(qs queue.Channels, m guac.MessageEvent) (queue.Channels, error) {
 		received = m
-		return q, nil
+		return qs, nil
 	}
 
 	event := getTestMessageEvent("U4321", "C1A2B3C", "myname: This is a message")
 	director := CreateMessageDirector("U123", "myname", publicHandler, privateHandler)
-	director(queue.Queue{}, event)
+	director(queue.Channels{}, event)
 
 	expected := getTestMessageEvent("U4321", "C1A2B3C", "This is a message")
 	if !reflect.DeepEqual(expected, received) {
@@ -99,18 +99,18 @@ func TestPublicMessageWithNameIsRouted(t *testing.T) {
 
 func TestPublicMessageWithIDIsRouted(t *testing.T) {
 	var received guac.MessageEvent
-	privateHandler := func(q queue.Queue, m guac.MessageEvent) (queue.Queue, error) {
+	privateHandler := func(qs queue.Channels, m guac.MessageEvent) (queue.Channels, error) {
 		t.Fatal("Unexpected call to private handler")
-		return q, nil
+		return qs, nil
 	}
-	publicHandler := func(q queue.Queue, m guac.MessageEvent) (queue.Queue, error) {
+	publicHandler := func(qs queue.Channels, m guac.MessageEvent) (queue.Channels, error) {
 		received = m
-		return q, nil
+		return qs, nil
 	}
 
 	event := getTestMessageEvent("U4321", "C1A2B3C", "<@U123> This is a message")
 	director := CreateMessageDirector("U123", "myname", publicHandler, privateHandler)
-	director(queue.Queue{}, event)
+	director(queue.Channels{}, event)
 
 	expected := getTestMessageEvent("U4321", "C1A2B3C", "This is a message")
 	if !reflect.DeepEqual(expected, received) {
@@ -119,18 +119,18 @@ func TestPublicMessageWithIDIsRouted(t *testing.T) {
 }
 
 func TestPublicMessageGetsNewQueue(t *testing.T) {
-	expected := queue.Queue([]queue.Item{{ID: "U123", Reason: "Tomato"}})
-	privateHandler := func(q queue.Queue, m guac.MessageEvent) (queue.Queue, error) {
+	expected := queue.Channels{"C1A2B3C": queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{{ID: "U123", Reason: "Tomato"}}}}}
+	privateHandler := func(qs queue.Channels, m guac.MessageEvent) (queue.Channels, error) {
 		t.Fatal("Unexpected call to private handler")
-		return q, nil
+		return qs, nil
 	}
-	publicHandler := func(q queue.Queue, m guac.MessageEvent) (queue.Queue, error) {
+	publicHandler := func(qs queue.Channels, m guac.MessageEvent) (queue.Channels, error) {
 		return expected, nil
 	}
 
 	event := getTestMessageEvent("U4321", "C1A2B3C", "<@U123> This is a message")
 	director := CreateMessageDirector("U123", "myname", publicHandler, privateHandler)
-	received, _ := director(queue.Queue{}, event)
+	received, _ := director(queue.Channels{}, event)
 
 	if !reflect.DeepEqual(expected, received) {
 		t.Fatal("Queue does not match ", event, received)
@@ -138,53 +138,53 @@ func TestPublicMessageGetsNewQueue(t *testing.T) {
 }
 
 func TestErrorReturnedIfPublicMessageFailed(t *testing.T) {
-	privateHandler := func(q queue.Queue, m guac.MessageEvent) (queue.Queue, error) {
+	privateHandler := func(qs queue.Channels, m guac.MessageEvent) (queue.Channels, error) {
 		t.Fatal("Unexpected call to private handler")
-		return q, nil
+		return qs, nil
 	}
-	publicHandler := func(q queue.Queue, m guac.MessageEvent) (queue.Queue, error) {
-		return q, fmt.Errorf("Error!")
+	publicHandler := func(qs queue.Channels, m guac.MessageEvent) (queue.Channels, error) {
+		return qs, fmt.Errorf("Error!")
 	}
 
 	event := getTestMessageEvent("U4321", "C1A2B3C", "<@U123> This is a message")
 	director := CreateMessageDirector("U123", "myname", publicHandler, privateHandler)
-	_, err := director(queue.Queue{}, event)
+	_, err := director(queue.Channels{}, event)
 	if err == nil {
 		t.Fatal("Expected error ", err)
 	}
 }
 
 func TestPublicMessageWithoutNameOrIDIsNotRouted(t *testing.T) {
-	privateHandler := func(q queue.Queue, m guac.MessageEvent) (queue.Queue, error) {
+	privateHandler := func(qs queue.Channels, m guac.MessageEvent) (queue.Channels, error) {
 		t.Fatal("Unexpected call to private handler")
-		return q, nil
+		return qs, nil
 	}
-	publicHandler := func(q queue.Queue, m guac.MessageEvent) (queue.Queue, error) {
+	publicHandler := func(qs queue.Channels, m guac.MessageEvent) (queue.Channels, error) {
 		t.Fatal("Unexpected call to public handler")
-		return q, nil
+		return qs, nil
 	}
 
 	event := getTestMessageEvent("U4321", "C1A2B3C", "This is a message")
 	director := CreateMessageDirector("U123", "myname", publicHandler, privateHandler)
-	director(queue.Queue{}, event)
+	director(queue.Channels{}, event)
 }
 
 func TestPublicMessageWithoutNameOrIDReturnsSameQueue(t *testing.T) {
-	q := queue.Queue([]queue.Item{{ID: "U123", Reason: "Tomato"}})
-	privateHandler := func(q queue.Queue, m guac.MessageEvent) (queue.Queue, error) {
+	qs := queue.Channels{"C1A2B3C": queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{{ID: "U123", Reason: "Tomato"}}}}}
+	privateHandler := func(qs queue.Channels, m guac.MessageEvent) (queue.Channels, error) {
 		t.Fatal("Unexpected call to private handler")
-		return q, nil
+		return qs, nil
 	}
-	publicHandler := func(q queue.Queue, m guac.MessageEvent) (queue.Queue, error) {
+	publicHandler := func(qs queue.Channels, m guac.MessageEvent) (queue.Channels, error) {
 		t.Fatal("Unexpected call to public handler")
-		return q, nil
+		return qs, nil
 	}
 
 	event := getTestMessageEvent("U4321", "C1A2B3C", "This is a message")
 	director := CreateMessageDirector("U123", "myname", publicHandler, privateHandler)
-	received, _ := director(q, event)
+	received, _ := director(qs, event)
 
-	if !q.Equal(received) {
-		t.Fatal("Unexpected queue", q, received)
+	if !qs.Equal(received) {
+		t.Fatal("Unexpected queue", qs, received)
 	}
 }
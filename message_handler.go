@@ -1,6 +1,7 @@
 package qbot
 
 import (
+	"sort"
 	"strings"
 
 	"github.com/doozr/guac"
@@ -11,34 +12,78 @@ import (
 )
 
 // MessageHandler handles an incoming message event.
-type MessageHandler func(queue.Queue, guac.MessageEvent) (queue.Queue, error)
+type MessageHandler func(queue.Channels, guac.MessageEvent) (queue.Channels, error)
 
 // CommandMap is a dictionary of command strings to functions.
-type CommandMap map[string]command.Command
+type CommandMap map[string]command.ChannelCommand
 
-// CreateMessageHandler creates a message handler that calls a command function.
-func CreateMessageHandler(commands CommandMap, notify Notifier) MessageHandler {
-	return func(oq queue.Queue, m guac.MessageEvent) (q queue.Queue, err error) {
-		text := strings.Trim(m.Text, " \t\r\n")
+// PrivateCommandMap is a dictionary of command strings to read-only functions.
+type PrivateCommandMap map[string]command.PrivateCommand
+
+// Names lists the commands in alphabetical order.
+func (c CommandMap) Names() []string {
+	names := []string{}
+	for name := range c {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Names lists the commands in alphabetical order.
+func (c PrivateCommandMap) Names() []string {
+	names := []string{}
+	for name := range c {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
 
-		var response command.Notification
+func parseCommand(m guac.MessageEvent) (cmd, args string) {
+	text := strings.Trim(m.Text, " \t\r\n")
+	cmd, args = util.StringPop(text)
+	cmd = strings.ToLower(cmd)
 
-		cmd, args := util.StringPop(text)
-		cmd = strings.ToLower(cmd)
+	jot.Printf("message dispatch: message %s with cmd %s and args %v", m.Text, cmd, args)
+	return
+}
+
+// CreateMessageHandler creates a message handler that calls a command function on the tokens for the message channel.
+func CreateMessageHandler(commands CommandMap, notify Notifier) MessageHandler {
+	return func(oqs queue.Channels, m guac.MessageEvent) (qs queue.Channels, err error) {
+		qs = oqs
 
-		jot.Printf("message dispatch: message %s with cmd %s and args %v", m.Text, cmd, args)
+		cmd, args := parseCommand(m)
 		fn, ok := commands[cmd]
 		if !ok {
 			fn, ok = commands["help"]
 			if !ok {
-				q = oq
 				return
 			}
 		}
 
-		q, response = fn(oq, m.Channel, m.User, args)
+		t, response := fn(oqs.Get(m.Channel), m.Channel, m.User, args)
+		qs = oqs.Set(m.Channel, t)
 
 		err = notify(response)
 		return
 	}
 }
+
+// CreatePrivateMessageHandler creates a message handler that calls a read-only command function with every queue.
+func CreatePrivateMessageHandler(commands PrivateCommandMap, notify Notifier) MessageHandler {
+	return func(qs queue.Channels, m guac.MessageEvent) (queue.Channels, error) {
+		cmd, args := parseCommand(m)
+		fn, ok := commands[cmd]
+		if !ok {
+			fn, ok = commands["help"]
+			if !ok {
+				return qs, nil
+			}
+		}
+
+		response := fn(qs, m.Channel, m.User, args)
+		return qs, notify(response)
+	}
+}
@@ -22,14 +22,14 @@ func makeTestEvent(text string) guac.MessageEvent {
 }
 
 func TestDispatchesMessage(t *testing.T) {
-	initialQueue := queue.Queue{}
+	initialQueue := queue.Channels{}
 	event := makeTestEvent("test the args")
 
-	commands := map[string]command.Command{
-		"test": func(q queue.Queue, channel string, user string, args string) (queue.Queue, command.Notification) {
-			q = q.Add(queue.Item{ID: user, Reason: args})
+	commands := map[string]command.ChannelCommand{
+		"test": func(tokens queue.Tokens, channel string, user string, args string) (queue.Tokens, command.Notification) {
+			q := tokens.Get(queue.DefaultToken).Add(queue.Item{ID: user, Reason: args})
 			n := command.Notification{Channel: channel, Message: "This is a message"}
-			return q, n
+			return tokens.Set(queue.DefaultToken, q), n
 		},
 	}
 
@@ -53,23 +53,23 @@ func TestDispatchesMessage(t *testing.T) {
 		t.Fatal("Received unexpected notification ", expectedNotification, receivedNotification)
 	}
 
-	expectedQueue := queue.Queue([]queue.Item{
+	expectedQueue := queue.Channels{"C1234": queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{
 		{ID: "U1234", Reason: "the args"},
-	})
+	}}}}
 	if !receivedQueue.Equal(expectedQueue) {
 		t.Fatal("Received unexpected queue", expectedQueue, receivedQueue)
 	}
 }
 
 func TestDispatchCaseInsensitive(t *testing.T) {
-	initialQueue := queue.Queue{}
+	initialQueue := queue.Channels{}
 	event := makeTestEvent("TEST UPPER CASE")
 
 	calls := 0
-	commands := map[string]command.Command{
-		"test": func(q queue.Queue, channel string, user string, args string) (queue.Queue, command.Notification) {
+	commands := map[string]command.ChannelCommand{
+		"test": func(tokens queue.Tokens, channel string, user string, args string) (queue.Tokens, command.Notification) {
 			calls++
-			return q, command.Notification{Channel: channel, Message: "response"}
+			return tokens, command.Notification{Channel: channel, Message: "response"}
 		},
 	}
 
@@ -86,13 +86,13 @@ func TestDispatchCaseInsensitive(t *testing.T) {
 }
 
 func TestDoesNothingIfNoMatchingCommand(t *testing.T) {
-	initialQueue := queue.Queue([]queue.Item{{ID: "U123", Reason: "Tomato"}})
+	initialQueue := queue.Channels{"C1234": queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{{ID: "U123", Reason: "Tomato"}}}}}
 	event := makeTestEvent("NOT FOUND")
 
-	commands := map[string]command.Command{
-		"test": func(q queue.Queue, channel string, user string, args string) (queue.Queue, command.Notification) {
+	commands := map[string]command.ChannelCommand{
+		"test": func(tokens queue.Tokens, channel string, user string, args string) (queue.Tokens, command.Notification) {
 			t.Fatal("Unexpected call to command")
-			return q, command.Notification{}
+			return tokens, command.Notification{}
 		},
 	}
 
@@ -110,12 +110,12 @@ func TestDoesNothingIfNoMatchingCommand(t *testing.T) {
 }
 
 func TestReturnsErrorIfNotifyFails(t *testing.T) {
-	initialQueue := queue.Queue{}
+	initialQueue := queue.Channels{}
 	event := makeTestEvent("test with errors")
 
-	commands := map[string]command.Command{
-		"test": func(q queue.Queue, channel string, user string, args string) (queue.Queue, command.Notification) {
-			return q, command.Notification{Channel: channel, Message: "response"}
+	commands := map[string]command.ChannelCommand{
+		"test": func(tokens queue.Tokens, channel string, user string, args string) (queue.Tokens, command.Notification) {
+			return tokens, command.Notification{Channel: channel, Message: "response"}
 		},
 	}
 
@@ -129,3 +129,77 @@ func TestReturnsErrorIfNotifyFails(t *testing.T) {
 		t.Fatal("Expected error")
 	}
 }
+
+func TestOnlyChangesQueueForMessageChannel(t *testing.T) {
+	initialQueue := queue.Channels{
+		"C1234": queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{{ID: "U123", Reason: "Tomato"}}}},
+		"C5678": queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{{ID: "U456", Reason: "Potato"}}}},
+	}
+	event := makeTestEvent("test the args")
+
+	commands := map[string]command.ChannelCommand{
+		"test": func(tokens queue.Tokens, channel string, user string, args string) (queue.Tokens, command.Notification) {
+			q := tokens.Get(queue.DefaultToken).Add(queue.Item{ID: user, Reason: args})
+			return tokens.Set(queue.DefaultToken, q), command.Notification{}
+		},
+	}
+
+	notify := func(n command.Notification) error {
+		return nil
+	}
+
+	handler := CreateMessageHandler(commands, notify)
+	receivedQueue, _ := handler(initialQueue, event)
+
+	expectedQueue := queue.Channels{
+		"C1234": queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{{ID: "U123", Reason: "Tomato"}, {ID: "U1234", Reason: "the args"}}}},
+		"C5678": queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{{ID: "U456", Reason: "Potato"}}}},
+	}
+	if !receivedQueue.Equal(expectedQueue) {
+		t.Fatal("Received unexpected queue", expectedQueue, receivedQueue)
+	}
+}
+
+func TestPrivateHandlerSeesEveryQueue(t *testing.T) {
+	initialQueue := queue.Channels{
+		"C1234": queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{{ID: "U123", Reason: "Tomato"}}}},
+		"C5678": queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{{ID: "U456", Reason: "Potato"}}}},
+	}
+	event := makeTestEvent("test the args")
+
+	var receivedQueue queue.Channels
+	commands := map[string]command.PrivateCommand{
+		"test": func(qs queue.Channels, channel string, user string, args string) command.Notification {
+			receivedQueue = qs
+			return command.Notification{Channel: channel, Message: "This is a message"}
+		},
+	}
+
+	var receivedNotification command.Notification
+	notify := func(n command.Notification) error {
+		receivedNotification = n
+		return nil
+	}
+
+	handler := CreatePrivateMessageHandler(commands, notify)
+	returnedQueue, err := handler(initialQueue, event)
+	if err != nil {
+		t.Fatal("Unexpected error ", err)
+	}
+
+	if !receivedQueue.Equal(initialQueue) {
+		t.Fatal("Command received unexpected queue", initialQueue, receivedQueue)
+	}
+
+	if !returnedQueue.Equal(initialQueue) {
+		t.Fatal("Handler returned unexpected queue", initialQueue, returnedQueue)
+	}
+
+	expectedNotification := command.Notification{
+		Channel: "C1234",
+		Message: "This is a message",
+	}
+	if !reflect.DeepEqual(expectedNotification, receivedNotification) {
+		t.Fatal("Received unexpected notification ", expectedNotification, receivedNotification)
+	}
+}
@@ -0,0 +1,22 @@
+package qbot
+
+import (
+	"github.com/doozr/guac"
+	"github.com/doozr/qbot/journal"
+	"github.com/doozr/qbot/queue"
+)
+
+// CreateJournaledMessageHandler creates a message handler that calls another and records what changed, who changed
+// it and with which command.
+func CreateJournaledMessageHandler(fn MessageHandler, record Journal, now Clock) MessageHandler {
+	return func(oqs queue.Channels, m guac.MessageEvent) (qs queue.Channels, err error) {
+		qs, err = fn(oqs, m)
+		if err != nil {
+			return
+		}
+
+		cmd, _ := parseCommand(m)
+		err = record(journal.Diff(oqs, qs, m.User, cmd, now()))
+		return
+	}
+}
@@ -0,0 +1,72 @@
+package qbot_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/doozr/guac"
+
+	. "github.com/doozr/qbot"
+	"github.com/doozr/qbot/journal"
+	"github.com/doozr/qbot/queue"
+)
+
+func TestJournaledMessageHandlerRecordsChanges(t *testing.T) {
+	now := time.Date(2017, 3, 14, 12, 0, 0, 0, time.UTC)
+	before := queue.Channels{}
+	after := queue.Channels{"C1234": queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{{ID: "U1234", Reason: "Tomato"}}}}}
+
+	fn := func(qs queue.Channels, m guac.MessageEvent) (queue.Channels, error) {
+		return after, nil
+	}
+
+	var recorded []journal.Event
+	record := func(events []journal.Event) error {
+		recorded = events
+		return nil
+	}
+
+	handler := CreateJournaledMessageHandler(fn, record, func() time.Time { return now })
+	qs, _ := handler(before, makeTestEvent("JOIN Tomato"))
+
+	if !after.Equal(qs) {
+		t.Fatal("Unexpected queue returned", after, qs)
+	}
+
+	if len(recorded) != 1 {
+		t.Fatal("Expected 1 event, got ", recorded)
+	}
+
+	e := recorded[0]
+	if e.Actor != "U1234" || e.Command != "join" || !e.Time.Equal(now) || e.After != 1 {
+		t.Fatal("Unexpected event", e)
+	}
+
+	if !after.Equal(journal.Replay(before, recorded)) {
+		t.Fatal("Replayed events do not match queue", recorded)
+	}
+}
+
+func TestJournaledMessageHandlerDoesNotRecordOnError(t *testing.T) {
+	fn := func(qs queue.Channels, m guac.MessageEvent) (queue.Channels, error) {
+		return nil, fmt.Errorf("Error!")
+	}
+
+	calls := 0
+	record := func(events []journal.Event) error {
+		calls++
+		return nil
+	}
+
+	handler := CreateJournaledMessageHandler(fn, record, time.Now)
+	_, err := handler(queue.Channels{}, makeTestEvent("join Tomato"))
+
+	if err == nil {
+		t.Fatal("Expected error")
+	}
+
+	if calls != 0 {
+		t.Fatal("Expected no calls to record, got ", calls)
+	}
+}
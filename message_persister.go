@@ -7,13 +7,13 @@ import (
 
 // CreatePersistedMessageHandler creates a message handler that call another and persists the result
 func CreatePersistedMessageHandler(fn MessageHandler, persist Persister) MessageHandler {
-	return func(oq queue.Queue, m guac.MessageEvent) (q queue.Queue, err error) {
-		q, err = fn(oq, m)
+	return func(oqs queue.Channels, m guac.MessageEvent) (qs queue.Channels, err error) {
+		qs, err = fn(oqs, m)
 		if err != nil {
 			return
 		}
 
-		err = persist(q)
+		err = persist(qs)
 		return
 	}
 }
@@ -11,20 +11,20 @@ import (
 )
 
 func TestPassesOnParameters(t *testing.T) {
-	var receivedQueue queue.Queue
+	var receivedQueue queue.Channels
 	var receivedEvent guac.MessageEvent
-	fn := func(q queue.Queue, m guac.MessageEvent) (queue.Queue, error) {
-		receivedQueue = q
+	fn := func(qs queue.Channels, m guac.MessageEvent) (queue.Channels, error) {
+		receivedQueue = qs
 		receivedEvent = m
-		return q, nil
+		return qs, nil
 	}
 
-	persist := func(q queue.Queue) error {
+	persist := func(qs queue.Channels) error {
 		return nil
 	}
 
 	event := makeTestEvent("text")
-	expectedQueue := queue.Queue([]queue.Item{{ID: "U123", Reason: "Tomato"}})
+	expectedQueue := queue.Channels{"C1A2B3C": queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{{ID: "U123", Reason: "Tomato"}}}}}
 
 	handler := CreatePersistedMessageHandler(fn, persist)
 	handler(expectedQueue, event)
@@ -39,22 +39,22 @@ func TestPassesOnParameters(t *testing.T) {
 }
 
 func TestPersistsReturnedQueue(t *testing.T) {
-	expectedQueue := queue.Queue([]queue.Item{{ID: "U123", Reason: "Tomato"}})
+	expectedQueue := queue.Channels{"C1A2B3C": queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{{ID: "U123", Reason: "Tomato"}}}}}
 
-	fn := func(q queue.Queue, m guac.MessageEvent) (queue.Queue, error) {
+	fn := func(qs queue.Channels, m guac.MessageEvent) (queue.Channels, error) {
 		return expectedQueue, nil
 	}
 
-	var receivedQueue queue.Queue
-	persist := func(q queue.Queue) error {
-		receivedQueue = q
+	var receivedQueue queue.Channels
+	persist := func(qs queue.Channels) error {
+		receivedQueue = qs
 		return nil
 	}
 
 	event := makeTestEvent("text")
 
 	handler := CreatePersistedMessageHandler(fn, persist)
-	handler(queue.Queue{}, event)
+	handler(queue.Channels{}, event)
 
 	if !expectedQueue.Equal(receivedQueue) {
 		t.Fatal("Unexpected qeueu", expectedQueue, receivedQueue)
@@ -62,20 +62,20 @@ func TestPersistsReturnedQueue(t *testing.T) {
 }
 
 func TestReturnsReturnedQueue(t *testing.T) {
-	expectedQueue := queue.Queue([]queue.Item{{ID: "U123", Reason: "Tomato"}})
+	expectedQueue := queue.Channels{"C1A2B3C": queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{{ID: "U123", Reason: "Tomato"}}}}}
 
-	fn := func(q queue.Queue, m guac.MessageEvent) (queue.Queue, error) {
+	fn := func(qs queue.Channels, m guac.MessageEvent) (queue.Channels, error) {
 		return expectedQueue, nil
 	}
 
-	persist := func(q queue.Queue) error {
+	persist := func(qs queue.Channels) error {
 		return nil
 	}
 
 	event := makeTestEvent("text")
 
 	handler := CreatePersistedMessageHandler(fn, persist)
-	receivedQueue, _ := handler(queue.Queue{}, event)
+	receivedQueue, _ := handler(queue.Channels{}, event)
 
 	if !expectedQueue.Equal(receivedQueue) {
 		t.Fatal("Unexpected qeueu", expectedQueue, receivedQueue)
@@ -83,12 +83,12 @@ func TestReturnsReturnedQueue(t *testing.T) {
 }
 
 func TestDoesNotPersistOnError(t *testing.T) {
-	fn := func(q queue.Queue, m guac.MessageEvent) (queue.Queue, error) {
+	fn := func(qs queue.Channels, m guac.MessageEvent) (queue.Channels, error) {
 		return nil, fmt.Errorf("Error!")
 	}
 
 	calls := 0
-	persist := func(q queue.Queue) error {
+	persist := func(qs queue.Channels) error {
 		calls++
 		return nil
 	}
@@ -96,7 +96,7 @@ func TestDoesNotPersistOnError(t *testing.T) {
 	event := makeTestEvent("text")
 
 	handler := CreatePersistedMessageHandler(fn, persist)
-	_, err := handler(queue.Queue{}, event)
+	_, err := handler(queue.Channels{}, event)
 
 	if calls != 0 {
 		t.Fatal("Expected 0 calls, received ", calls)
@@ -108,18 +108,18 @@ func TestDoesNotPersistOnError(t *testing.T) {
 }
 
 func TestReturnsPersistError(t *testing.T) {
-	fn := func(q queue.Queue, m guac.MessageEvent) (queue.Queue, error) {
-		return q, nil
+	fn := func(qs queue.Channels, m guac.MessageEvent) (queue.Channels, error) {
+		return qs, nil
 	}
 
-	persist := func(q queue.Queue) error {
+	persist := func(qs queue.Channels) error {
 		return fmt.Errorf("Error!")
 	}
 
 	event := makeTestEvent("text")
 
 	handler := CreatePersistedMessageHandler(fn, persist)
-	_, err := handler(queue.Queue{}, event)
+	_, err := handler(queue.Channels{}, event)
 
 	if err == nil {
 		t.Fatal("Expected error")
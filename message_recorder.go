@@ -0,0 +1,37 @@
+package qbot
+
+import (
+	"strings"
+
+	"github.com/doozr/guac"
+	"github.com/doozr/qbot/history"
+	"github.com/doozr/qbot/queue"
+)
+
+// CreateRecordedMessageHandler creates a message handler that calls another and remembers any change it made to the
+// tokens of the channel so that it can be undone.
+//
+// Undoing a change is not itself remembered, so undoing again undoes the change before.
+func CreateRecordedMessageHandler(fn MessageHandler, h history.History, now Clock) MessageHandler {
+	return func(oqs queue.Channels, m guac.MessageEvent) (qs queue.Channels, err error) {
+		qs, err = fn(oqs, m)
+		if err != nil {
+			return
+		}
+
+		cmd, _ := parseCommand(m)
+		before, after := oqs.Get(m.Channel), qs.Get(m.Channel)
+		if cmd == "undo" || before.Equal(after) {
+			return
+		}
+
+		h.Record(m.Channel, history.Change{
+			Actor:   m.User,
+			Command: strings.Trim(m.Text, " \t\r\n"),
+			Time:    now(),
+			Before:  before,
+			After:   after,
+		})
+		return
+	}
+}
@@ -0,0 +1,83 @@
+package qbot_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/doozr/guac"
+	. "github.com/doozr/qbot"
+	"github.com/doozr/qbot/history"
+	"github.com/doozr/qbot/queue"
+)
+
+func TestRecordsChangeToChannel(t *testing.T) {
+	now := time.Date(2017, 3, 14, 12, 0, 0, 0, time.UTC)
+	after := queue.Channels{"C1234": queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{{ID: "U1234", Reason: "Tomato"}}}}}
+
+	fn := func(qs queue.Channels, m guac.MessageEvent) (queue.Channels, error) {
+		return after, nil
+	}
+
+	h := history.New(10)
+	handler := CreateRecordedMessageHandler(fn, h, func() time.Time { return now })
+	handler(queue.Channels{}, makeTestEvent(" join Tomato "))
+
+	changes := h.Changes("C1234")
+	if len(changes) != 1 {
+		t.Fatal("Expected 1 change, got ", changes)
+	}
+
+	c := changes[0]
+	if c.Actor != "U1234" || c.Command != "join Tomato" || !c.Time.Equal(now) ||
+		!c.Before.Equal(queue.Tokens{}) || !c.After.Equal(after.Get("C1234")) {
+		t.Fatal("Unexpected change ", c)
+	}
+}
+
+func TestDoesNotRecordWhenNothingChanges(t *testing.T) {
+	fn := func(qs queue.Channels, m guac.MessageEvent) (queue.Channels, error) {
+		return qs, nil
+	}
+
+	h := history.New(10)
+	handler := CreateRecordedMessageHandler(fn, h, time.Now)
+	handler(queue.Channels{}, makeTestEvent("list"))
+
+	if len(h.Changes("C1234")) != 0 {
+		t.Fatal("Expected no changes, got ", h.Changes("C1234"))
+	}
+}
+
+func TestDoesNotRecordUndo(t *testing.T) {
+	after := queue.Channels{"C1234": queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{{ID: "U1234", Reason: "Tomato"}}}}}
+	fn := func(qs queue.Channels, m guac.MessageEvent) (queue.Channels, error) {
+		return after, nil
+	}
+
+	h := history.New(10)
+	handler := CreateRecordedMessageHandler(fn, h, time.Now)
+	handler(queue.Channels{}, makeTestEvent("undo"))
+
+	if len(h.Changes("C1234")) != 0 {
+		t.Fatal("Expected no changes, got ", h.Changes("C1234"))
+	}
+}
+
+func TestDoesNotRecordOnError(t *testing.T) {
+	fn := func(qs queue.Channels, m guac.MessageEvent) (queue.Channels, error) {
+		return nil, fmt.Errorf("Error!")
+	}
+
+	h := history.New(10)
+	handler := CreateRecordedMessageHandler(fn, h, time.Now)
+	_, err := handler(queue.Channels{}, makeTestEvent("join Tomato"))
+
+	if err == nil {
+		t.Fatal("Expected error")
+	}
+
+	if len(h.Changes("C1234")) != 0 {
+		t.Fatal("Expected no changes, got ", h.Changes("C1234"))
+	}
+}
@@ -0,0 +1,62 @@
+package qbot
+
+import (
+	"strings"
+
+	"github.com/doozr/guac"
+	"github.com/doozr/qbot/command"
+	"github.com/doozr/qbot/queue"
+	"github.com/doozr/qbot/util"
+)
+
+// Suggester tells a user that a command is not known, along with the known command they might have meant if any.
+type Suggester func(channel, user, cmd, suggestion string) command.Notification
+
+// suggest finds the known command closest to an unknown one, if any is close enough to be a typo.
+func suggest(names []string, cmd string) (suggestion string, ok bool) {
+	best := 2
+	if len(cmd) <= 4 {
+		best = 1
+	}
+
+	for _, name := range names {
+		if d := util.Distance(cmd, name); d <= best && (!ok || d < best) {
+			suggestion, ok, best = name, true, d
+		}
+	}
+	return
+}
+
+// CreateSuggestingMessageHandler creates a message handler that answers unknown commands with the closest known
+// command instead of passing them on, and passes the suggested command on if the user then says `yes`.
+//
+// Saying anything else in the same channel forgets the suggestion. Messages without a command are passed on as usual.
+func CreateSuggestingMessageHandler(fn MessageHandler, names []string, unknown Suggester, notify Notifier) MessageHandler {
+	known := map[string]bool{}
+	for _, name := range names {
+		known[name] = true
+	}
+	pending := map[string]string{}
+
+	return func(qs queue.Channels, m guac.MessageEvent) (queue.Channels, error) {
+		cmd, args := parseCommand(m)
+		key := m.Channel + ":" + m.User
+
+		suggested, ok := pending[key]
+		delete(pending, key)
+		if ok && cmd == "yes" && args == "" {
+			m.Text = suggested
+			return fn(qs, m)
+		}
+
+		if cmd == "" || known[cmd] {
+			return fn(qs, m)
+		}
+
+		suggestion, ok := suggest(names, cmd)
+		if ok {
+			pending[key] = strings.TrimSpace(suggestion + " " + args)
+		}
+		return qs, notify(unknown(m.Channel, m.User, cmd, suggestion))
+	}
+}
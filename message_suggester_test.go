@@ -0,0 +1,119 @@
+package qbot_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/doozr/guac"
+	. "github.com/doozr/qbot"
+	"github.com/doozr/qbot/command"
+	"github.com/doozr/qbot/queue"
+)
+
+func createTestSuggester(received *[]string) (MessageHandler, *[]command.Notification) {
+	notifications := &[]command.Notification{}
+	fn := func(qs queue.Channels, m guac.MessageEvent) (queue.Channels, error) {
+		*received = append(*received, m.Text)
+		return qs, nil
+	}
+	unknown := func(ch, id, cmd, suggestion string) command.Notification {
+		return command.Notification{Channel: ch, Message: fmt.Sprintf("%s %s %s", id, cmd, suggestion)}
+	}
+	notify := func(n command.Notification) error {
+		*notifications = append(*notifications, n)
+		return nil
+	}
+	names := CommandMap{"done": nil, "join": nil, "leave": nil, "list": nil, "help": nil}.Names()
+	return CreateSuggestingMessageHandler(fn, names, unknown, notify), notifications
+}
+
+func TestSuggesterPassesOnKnownCommands(t *testing.T) {
+	received := []string{}
+	handler, notifications := createTestSuggester(&received)
+
+	handler(queue.Channels{}, makeTestEvent("JOIN the queue"))
+	handler(queue.Channels{}, makeTestEvent(""))
+
+	if len(received) != 2 || received[0] != "JOIN the queue" || received[1] != "" {
+		t.Fatal("Expected commands to be passed on, got ", received)
+	}
+	if len(*notifications) != 0 {
+		t.Fatal("Expected no notifications, got ", *notifications)
+	}
+}
+
+func TestSuggesterSuggestsClosestCommand(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{"dne", "U1234 dne done"},
+		{"jion some reason", "U1234 jion join"},
+		{"lsit", "U1234 lsit list"},
+		{"lst", "U1234 lst list"},
+		{"banana", "U1234 banana "},
+		{"xyz", "U1234 xyz "},
+	}
+
+	for _, tt := range tests {
+		received := []string{}
+		handler, notifications := createTestSuggester(&received)
+		handler(queue.Channels{}, makeTestEvent(tt.text))
+
+		if len(received) != 0 {
+			t.Fatal("Expected unknown command not to be passed on, got ", received)
+		}
+		if len(*notifications) != 1 || (*notifications)[0].Message != tt.expected || (*notifications)[0].Channel != "C1234" {
+			t.Fatalf("Expected suggestion '%s' for '%s', got %v", tt.expected, tt.text, *notifications)
+		}
+	}
+}
+
+func TestSuggesterRunsSuggestionOnYes(t *testing.T) {
+	received := []string{}
+	handler, _ := createTestSuggester(&received)
+
+	handler(queue.Channels{}, makeTestEvent("jion some reason"))
+	handler(queue.Channels{}, makeTestEvent("Yes"))
+
+	if len(received) != 1 || received[0] != "join some reason" {
+		t.Fatal("Expected suggestion to be passed on, got ", received)
+	}
+
+	handler(queue.Channels{}, makeTestEvent("yes"))
+	if len(received) != 1 {
+		t.Fatal("Expected suggestion to be run only once, got ", received)
+	}
+}
+
+func TestSuggesterForgetsSuggestion(t *testing.T) {
+	received := []string{}
+	handler, _ := createTestSuggester(&received)
+
+	handler(queue.Channels{}, makeTestEvent("dne"))
+	handler(queue.Channels{}, makeTestEvent("list"))
+	handler(queue.Channels{}, makeTestEvent("yes"))
+
+	if len(received) != 1 || received[0] != "list" {
+		t.Fatal("Expected suggestion to be forgotten, got ", received)
+	}
+}
+
+func TestSuggesterOnlyRunsSuggestionForSameUser(t *testing.T) {
+	received := []string{}
+	handler, _ := createTestSuggester(&received)
+
+	handler(queue.Channels{}, makeTestEvent("dne"))
+	other := makeTestEvent("yes")
+	other.User = "U5678"
+	handler(queue.Channels{}, other)
+
+	if len(received) != 0 {
+		t.Fatal("Expected suggestion not to be run for somebody else, got ", received)
+	}
+
+	handler(queue.Channels{}, makeTestEvent("yes"))
+	if len(received) != 1 || received[0] != "done" {
+		t.Fatal("Expected suggestion to be run, got ", received)
+	}
+}
@@ -0,0 +1,25 @@
+package qbot
+
+import (
+	"time"
+
+	"github.com/doozr/guac"
+	"github.com/doozr/qbot/queue"
+)
+
+// Clock is a thing that tells the time. See time.Now().
+type Clock func() time.Time
+
+// CreateTimestampedMessageHandler creates a message handler that calls another and records when entries joined the
+// queue or acquired the token.
+func CreateTimestampedMessageHandler(fn MessageHandler, now Clock) MessageHandler {
+	return func(oqs queue.Channels, m guac.MessageEvent) (qs queue.Channels, err error) {
+		qs, err = fn(oqs, m)
+		if err != nil {
+			return
+		}
+
+		qs = qs.Stamp(now())
+		return
+	}
+}
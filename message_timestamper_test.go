@@ -0,0 +1,56 @@
+package qbot_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/doozr/guac"
+	. "github.com/doozr/qbot"
+	"github.com/doozr/qbot/queue"
+)
+
+func TestStampsReturnedQueue(t *testing.T) {
+	joined := time.Date(2017, 3, 14, 9, 0, 0, 0, time.UTC)
+	now := time.Date(2017, 3, 14, 10, 30, 0, 0, time.UTC)
+
+	fn := func(qs queue.Channels, m guac.MessageEvent) (queue.Channels, error) {
+		return queue.Channels{"C1234": queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{
+			{ID: "U123", Reason: "Tomato", JoinedAt: joined},
+			{ID: "U456", Reason: "Potato"},
+		}}}}, nil
+	}
+	clock := func() time.Time {
+		return now
+	}
+
+	handler := CreateTimestampedMessageHandler(fn, clock)
+	receivedQueue, err := handler(queue.Channels{}, makeTestEvent("text"))
+	if err != nil {
+		t.Fatal("Unexpected error ", err)
+	}
+
+	expectedQueue := queue.Channels{"C1234": queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{
+		{ID: "U123", Reason: "Tomato", Key: "jox", JoinedAt: joined, ActiveSince: now},
+		{ID: "U456", Reason: "Potato", Key: "lqy", JoinedAt: now},
+	}}}}
+	if !expectedQueue.Equal(receivedQueue) {
+		t.Fatal("Unexpected queue", expectedQueue, receivedQueue)
+	}
+}
+
+func TestDoesNotStampOnError(t *testing.T) {
+	fn := func(qs queue.Channels, m guac.MessageEvent) (queue.Channels, error) {
+		return nil, fmt.Errorf("Error!")
+	}
+	clock := func() time.Time {
+		t.Fatal("Unexpected call to clock")
+		return time.Time{}
+	}
+
+	handler := CreateTimestampedMessageHandler(fn, clock)
+	_, err := handler(queue.Channels{}, makeTestEvent("text"))
+	if err == nil {
+		t.Fatal("Expected error")
+	}
+}
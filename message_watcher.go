@@ -0,0 +1,21 @@
+package qbot
+
+import (
+	"github.com/doozr/guac"
+	"github.com/doozr/qbot/queue"
+)
+
+// CreateWatchedMessageHandler creates a message handler that calls another and tells a watcher what changed, who
+// changed it and with which command.
+func CreateWatchedMessageHandler(fn MessageHandler, watch Watcher) MessageHandler {
+	return func(oqs queue.Channels, m guac.MessageEvent) (qs queue.Channels, err error) {
+		qs, err = fn(oqs, m)
+		if err != nil {
+			return
+		}
+
+		cmd, _ := parseCommand(m)
+		err = watch(oqs, qs, m.User, cmd)
+		return
+	}
+}
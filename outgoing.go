@@ -0,0 +1,306 @@
+package qbot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/doozr/jot"
+	"github.com/doozr/qbot/queue"
+	"github.com/doozr/qbot/usercache"
+)
+
+// The events that outgoing webhooks are sent for.
+const (
+	HookAcquired = "acquired"
+	HookReleased = "released"
+	HookJoined   = "joined"
+	HookLeft     = "left"
+	HookOusted   = "ousted"
+	HookBooted   = "booted"
+)
+
+// hookBacklog is how many outgoing webhooks may wait to be sent to one URL before more are dropped
+const hookBacklog = 100
+
+// ousting are the commands that take the token or a place in the queue from somebody against their will, along with
+// the hold timer that ousts holders who keep the token too long
+var ousting = map[string]bool{"oust": true, "boot": true, "barge": true, "hold": true}
+
+// HookEvent is the body of an outgoing webhook.
+//
+// Actor is whoever made the change, and is empty when qbot made it itself, such as when a holder runs out of time.
+// Entry is the entry that the event is about, as it is in the resulting queue, or with no position if it has gone.
+type HookEvent struct {
+	Event     string    `json:"event"`
+	Time      time.Time `json:"time"`
+	Channel   string    `json:"channel"`
+	Actor     string    `json:"actor"`
+	ActorName string    `json:"actor_name"`
+	Entry     apiEntry  `json:"entry"`
+	Queue     apiToken  `json:"queue"`
+}
+
+// OutgoingHook is a URL that is told about changes to the queues.
+//
+// Only the named events are sent, or every event if none are named.
+type OutgoingHook struct {
+	URL    string
+	Events []string
+}
+
+// wants is true if the hook is sent the event.
+func (h OutgoingHook) wants(event string) bool {
+	if len(h.Events) == 0 {
+		return true
+	}
+	for _, e := range h.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Retry says how many times an outgoing webhook is tried and how long to wait after the first failure, which doubles
+// after each failure that follows.
+type Retry struct {
+	Attempts int
+	Backoff  time.Duration
+}
+
+// HookPoster sends the body of an outgoing webhook to a URL.
+type HookPoster func(url string, body []byte) error
+
+// HookSender queues an outgoing webhook to be sent, without waiting for it to go.
+type HookSender func(e HookEvent)
+
+// CreateHookPoster creates a HookPoster that posts JSON with an HTTP client, signed with the secret in SignatureHeader
+// along with the time in TimestampHeader if there is one, in the same way as incoming webhooks.
+//
+// Anything other than a 2xx status is an error.
+func CreateHookPoster(client *http.Client, secret []byte, now Clock) HookPoster {
+	return func(url string, body []byte) (err error) {
+		req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if len(secret) > 0 {
+			timestamp := strconv.FormatInt(now().Unix(), 10)
+			req.Header.Set(TimestampHeader, timestamp)
+			req.Header.Set(SignatureHeader, sign(secret, timestamp, body))
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			return
+		}
+		defer res.Body.Close()
+		io.Copy(ioutil.Discard, res.Body)
+
+		if res.StatusCode < 200 || res.StatusCode > 299 {
+			err = fmt.Errorf("%s responded %s", url, res.Status)
+		}
+		return
+	}
+}
+
+// StartHookSender sends outgoing webhooks to one URL in the background, in the order they happen, until done.
+//
+// A webhook that fails is tried again after a wait until it has been tried as often as the retry allows, and the
+// others wait behind it. Nothing that is sent ever waits for the URL, so if too many pile up the new ones are dropped.
+func StartHookSender(hook OutgoingHook, post HookPoster, retry Retry, after After, done DoneChan,
+	waitGroup *sync.WaitGroup) HookSender {
+
+	pending := make(chan HookEvent, hookBacklog)
+
+	send := func(e HookEvent) {
+		body, err := json.Marshal(e)
+		if err != nil {
+			log.Printf("Error serialising %s webhook: %s", e.Event, err)
+			return
+		}
+
+		wait := retry.Backoff
+		for attempt := 1; ; attempt++ {
+			jot.Printf("hooks: sending %s to %s, attempt %d", e.Event, hook.URL, attempt)
+			err = post(hook.URL, body)
+			if err == nil {
+				return
+			}
+			if attempt >= retry.Attempts {
+				log.Printf("Error sending %s webhook to %s, giving up after %d attempts: %s", e.Event, hook.URL, attempt, err)
+				return
+			}
+			log.Printf("Error sending %s webhook to %s, trying again in %s: %s", e.Event, hook.URL, wait, err)
+
+			select {
+			case <-done:
+				return
+			case <-after(wait):
+			}
+			wait *= 2
+		}
+	}
+
+	jot.Print("qbot.hooks starting up for ", hook.URL)
+	waitGroup.Add(1)
+	go func() {
+		for {
+			select {
+			case <-done:
+				jot.Print("qbot.hooks done for ", hook.URL)
+				waitGroup.Done()
+				return
+			case e := <-pending:
+				send(e)
+			}
+		}
+	}()
+
+	return func(e HookEvent) {
+		if !hook.wants(e.Event) {
+			return
+		}
+		select {
+		case pending <- e:
+		default:
+			log.Printf("Too many webhooks waiting for %s, dropping %s", hook.URL, e.Event)
+		}
+	}
+}
+
+// hookChange is an event that happened to an entry in a queue.
+type hookChange struct {
+	event string
+	item  queue.Item
+}
+
+// indexOfOwn finds where an entry is in a queue, or -1 if it is not there or has been delegated to somebody else.
+func indexOfOwn(q queue.Queue, i queue.Item) int {
+	ix := q.IndexOf(i)
+	if ix >= 0 && q[ix].ID != i.ID {
+		return -1
+	}
+	return ix
+}
+
+// hookChanges works out the events that turn one queue into another.
+//
+// Entries that go or stop holding the token come first, then those that join and last those that get the token. An
+// entry that goes or stops holding because of a command that ousts is booted or ousted, and a holder that goes for any
+// other reason, such as finishing or a reservation ending, both releases the token and leaves. An entry that is
+// delegated keeps its key, but leaves for its old owner and joins for its new one.
+func hookChanges(before, after queue.Queue, beforeCapacity, afterCapacity int, command string) []hookChange {
+	changes := []hookChange{}
+	add := func(event string, i queue.Item) {
+		changes = append(changes, hookChange{event, i})
+	}
+
+	ousted := ousting[command]
+	for _, i := range before {
+		held := before.Holds(i, beforeCapacity)
+		ix := indexOfOwn(after, i)
+		stayed := ix >= 0
+
+		switch {
+		case !stayed && held && ousted:
+			add(HookOusted, i)
+		case !stayed && held:
+			add(HookReleased, i)
+			add(HookLeft, i)
+		case !stayed && ousted:
+			add(HookBooted, i)
+		case !stayed:
+			add(HookLeft, i)
+		case held && !after.Holds(i, afterCapacity) && ousted:
+			add(HookOusted, after[ix])
+		case held && !after.Holds(i, afterCapacity):
+			add(HookReleased, after[ix])
+		}
+	}
+
+	for _, i := range after {
+		if indexOfOwn(before, i) < 0 {
+			add(HookJoined, i)
+		}
+	}
+
+	for _, i := range after {
+		if after.Holds(i, afterCapacity) && (indexOfOwn(before, i) < 0 || !before.Holds(i, beforeCapacity)) {
+			add(HookAcquired, i)
+		}
+	}
+	return changes
+}
+
+// CreateHookWatcher creates a Watcher that sends outgoing webhooks when entries get or release a token, join or leave
+// a queue, or are ousted or booted.
+//
+// Each webhook carries the entry, who made the change and the whole of the resulting queue. The senders queue the
+// webhooks to be sent in the background, so the watcher never waits for them.
+func CreateHookWatcher(userCache usercache.UserCache, now Clock, senders ...HookSender) Watcher {
+	return func(before, after queue.Channels, actor, command string) (err error) {
+		t := now()
+		for _, ch := range allChannels(before, after) {
+			bt, at := before.Get(ch), after.Get(ch)
+			names := at.Names()
+			for _, name := range bt.Names() {
+				if !at.Exists(name) {
+					names = append(names, name)
+				}
+			}
+
+			for _, name := range names {
+				aq := at.Get(name)
+				changes := hookChanges(bt.Get(name), aq, bt.Capacity(name), at.Capacity(name), command)
+				if len(changes) == 0 {
+					continue
+				}
+
+				resulting := describeToken(userCache, at, name)
+				for _, c := range changes {
+					entry := describeEntry(userCache, c.item, 0, false)
+					if ix := indexOfOwn(aq, c.item); ix >= 0 {
+						entry = resulting.Entries[ix]
+					}
+
+					jot.Printf("hooks: %s %s in %s for %s", c.event, name, ch, c.item.ID)
+					for _, send := range senders {
+						send(HookEvent{
+							Event:     c.event,
+							Time:      t,
+							Channel:   ch,
+							Actor:     actor,
+							ActorName: userCache.GetUserName(actor),
+							Entry:     entry,
+							Queue:     resulting,
+						})
+					}
+				}
+			}
+		}
+		return
+	}
+}
+
+// allChannels returns every channel in either set of queues, in order
+func allChannels(before, after queue.Channels) []string {
+	channels := sortedChannels(after)
+	for _, ch := range sortedChannels(before) {
+		if _, ok := after[ch]; !ok {
+			channels = append(channels, ch)
+		}
+	}
+	sort.Strings(channels)
+	return channels
+}
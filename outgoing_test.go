@@ -0,0 +1,314 @@
+package qbot_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/doozr/qbot"
+	"github.com/doozr/qbot/queue"
+)
+
+var hookTime = time.Date(2017, 3, 14, 12, 0, 0, 0, time.UTC)
+
+var (
+	craigsEntry = queue.Item{ID: "U123", Reason: "Tomato", Key: "jox"}
+	botsEntry   = queue.Item{ID: "U999", Reason: "Potato", Key: "lqy"}
+
+	// delegatedEntry is craig's entry once it has been delegated to U456
+	delegatedEntry = queue.Item{ID: "U456", Reason: "Tomato", Key: "jox"}
+)
+
+func hookQueues(q queue.Queue) queue.Channels {
+	return queue.Channels{}.Set("C1234", queue.Tokens{}.Set(queue.DefaultToken, q))
+}
+
+// watchHooks gives the events a hook watcher sends for a change.
+func watchHooks(t *testing.T, before, after queue.Channels, actor, command string) []HookEvent {
+	events := []HookEvent{}
+	send := func(e HookEvent) {
+		events = append(events, e)
+	}
+
+	watch := CreateHookWatcher(webhookUsers, func() time.Time { return hookTime }, send)
+	if err := watch(before, after, actor, command); err != nil {
+		t.Fatal("Unexpected error: ", err)
+	}
+	return events
+}
+
+func hookNames(events []HookEvent) []string {
+	names := []string{}
+	for _, e := range events {
+		names = append(names, e.Event+" "+e.Entry.User)
+	}
+	return names
+}
+
+func TestHookWatcherWorksOutEvents(t *testing.T) {
+	reserved := queue.Item{ID: "U456", Reason: "Reserved", Key: "rez"}
+
+	for _, tt := range []struct {
+		test     string
+		before   queue.Queue
+		after    queue.Queue
+		actor    string
+		command  string
+		expected []string
+	}{
+		{"nothing changes", queue.Queue{craigsEntry}, queue.Queue{craigsEntry}, "U123", "join", []string{}},
+		{"join an empty queue", queue.Queue{}, queue.Queue{craigsEntry}, "U123", "join",
+			[]string{"joined U123", "acquired U123"}},
+		{"join behind someone", queue.Queue{craigsEntry}, queue.Queue{craigsEntry, botsEntry}, "U999", "join",
+			[]string{"joined U999"}},
+		{"done", queue.Queue{craigsEntry, botsEntry}, queue.Queue{botsEntry}, "U123", "done",
+			[]string{"released U123", "left U123", "acquired U999"}},
+		{"success", queue.Queue{craigsEntry, botsEntry}, queue.Queue{botsEntry}, "U999", "success",
+			[]string{"released U123", "left U123", "acquired U999"}},
+		{"leave", queue.Queue{craigsEntry, botsEntry}, queue.Queue{craigsEntry}, "U999", "leave",
+			[]string{"left U999"}},
+		{"yield", queue.Queue{craigsEntry, botsEntry}, queue.Queue{botsEntry, craigsEntry}, "U123", "yield",
+			[]string{"released U123", "acquired U999"}},
+		{"oust", queue.Queue{craigsEntry, botsEntry}, queue.Queue{botsEntry, craigsEntry}, "U999", "oust",
+			[]string{"ousted U123", "acquired U999"}},
+		{"oust with nobody waiting", queue.Queue{craigsEntry}, queue.Queue{}, "U999", "oust",
+			[]string{"ousted U123"}},
+		{"barge", queue.Queue{craigsEntry}, queue.Queue{botsEntry, craigsEntry}, "U999", "barge",
+			[]string{"ousted U123", "joined U999", "acquired U999"}},
+		{"boot", queue.Queue{craigsEntry, botsEntry}, queue.Queue{craigsEntry}, "U123", "boot",
+			[]string{"booted U999"}},
+		{"delegate the token", queue.Queue{craigsEntry, botsEntry}, queue.Queue{delegatedEntry, botsEntry}, "U123",
+			"delegate", []string{"released U123", "left U123", "joined U456", "acquired U456"}},
+		{"delegate a place", queue.Queue{botsEntry, craigsEntry}, queue.Queue{botsEntry, delegatedEntry}, "U123",
+			"delegate", []string{"left U123", "joined U456"}},
+		{"run out of time", queue.Queue{craigsEntry, botsEntry}, queue.Queue{botsEntry, craigsEntry}, "", "hold",
+			[]string{"ousted U123", "acquired U999"}},
+		{"reservation starts", queue.Queue{craigsEntry}, queue.Queue{reserved, craigsEntry}, "", "reserve",
+			[]string{"released U123", "joined U456", "acquired U456"}},
+		{"reservation ends", queue.Queue{reserved, craigsEntry}, queue.Queue{craigsEntry}, "", "reserve",
+			[]string{"released U456", "left U456", "acquired U123"}},
+	} {
+		events := watchHooks(t, hookQueues(tt.before), hookQueues(tt.after), tt.actor, tt.command)
+		if names := hookNames(events); !reflect.DeepEqual(tt.expected, names) {
+			t.Fatal(tt.test, ": expected ", tt.expected, ", got ", names)
+		}
+	}
+}
+
+func TestHookWatcherSendsEntryActorAndQueue(t *testing.T) {
+	events := watchHooks(t,
+		hookQueues(queue.Queue{craigsEntry, botsEntry}),
+		hookQueues(queue.Queue{botsEntry}),
+		"U123", "done")
+
+	released, acquired := events[0], events[2]
+	if released.Time != hookTime || released.Channel != "C1234" || released.Actor != "U123" || released.ActorName != "craig" {
+		t.Fatal("Expected event by craig in C1234, got ", released)
+	}
+	if released.Entry.Key != "jox" || released.Entry.Position != 0 || released.Entry.Holding {
+		t.Fatal("Expected entry that has gone, got ", released.Entry)
+	}
+	if acquired.Entry.Key != "lqy" || acquired.Entry.Position != 1 || !acquired.Entry.Holding {
+		t.Fatal("Expected entry that holds the token, got ", acquired.Entry)
+	}
+
+	for _, e := range events {
+		if e.Queue.Name != queue.DefaultToken || len(e.Queue.Entries) != 1 || e.Queue.Entries[0].Key != "lqy" {
+			t.Fatal("Expected resulting queue, got ", e.Queue)
+		}
+	}
+}
+
+func TestHookWatcherDescribesBothOwnersOfDelegatedEntry(t *testing.T) {
+	events := watchHooks(t,
+		hookQueues(queue.Queue{craigsEntry, botsEntry}),
+		hookQueues(queue.Queue{delegatedEntry, botsEntry}),
+		"U123", "delegate")
+
+	released, acquired := events[0], events[3]
+	if released.Entry.User != "U123" || released.Entry.Position != 0 || released.Entry.Holding {
+		t.Fatal("Expected craig's entry to have gone, got ", released.Entry)
+	}
+	if acquired.Entry.User != "U456" || acquired.Entry.Key != "jox" || acquired.Entry.Position != 1 || !acquired.Entry.Holding {
+		t.Fatal("Expected the delegated entry to hold the token, got ", acquired.Entry)
+	}
+}
+
+func TestHookWatcherSendsEventsForDeletedToken(t *testing.T) {
+	before := queue.Channels{}.Set("C1234", queue.Tokens{}.Set("staging", queue.Queue{craigsEntry}))
+	after := queue.Channels{}.Set("C1234", queue.Tokens{})
+
+	events := watchHooks(t, before, after, "U999", "delete")
+	if names := hookNames(events); !reflect.DeepEqual([]string{"released U123", "left U123"}, names) {
+		t.Fatal("Expected holder to release the token and leave, got ", names)
+	}
+	if events[0].Queue.Name != "staging" || len(events[0].Queue.Entries) != 0 {
+		t.Fatal("Expected empty staging queue, got ", events[0].Queue)
+	}
+}
+
+// startHookSender starts a sender that records what it posts and the waits between tries.
+func startHookSender(hook OutgoingHook, post HookPoster) (HookSender, *[]time.Duration, func()) {
+	waits := []time.Duration{}
+	after := func(d time.Duration) <-chan time.Time {
+		waits = append(waits, d)
+		c := make(chan time.Time, 1)
+		c <- hookTime
+		return c
+	}
+
+	done := make(DoneChan)
+	waitGroup := sync.WaitGroup{}
+	send := StartHookSender(hook, post, Retry{Attempts: 4, Backoff: time.Second}, after, done, &waitGroup)
+	return send, &waits, func() {
+		close(done)
+		waitGroup.Wait()
+	}
+}
+
+func TestHookSenderRetriesWithBackoff(t *testing.T) {
+	posted := make(chan HookEvent, 10)
+	attempts := 0
+	post := func(url string, body []byte) error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("unavailable")
+		}
+
+		var e HookEvent
+		if url != "http://example.com/hook" || json.Unmarshal(body, &e) != nil {
+			t.Error("Unexpected post to ", url, ": ", string(body))
+		}
+		posted <- e
+		return nil
+	}
+
+	send, waits, stop := startHookSender(OutgoingHook{URL: "http://example.com/hook"}, post)
+	send(HookEvent{Event: HookJoined, Channel: "C1234"})
+
+	select {
+	case e := <-posted:
+		if e.Event != HookJoined || e.Channel != "C1234" {
+			t.Fatal("Expected joined in C1234, got ", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Webhook was never sent")
+	}
+	stop()
+
+	if !reflect.DeepEqual([]time.Duration{time.Second, 2 * time.Second}, *waits) {
+		t.Fatal("Expected back off of 1s then 2s, got ", *waits)
+	}
+}
+
+func TestHookSenderGivesUp(t *testing.T) {
+	attempts := make(chan int, 10)
+	count := 0
+	post := func(url string, body []byte) error {
+		count++
+		attempts <- count
+		return errors.New("unavailable")
+	}
+
+	send, _, stop := startHookSender(OutgoingHook{URL: "http://example.com/hook"}, post)
+	send(HookEvent{Event: HookJoined})
+	send(HookEvent{Event: HookLeft})
+
+	for expected := 1; expected <= 8; expected++ {
+		select {
+		case n := <-attempts:
+			if n != expected {
+				t.Fatal("Expected attempt ", expected, ", got ", n)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Expected 4 attempts at each webhook, got ", expected-1)
+		}
+	}
+	stop()
+}
+
+func TestHookSenderNeverWaitsForSlowURL(t *testing.T) {
+	release := make(chan struct{})
+	post := func(url string, body []byte) error {
+		<-release
+		return nil
+	}
+
+	send, _, stop := startHookSender(OutgoingHook{URL: "http://example.com/hook"}, post)
+	sent := make(chan struct{})
+	go func() {
+		for n := 0; n < 1000; n++ {
+			send(HookEvent{Event: HookJoined})
+		}
+		close(sent)
+	}()
+
+	select {
+	case <-sent:
+	case <-time.After(time.Second):
+		t.Fatal("Sending waited for a slow URL")
+	}
+	close(release)
+	stop()
+}
+
+func TestHookSenderOnlySendsChosenEvents(t *testing.T) {
+	posted := make(chan string, 10)
+	post := func(url string, body []byte) error {
+		var e HookEvent
+		json.Unmarshal(body, &e)
+		posted <- e.Event
+		return nil
+	}
+
+	send, _, stop := startHookSender(OutgoingHook{URL: "http://example.com/hook", Events: []string{HookLeft}}, post)
+	send(HookEvent{Event: HookJoined})
+	send(HookEvent{Event: HookLeft})
+
+	select {
+	case event := <-posted:
+		if event != HookLeft {
+			t.Fatal("Expected only left to be sent, got ", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Webhook was never sent")
+	}
+	stop()
+}
+
+func TestHookPosterSignsBody(t *testing.T) {
+	var signature, timestamp, contentType, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		body, contentType = string(b), r.Header.Get("Content-Type")
+		signature, timestamp = r.Header.Get(SignatureHeader), r.Header.Get(TimestampHeader)
+	}))
+	defer server.Close()
+
+	post := CreateHookPoster(server.Client(), webhookSecret, func() time.Time { return webhookTime })
+	if err := post(server.URL, []byte(`{"event":"joined"}`)); err != nil {
+		t.Fatal("Unexpected error: ", err)
+	}
+	if body != `{"event":"joined"}` || contentType != "application/json" || timestamp != webhookTimestamp ||
+		signature != sign(body) {
+		t.Fatal("Expected signed JSON, got ", body, contentType, timestamp, signature)
+	}
+}
+
+func TestHookPosterFailsOnErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	post := CreateHookPoster(server.Client(), nil, time.Now)
+	if err := post(server.URL, []byte(`{}`)); err == nil {
+		t.Fatal("Expected error for bad gateway")
+	}
+}
@@ -2,6 +2,7 @@ package qbot
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
 	"os"
 
@@ -9,22 +10,22 @@ import (
 	"github.com/doozr/qbot/queue"
 )
 
-// Persister handles exporting the queue to persistent media.
-type Persister func(queue.Queue) error
+// Persister handles exporting the queues to persistent media.
+type Persister func(queue.Channels) error
 
 // WriteFile is a type to allow replacement of the WriteFile function for reasons.
 type WriteFile func(string, []byte, os.FileMode) error
 
 // CreatePersister creates a new Persister.
-func CreatePersister(writeFile WriteFile, filename string, oldQ queue.Queue) Persister {
-	return func(q queue.Queue) (err error) {
-		jot.Print("persist: queue to save ", q)
-		if oldQ.Equal(q) {
-			jot.Print("persist: not saving identical queue")
+func CreatePersister(writeFile WriteFile, filename string, oldQs queue.Channels) Persister {
+	return func(qs queue.Channels) (err error) {
+		jot.Print("persist: queues to save ", qs)
+		if oldQs.Equal(qs) {
+			jot.Print("persist: not saving identical queues")
 			return
 		}
 
-		j, err := json.Marshal(q)
+		j, err := json.Marshal(qs)
 		if err != nil {
 			log.Print("Error serialising qeuue: ", err)
 			return
@@ -37,9 +38,33 @@ func CreatePersister(writeFile WriteFile, filename string, oldQ queue.Queue) Per
 			return
 		}
 
-		oldQ = q
-		jot.Print("perist old queue is now: ", q)
+		oldQs = qs
+		jot.Print("perist old queues are now: ", qs)
 		jot.Print("persist: saved to ", filename)
 		return
 	}
 }
+
+// ReadQueues reads the queues that a Persister saved.
+//
+// Data files from before each channel had its own queue hold a single queue, which is given to the legacy channel.
+// Such a file cannot be read unless the legacy channel is named.
+func ReadQueues(dat []byte, legacyChannel string) (qs queue.Channels, err error) {
+	qs = queue.Channels{}
+	err = json.Unmarshal(dat, &qs)
+	if err == nil {
+		return
+	}
+
+	var q queue.Queue
+	if json.Unmarshal(dat, &q) != nil {
+		return
+	}
+	if legacyChannel == "" {
+		return nil, fmt.Errorf("the file holds a single queue from an older version, so the channel it belongs to must " +
+			"be named")
+	}
+
+	log.Printf("Moving the queue from an older version to %s", legacyChannel)
+	return queue.Channels{}.Set(legacyChannel, queue.Tokens{}.Set(queue.DefaultToken, q)), nil
+}
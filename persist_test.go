@@ -20,14 +20,14 @@ func TestDifferentQueueIsSaved(t *testing.T) {
 		return nil
 	}
 
-	persist := CreatePersister(writeFile, "output.json", queue.Queue{})
-	persist(queue.Queue([]queue.Item{queue.Item{ID: "U12345", Reason: "A reason"}, queue.Item{ID: "U67890", Reason: "Another reason"}}))
+	persist := CreatePersister(writeFile, "output.json", queue.Channels{})
+	persist(queue.Channels{"C12345": queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{queue.Item{ID: "U12345", Reason: "A reason"}, queue.Item{ID: "U67890", Reason: "Another reason"}}}}})
 
 	if fileWritten != "output.json" {
 		t.Fatal("Incorrect file written: ", fileWritten)
 	}
 
-	if string(contentWritten) != `[{"ID":"U12345","Reason":"A reason"},{"ID":"U67890","Reason":"Another reason"}]` {
+	if string(contentWritten) != `{"C12345":{"default":[{"ID":"U12345","Reason":"A reason"},{"ID":"U67890","Reason":"Another reason"}]}}` {
 		t.Fatal("Incorrect content written: ", contentWritten)
 	}
 
@@ -43,14 +43,14 @@ func TestIdenticalQueueIsNotWritten(t *testing.T) {
 		return nil
 	}
 
-	oq := queue.Queue([]queue.Item{
+	oq := queue.Channels{"C12345": queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{
 		queue.Item{ID: "U12345", Reason: "A reason"},
 		queue.Item{ID: "U67890", Reason: "Another reason"},
-	})
-	nq := queue.Queue([]queue.Item{
+	}}}}
+	nq := queue.Channels{"C12345": queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{
 		queue.Item{ID: "U12345", Reason: "A reason"},
 		queue.Item{ID: "U67890", Reason: "Another reason"},
-	})
+	}}}}
 
 	persist := CreatePersister(writeFile, "output.json", oq)
 	persist(oq)
@@ -66,8 +66,8 @@ func TestReturnsErrorIfWriteFails(t *testing.T) {
 		return fmt.Errorf("Error!")
 	}
 
-	persist := CreatePersister(writeFile, "output.json", queue.Queue{})
-	err := persist(queue.Queue([]queue.Item{queue.Item{ID: "U1234", Reason: "A reason"}}))
+	persist := CreatePersister(writeFile, "output.json", queue.Channels{})
+	err := persist(queue.Channels{"C12345": queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{queue.Item{ID: "U1234", Reason: "A reason"}}}}})
 	if err == nil {
 		t.Fatal("Expected error")
 	}
@@ -85,16 +85,77 @@ func TestStillSavesAfterFailure(t *testing.T) {
 		return nil
 	}
 
-	q := queue.Queue([]queue.Item{
+	q := queue.Channels{"C12345": queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{
 		queue.Item{ID: "U12345", Reason: "A reason"},
 		queue.Item{ID: "U67890", Reason: "Another reason"},
-	})
+	}}}}
 
-	persist := CreatePersister(writeFile, "output.json", queue.Queue{})
+	persist := CreatePersister(writeFile, "output.json", queue.Channels{})
 	persist(q)
 	persist(q)
 
-	if string(contentWritten) != `[{"ID":"U12345","Reason":"A reason"},{"ID":"U67890","Reason":"Another reason"}]` {
+	if string(contentWritten) != `{"C12345":{"default":[{"ID":"U12345","Reason":"A reason"},{"ID":"U67890","Reason":"Another reason"}]}}` {
 		t.Fatal("Incorrect content written: ", contentWritten)
 	}
 }
+
+func TestAllChannelsAndTokensAreSaved(t *testing.T) {
+	var contentWritten []byte
+	writeFile := func(f string, c []byte, p os.FileMode) error {
+		contentWritten = c
+		return nil
+	}
+
+	persist := CreatePersister(writeFile, "output.json", queue.Channels{})
+	persist(queue.Channels{
+		"C12345": queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{queue.Item{ID: "U12345", Reason: "A reason"}}}},
+		"C67890": queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{queue.Item{ID: "U67890", Reason: "Another reason"}}}, "staging": {Queue: queue.Queue{}}},
+	})
+
+	if string(contentWritten) != `{"C12345":{"default":[{"ID":"U12345","Reason":"A reason"}]},"C67890":{"default":[{"ID":"U67890","Reason":"Another reason"}],"staging":[]}}` {
+		t.Fatal("Incorrect content written: ", string(contentWritten))
+	}
+}
+
+func TestQueuesAreRead(t *testing.T) {
+	qs, err := ReadQueues([]byte(`{"C12345":{"default":[{"ID":"U12345","Reason":"A reason"}]}}`), "C999")
+	if err != nil {
+		t.Fatal("Unexpected error: ", err)
+	}
+
+	expected := queue.Channels{"C12345": queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{
+		queue.Item{ID: "U12345", Reason: "A reason"},
+	}}}}
+	if !expected.Equal(qs) {
+		t.Fatal("Incorrect queues read: ", qs)
+	}
+}
+
+func TestLegacyQueueIsMovedToChannel(t *testing.T) {
+	qs, err := ReadQueues([]byte(`[{"ID":"U12345","Reason":"A reason"},{"ID":"U67890","Reason":"Another reason"}]`), "C12345")
+	if err != nil {
+		t.Fatal("Unexpected error: ", err)
+	}
+
+	expected := queue.Channels{"C12345": queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{
+		queue.Item{ID: "U12345", Reason: "A reason"},
+		queue.Item{ID: "U67890", Reason: "Another reason"},
+	}}}}
+	if !expected.Equal(qs) {
+		t.Fatal("Incorrect queues read: ", qs)
+	}
+}
+
+func TestLegacyQueueNeedsChannel(t *testing.T) {
+	_, err := ReadQueues([]byte(`[{"ID":"U12345","Reason":"A reason"}]`), "")
+	if err == nil {
+		t.Fatal("Expected error without a legacy channel")
+	}
+}
+
+func TestBadQueuesAreNotRead(t *testing.T) {
+	_, err := ReadQueues([]byte(`{"C12345":`), "C12345")
+	if err == nil {
+		t.Fatal("Expected error for bad JSON")
+	}
+}
@@ -0,0 +1,84 @@
+package preferences
+
+import (
+	"sync"
+)
+
+// Notify says which changes to their place in a queue a user wants to be sent a direct message about
+type Notify string
+
+const (
+	// NotifyOff sends no direct messages
+	NotifyOff Notify = "off"
+
+	// NotifyNext sends a direct message when the user gets the token or becomes next in line
+	NotifyNext Notify = "next"
+
+	// NotifyAll sends a direct message whenever the user's place in a queue changes
+	NotifyAll Notify = "on"
+)
+
+// User holds the preferences of a single user
+type User struct {
+	Notify Notify `json:",omitempty"`
+}
+
+// Save is a function that stores the preferences of every user
+type Save func(map[string]User) error
+
+// Preferences holds the preferences of every user, saving them whenever they change
+type Preferences interface {
+	Notify(id string) Notify
+	SetNotify(id string, n Notify) error
+}
+
+// preferences contains a mutex controlled list of user preferences keyed on ID
+type preferences struct {
+	Mux   sync.Mutex
+	Users map[string]User
+	save  Save
+}
+
+// New creates an instance of Preferences starting from the given users
+func New(users map[string]User, save Save) Preferences {
+	p := &preferences{Users: map[string]User{}, save: save}
+	for id, u := range users {
+		p.Users[id] = u
+	}
+	return p
+}
+
+// Notify returns which changes the user wants to be told about, which is none unless they have said otherwise
+func (p *preferences) Notify(id string) (n Notify) {
+	p.Mux.Lock()
+	n = p.Users[id].Notify
+	p.Mux.Unlock()
+	if n == "" {
+		n = NotifyOff
+	}
+	return
+}
+
+// SetNotify changes which changes the user wants to be told about and saves the preferences of every user
+func (p *preferences) SetNotify(id string, n Notify) error {
+	p.Mux.Lock()
+	defer p.Mux.Unlock()
+
+	u := p.Users[id]
+	u.Notify = n
+	if n == NotifyOff {
+		u.Notify = ""
+	}
+
+	if u == (User{}) {
+		delete(p.Users, id)
+	} else {
+		p.Users[id] = u
+	}
+
+	users := map[string]User{}
+	for id, u := range p.Users {
+		users[id] = u
+	}
+	return p.save(users)
+}
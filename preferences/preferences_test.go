@@ -0,0 +1,74 @@
+package preferences_test
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/doozr/qbot/preferences"
+)
+
+func noSave(map[string]User) error {
+	return nil
+}
+
+func TestNotifyIsOffByDefault(t *testing.T) {
+	p := New(map[string]User{}, noSave)
+	if n := p.Notify("U123"); n != NotifyOff {
+		t.Fatal("Expected notifications off, got ", n)
+	}
+}
+
+func TestNotifyReadsStartingUsers(t *testing.T) {
+	p := New(map[string]User{"U123": {Notify: NotifyNext}}, noSave)
+	if n := p.Notify("U123"); n != NotifyNext {
+		t.Fatal("Expected notifications for next in line, got ", n)
+	}
+}
+
+func TestSetNotifySavesEveryUser(t *testing.T) {
+	var saved map[string]User
+	save := func(users map[string]User) error {
+		saved = users
+		return nil
+	}
+
+	p := New(map[string]User{"U456": {Notify: NotifyNext}}, save)
+	err := p.SetNotify("U123", NotifyAll)
+	if err != nil {
+		t.Fatal("Unexpected error ", err)
+	}
+
+	if p.Notify("U123") != NotifyAll {
+		t.Fatal("Expected all notifications, got ", p.Notify("U123"))
+	}
+
+	if len(saved) != 2 || saved["U123"].Notify != NotifyAll || saved["U456"].Notify != NotifyNext {
+		t.Fatal("Unexpected users saved ", saved)
+	}
+}
+
+func TestSetNotifyOffForgetsUser(t *testing.T) {
+	var saved map[string]User
+	save := func(users map[string]User) error {
+		saved = users
+		return nil
+	}
+
+	p := New(map[string]User{"U123": {Notify: NotifyAll}}, save)
+	p.SetNotify("U123", NotifyOff)
+
+	if len(saved) != 0 {
+		t.Fatal("Expected no users saved, got ", saved)
+	}
+}
+
+func TestSetNotifyReturnsSaveError(t *testing.T) {
+	save := func(users map[string]User) error {
+		return fmt.Errorf("Error!")
+	}
+
+	p := New(map[string]User{}, save)
+	if err := p.SetNotify("U123", NotifyAll); err == nil {
+		t.Fatal("Expected error")
+	}
+}
@@ -1,12 +1,32 @@
 package qbot
 
-import "github.com/doozr/qbot/command"
+import (
+	"github.com/doozr/qbot/command"
+	"github.com/doozr/qbot/queue"
+)
+
+// readOnly adapts a command that never changes the queue so that it can be used in private.
+func readOnly(fn command.Command) command.PrivateCommand {
+	return func(qs queue.Channels, ch, id, args string) command.Notification {
+		_, n := fn(queue.Queue{}, ch, id, args)
+		return n
+	}
+}
 
 // PrivateCommands are commands only available to DM.
-func PrivateCommands(commands command.QueueCommands) (commandMap CommandMap) {
-	commandMap = CommandMap{
-		"list": commands.List,
-		"help": commands.Help,
+func PrivateCommands(commands command.QueueCommands) (commandMap PrivateCommandMap) {
+	commandMap = privateCommands(commands)
+	commandMap["help"] = readOnly(help(commands))
+	return
+}
+
+// privateCommands are the private commands other than help.
+func privateCommands(commands command.QueueCommands) (commandMap PrivateCommandMap) {
+	commandMap = PrivateCommandMap{
+		"list":   commands.ListAll,
+		"stats":  commands.StatsAll,
+		"notify": commands.Notify,
+		"where":  commands.Where,
 	}
 	return
 }
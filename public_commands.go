@@ -1,24 +1,51 @@
 package qbot
 
-import "github.com/doozr/qbot/command"
+import (
+	"github.com/doozr/qbot/command"
+	"github.com/doozr/qbot/queue"
+)
+
+// tokenless adapts a command that never changes the queue so that it can be used in a channel with any tokens.
+func tokenless(fn command.Command) command.ChannelCommand {
+	return func(t queue.Tokens, ch, id, args string) (queue.Tokens, command.Notification) {
+		_, n := fn(queue.Queue{}, ch, id, args)
+		return t, n
+	}
+}
 
 // PublicCommands are commands accessible from public channels.
 func PublicCommands(commands command.QueueCommands) (commandMap CommandMap) {
+	commandMap = publicCommands(commands)
+	commandMap["help"] = tokenless(help(commands))
+	return
+}
+
+// publicCommands are the public commands other than help.
+func publicCommands(commands command.QueueCommands) (commandMap CommandMap) {
 	commandMap = CommandMap{
-		"join":     commands.Join,
-		"leave":    commands.Leave,
-		"done":     commands.Done,
-		"drop":     commands.Done,
-		"yield":    commands.Yield,
-		"success":  commands.Success,
-		"failure":  commands.Failure,
-		"barge":    commands.Barge,
-		"replace":  commands.Replace,
-		"delegate": commands.Delegate,
-		"boot":     commands.Boot,
-		"oust":     commands.Oust,
-		"list":     commands.List,
-		"help":     commands.Help,
+		"join":     commands.Named(command.QueueCommands.Join),
+		"leave":    commands.Named(command.QueueCommands.Leave),
+		"done":     commands.Named(command.QueueCommands.Done),
+		"drop":     commands.Named(command.QueueCommands.Done),
+		"yield":    commands.Named(command.QueueCommands.Yield),
+		"success":  commands.Named(command.QueueCommands.Success),
+		"failure":  commands.Named(command.QueueCommands.Failure),
+		"barge":    commands.Named(command.QueueCommands.Barge),
+		"replace":  commands.Named(command.QueueCommands.Replace),
+		"delegate": commands.Named(command.QueueCommands.Delegate),
+		"boot":     commands.Named(command.QueueCommands.Boot),
+		"oust":     commands.VoteOust,
+		"move":     commands.Move,
+		"swap":     commands.Swap,
+		"accept":   commands.Accept,
+		"decline":  commands.Decline,
+		"create":   commands.Create,
+		"delete":   commands.Delete,
+		"capacity": commands.Capacity,
+		"reserve":  commands.Reserve,
+		"undo":     commands.Undo,
+		"stats":    commands.Stats,
+		"list":     commands.ListTokens,
 	}
 	return
 }
@@ -0,0 +1,58 @@
+package queue
+
+import "time"
+
+// Channels holds a separate set of Tokens for each channel, keyed on channel ID
+type Channels map[string]Tokens
+
+func (c Channels) clone() Channels {
+	nc := make(Channels, len(c))
+	for k, v := range c {
+		nc[k] = v
+	}
+	return nc
+}
+
+// Get returns the tokens for a channel, or an empty set of tokens if the channel has none
+func (c Channels) Get(channel string) Tokens {
+	if t, ok := c[channel]; ok {
+		return t
+	}
+	return Tokens{}
+}
+
+// Set returns a copy with the tokens for a channel replaced, dropping the channel entirely if it has no tokens
+func (c Channels) Set(channel string, t Tokens) Channels {
+	nc := c.clone()
+	if len(t) == 0 {
+		delete(nc, channel)
+		return nc
+	}
+	nc[channel] = t
+	return nc
+}
+
+// Equal checks if every channel has the same tokens as in another set of channels
+func (c Channels) Equal(other Channels) bool {
+	if len(c) != len(other) {
+		return false
+	}
+
+	for channel, t := range c {
+		o, ok := other[channel]
+		if !ok || !t.Equal(o) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Stamp sets the join and acquisition times of every queue in every channel
+func (c Channels) Stamp(now time.Time) Channels {
+	nc := c.clone()
+	for channel, t := range c {
+		nc[channel] = t.Stamp(now)
+	}
+	return nc
+}
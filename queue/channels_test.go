@@ -0,0 +1,55 @@
+package queue_test
+
+import (
+	"testing"
+
+	. "github.com/doozr/qbot/queue"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetReturnsChannelTokens(t *testing.T) {
+	c := Channels{"C1": Tokens{DefaultToken: {Queue: Queue{John, Jimmy}}}, "C2": Tokens{DefaultToken: {Queue: Queue{Mick}}}}
+	assert.Equal(t, Tokens{DefaultToken: {Queue: Queue{Mick}}}, c.Get("C2"))
+}
+
+func TestGetReturnsEmptyTokensForUnknownChannel(t *testing.T) {
+	c := Channels{"C1": Tokens{DefaultToken: {Queue: Queue{John, Jimmy}}}}
+	assert.Equal(t, Tokens{}, c.Get("C2"))
+}
+
+func TestSetImmutable(t *testing.T) {
+	c := Channels{"C1": Tokens{DefaultToken: {Queue: Queue{John}}}}
+	c.Set("C1", Tokens{DefaultToken: {Queue: Queue{Jimmy}}})
+	c.Set("C2", Tokens{DefaultToken: {Queue: Queue{Mick}}})
+	assert.Equal(t, Channels{"C1": Tokens{DefaultToken: {Queue: Queue{John}}}}, c)
+}
+
+func TestSetReplacesChannelTokens(t *testing.T) {
+	c := Channels{"C1": Tokens{DefaultToken: {Queue: Queue{John}}}, "C2": Tokens{DefaultToken: {Queue: Queue{Mick}}}}
+	c = c.Set("C1", Tokens{DefaultToken: {Queue: Queue{John, Jimmy}}})
+	assert.Equal(t, Channels{"C1": Tokens{DefaultToken: {Queue: Queue{John, Jimmy}}}, "C2": Tokens{DefaultToken: {Queue: Queue{Mick}}}}, c)
+}
+
+func TestSetRemovesChannelWithoutTokens(t *testing.T) {
+	c := Channels{"C1": Tokens{DefaultToken: {Queue: Queue{John}}}, "C2": Tokens{DefaultToken: {Queue: Queue{Mick}}}}
+	c = c.Set("C1", Tokens{})
+	assert.Equal(t, Channels{"C2": Tokens{DefaultToken: {Queue: Queue{Mick}}}}, c)
+}
+
+func TestChannelsEqual(t *testing.T) {
+	c := Channels{"C1": Tokens{DefaultToken: {Queue: Queue{John, Jimmy}}}, "C2": Tokens{"staging": {Queue: Queue{Mick}}}}
+	other := Channels{"C1": Tokens{DefaultToken: {Queue: Queue{John, Jimmy}}}, "C2": Tokens{"staging": {Queue: Queue{Mick}}}}
+	assert.Equal(t, true, c.Equal(other))
+}
+
+func TestChannelsUnequalIfDifferentChannels(t *testing.T) {
+	c := Channels{"C1": Tokens{DefaultToken: {Queue: Queue{John, Jimmy}}}}
+	other := Channels{"C2": Tokens{DefaultToken: {Queue: Queue{John, Jimmy}}}}
+	assert.Equal(t, false, c.Equal(other))
+}
+
+func TestChannelsUnequalIfDifferentTokens(t *testing.T) {
+	c := Channels{"C1": Tokens{DefaultToken: {Queue: Queue{John, Jimmy}}}}
+	other := Channels{"C1": Tokens{DefaultToken: {Queue: Queue{John, Colin}}}}
+	assert.Equal(t, false, c.Equal(other))
+}
@@ -1,9 +1,67 @@
 package queue
 
+import (
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"time"
+)
+
 // Item represents a person with a job in the queue
+//
+// Key is a short identifier that stays the same for as long as the entry is in the queue, whatever happens to the
+// entries around it.
 type Item struct {
-	ID     string
-	Reason string
+	ID          string
+	Reason      string
+	Key         string
+	JoinedAt    time.Time
+	ActiveSince time.Time
+}
+
+// Is checks if the item is the same entry as another, regardless of when it joined or became active
+//
+// Entries are compared by key if both have one, otherwise by user and reason.
+func (i Item) Is(other Item) bool {
+	if i.Key != "" && other.Key != "" {
+		return i.Key == other.Key
+	}
+	return i.ID == other.ID && i.Reason == other.Reason
+}
+
+// keyChars are the characters that make up a key
+const keyChars = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// newKey makes a short key for an item that is not already in use
+//
+// The key is worked out from the user and reason so that it is the same every time, unless it is already in use. It
+// always starts with a letter so that it cannot be mistaken for a position.
+func newKey(i Item, used map[string]bool) string {
+	for salt := 0; ; salt++ {
+		h := crc32.ChecksumIEEE([]byte(fmt.Sprintf("%s/%s/%d", i.ID, i.Reason, salt)))
+		key := string([]byte{keyChars[10+h%26], keyChars[h/26%36], keyChars[h/936%36]})
+		if !used[key] {
+			return key
+		}
+	}
+}
+
+// MarshalJSON writes the item, leaving out timestamps that have not been set
+func (i Item) MarshalJSON() ([]byte, error) {
+	optional := func(t time.Time) *time.Time {
+		if t.IsZero() {
+			return nil
+		}
+		return &t
+	}
+
+	return json.Marshal(struct {
+		ID          string
+		Reason      string
+		Key         string     `json:",omitempty"`
+		JoinedAt    *time.Time `json:",omitempty"`
+		ActiveSince *time.Time `json:",omitempty"`
+	}{i.ID, i.Reason, i.Key, optional(i.JoinedAt), optional(i.ActiveSince)})
 }
 
 // Queue represents a list of waiting items
@@ -39,12 +97,25 @@ func (q Queue) Add(i Item) Queue {
 
 // Contains returns true if the item exists in the queue
 func (q Queue) Contains(i Item) bool {
-	for _, n := range q {
-		if n == i {
-			return true
+	return q.IndexOf(i) >= 0
+}
+
+// IndexOf returns where the item is in the queue, counting from zero, or -1 if it is not there
+func (q Queue) IndexOf(i Item) int {
+	for ix, n := range q {
+		if n.Is(i) {
+			return ix
 		}
 	}
-	return false
+	return -1
+}
+
+// slots returns the number of items that can hold the token at once, which is always at least one
+func slots(capacity int) int {
+	if capacity < 1 {
+		return 1
+	}
+	return capacity
 }
 
 // Active returns the first item in the queue or empty item if the queue is empty
@@ -57,22 +128,39 @@ func (q Queue) Active() Item {
 
 // Waiting returns all items in the queue in order except the Active item
 func (q Queue) Waiting() []Item {
-	if len(q) > 1 {
-		return q[1:].clone()
+	return q.WaitingBehind(1)
+}
+
+// Holders returns the items that hold the token when up to capacity items can hold it at once
+func (q Queue) Holders(capacity int) []Item {
+	n := slots(capacity)
+	if n > len(q) {
+		n = len(q)
+	}
+	return q[:n].clone()
+}
+
+// WaitingBehind returns the items waiting for the token when up to capacity items can hold it at once
+func (q Queue) WaitingBehind(capacity int) []Item {
+	n := slots(capacity)
+	if len(q) > n {
+		return q[n:].clone()
 	}
 	return []Item{}
 }
 
+// Holds returns true if the item holds the token when up to capacity items can hold it at once
+func (q Queue) Holds(i Item, capacity int) bool {
+	return Queue(q.Holders(capacity)).Contains(i)
+}
+
 // Remove removes an item from the queue
 func (q Queue) Remove(i Item) Queue {
 	for ix := range q {
-		if q[ix] == i {
-			if ix == 0 {
-				return q[1:].clone()
-			} else if ix == len(q)-1 {
-				return q[:ix].clone()
-			}
-			return append(q[:ix], q[ix+1:]...)
+		if q[ix].Is(i) {
+			nq := make(Queue, 0, len(q)-1)
+			nq = append(nq, q[:ix]...)
+			return append(nq, q[ix+1:]...)
 		}
 	}
 	return q
@@ -80,35 +168,140 @@ func (q Queue) Remove(i Item) Queue {
 
 // Yield swaps the Active item with the first Waiting item
 func (q Queue) Yield() Queue {
-	if len(q) < 2 {
+	return q.YieldHolder(q.Active(), 1)
+}
+
+// YieldHolder swaps an item that holds the token with the first item waiting behind the holders
+func (q Queue) YieldHolder(i Item, capacity int) Queue {
+	n := slots(capacity)
+	if len(q) <= n {
 		return q
 	}
-	q = q.clone()
-	q[0], q[1] = q[1], q[0]
+
+	for ix := 0; ix < n; ix++ {
+		if q[ix].Is(i) {
+			q = q.clone()
+			q[ix], q[n] = q[n], q[ix]
+			return q
+		}
+	}
 	return q
 }
 
 // Barge adds a new item to the second place in the queue, or moves an existing item to second place
 func (q Queue) Barge(i Item) Queue {
-	if q.Active() == i {
+	return q.BargeBehind(i, 1)
+}
+
+// BargeBehind adds a new item to the first place behind the holders, or moves an existing item there
+func (q Queue) BargeBehind(i Item, capacity int) Queue {
+	n := slots(capacity)
+	if q.Holds(i, n) {
 		return q
 	}
 
-	if len(q) < 2 {
+	if len(q) < n+1 {
 		return q.Add(i)
 	}
 
-	w := q.Remove(i).Waiting()
-	return append(Queue{q.Active(), i}, w...)
+	for _, e := range q {
+		if e.Is(i) {
+			i = e
+		}
+	}
+
+	w := q.Remove(i)
+	nq := append(w[:n].clone(), i)
+	return append(nq, w[n:]...)
+}
+
+// Move returns a copy with the item at index from moved to index to, shuffling the items in between along by one
+//
+// The queue is returned unchanged if either index is out of range.
+func (q Queue) Move(from, to int) Queue {
+	if from < 0 || from >= len(q) || to < 0 || to >= len(q) {
+		return q
+	}
+
+	i := q[from]
+	w := append(q[:from].clone(), q[from+1:]...)
+	nq := append(w[:to].clone(), i)
+	return append(nq, w[to:]...)
+}
+
+// Swap returns a copy with the items at indexes a and b swapped, leaving every other item where it is
+//
+// The queue is returned unchanged if either index is out of range.
+func (q Queue) Swap(a, b int) Queue {
+	if a < 0 || a >= len(q) || b < 0 || b >= len(q) {
+		return q
+	}
+
+	nq := q.clone()
+	nq[a], nq[b] = nq[b], nq[a]
+	return nq
+}
+
+// Prepend adds a new item to the front of the queue, or moves an existing item to the front
+func (q Queue) Prepend(i Item) Queue {
+	for _, e := range q {
+		if e.Is(i) {
+			i = e
+		}
+	}
+
+	return append(Queue{i}, q.Remove(i)...)
 }
 
 // Delegate swaps an item for another in the same position
+//
+// The new item keeps the place in the queue, so it takes over the time the old item joined. It also takes over the
+// time the token was acquired if it belongs to the same person.
 func (q Queue) Delegate(i Item, n Item) Queue {
 	q = q.clone()
 	for ix := range q {
-		if q[ix] == i {
+		if q[ix].Is(i) {
+			n.Key = q[ix].Key
+			n.JoinedAt = q[ix].JoinedAt
+			n.ActiveSince = time.Time{}
+			if n.ID == q[ix].ID {
+				n.ActiveSince = q[ix].ActiveSince
+			}
 			q[ix] = n
 		}
 	}
 	return q
 }
+
+// Stamp sets the key of new items, the time that they joined and the time that the holders acquired the token
+//
+// Items that have joined since the last stamp are given a key and the current time, as are holders that have only
+// just acquired the token. Items that no longer hold the token have their time cleared. Add, Yield, Barge, Delegate
+// and Remove all keep existing keys and times intact, so stamping after any change keeps them correct.
+func (q Queue) Stamp(now time.Time, capacity int) Queue {
+	n := slots(capacity)
+	q = q.clone()
+
+	used := map[string]bool{}
+	for _, i := range q {
+		used[i.Key] = i.Key != ""
+	}
+
+	for ix := range q {
+		if q[ix].Key == "" {
+			q[ix].Key = newKey(q[ix], used)
+			used[q[ix].Key] = true
+		}
+
+		if q[ix].JoinedAt.IsZero() {
+			q[ix].JoinedAt = now
+		}
+
+		if ix >= n {
+			q[ix].ActiveSince = time.Time{}
+		} else if q[ix].ActiveSince.IsZero() {
+			q[ix].ActiveSince = now
+		}
+	}
+	return q
+}
@@ -1,7 +1,9 @@
 package queue_test
 
 import (
+	"encoding/json"
 	"testing"
+	"time"
 
 	. "github.com/doozr/qbot/queue"
 	"github.com/stretchr/testify/assert"
@@ -212,3 +214,209 @@ func TestDelegateWhenNotPresent(t *testing.T) {
 	expected := []Item{Colin, Mick}
 	assert.Equal(t, expected, q.Waiting())
 }
+
+var joined = time.Date(2017, 3, 14, 9, 0, 0, 0, time.UTC)
+var acquired = time.Date(2017, 3, 14, 9, 30, 0, 0, time.UTC)
+var now = time.Date(2017, 3, 14, 10, 0, 0, 0, time.UTC)
+
+// keys are the keys that Stamp gives each test item
+var keys = map[string]string{"mick": "a2y", "john": "o67", "jimmy": "t8n", "colin": "qq6"}
+
+func stamped(i Item, joinedAt, activeSince time.Time) Item {
+	i.Key = keys[i.ID]
+	i.JoinedAt = joinedAt
+	i.ActiveSince = activeSince
+	return i
+}
+
+func TestContainsIgnoresTimes(t *testing.T) {
+	q := Queue{stamped(Mick, joined, acquired)}
+	assert.Equal(t, true, q.Contains(Mick))
+}
+
+func TestIndexOf(t *testing.T) {
+	q := Queue{stamped(Mick, joined, acquired), John}
+	assert.Equal(t, 0, q.IndexOf(Mick))
+	assert.Equal(t, 1, q.IndexOf(John))
+	assert.Equal(t, -1, q.IndexOf(Colin))
+}
+
+func TestRemoveIgnoresTimes(t *testing.T) {
+	q := Queue{stamped(Mick, joined, acquired), stamped(John, joined, time.Time{})}
+	q = q.Remove(John)
+	assert.Equal(t, Queue{stamped(Mick, joined, acquired)}, q)
+}
+
+func TestRemoveMiddleImmutable(t *testing.T) {
+	q := Queue{Mick, Jimmy, John}
+	q.Remove(Jimmy)
+	assert.Equal(t, Queue{Mick, Jimmy, John}, q)
+}
+
+func TestStampSetsJoinedAndActiveTimes(t *testing.T) {
+	q := Queue{Mick, John}
+	q = q.Stamp(now, 1)
+	assert.Equal(t, Queue{stamped(Mick, now, now), stamped(John, now, time.Time{})}, q)
+}
+
+func TestStampKeepsExistingTimes(t *testing.T) {
+	q := Queue{stamped(Mick, joined, acquired), stamped(John, joined, time.Time{}), Jimmy}
+	q = q.Stamp(now, 1)
+	assert.Equal(t, Queue{stamped(Mick, joined, acquired), stamped(John, joined, time.Time{}), stamped(Jimmy, now, time.Time{})}, q)
+}
+
+func TestStampImmutable(t *testing.T) {
+	q := Queue{Mick}
+	q.Stamp(now, 1)
+	assert.Equal(t, Queue{Mick}, q)
+}
+
+func TestStampAfterRemoveActive(t *testing.T) {
+	q := Queue{stamped(Mick, joined, acquired), stamped(John, joined, time.Time{})}
+	q = q.Remove(Mick).Stamp(now, 1)
+	assert.Equal(t, Queue{stamped(John, joined, now)}, q)
+}
+
+func TestStampAfterYield(t *testing.T) {
+	q := Queue{stamped(Mick, joined, acquired), stamped(John, joined, time.Time{})}
+	q = q.Yield().Stamp(now, 1)
+	assert.Equal(t, Queue{stamped(John, joined, now), stamped(Mick, joined, time.Time{})}, q)
+}
+
+func TestStampAfterBarge(t *testing.T) {
+	q := Queue{stamped(Mick, joined, acquired), stamped(John, joined, time.Time{}), stamped(Jimmy, acquired, time.Time{})}
+	q = q.Barge(Jimmy).Barge(Colin).Stamp(now, 1)
+	assert.Equal(t, Queue{
+		stamped(Mick, joined, acquired),
+		stamped(Colin, now, time.Time{}),
+		stamped(Jimmy, acquired, time.Time{}),
+		stamped(John, joined, time.Time{})}, q)
+}
+
+func TestDelegateKeepsJoinedTime(t *testing.T) {
+	q := Queue{stamped(Mick, joined, acquired), stamped(John, joined, time.Time{})}
+	q = q.Delegate(John, Jimmy)
+	assert.Equal(t, Item{ID: "jimmy", Reason: "fix some bugs", Key: "o67", JoinedAt: joined}, q[1])
+}
+
+func TestDelegateActiveToSomeoneElseResetsActiveTime(t *testing.T) {
+	q := Queue{stamped(Mick, joined, acquired)}
+	q = q.Delegate(Mick, Jimmy).Stamp(now, 1)
+	assert.Equal(t, Item{ID: "jimmy", Reason: "fix some bugs", Key: "a2y", JoinedAt: joined, ActiveSince: now}, q.Active())
+}
+
+func TestDelegateActiveToSamePersonKeepsActiveTime(t *testing.T) {
+	q := Queue{stamped(Mick, joined, acquired)}
+	q = q.Delegate(Mick, Item{ID: "mick", Reason: "new reason"}).Stamp(now, 1)
+	assert.Equal(t, Item{ID: "mick", Reason: "new reason", Key: "a2y", JoinedAt: joined, ActiveSince: acquired}, q.Active())
+}
+
+func TestKeyIsBasedOnUserAndReason(t *testing.T) {
+	q := Queue{Mick, John}.Stamp(now, 1)
+	assert.Equal(t, "a2y", q[0].Key)
+	assert.Equal(t, "o67", q[1].Key)
+}
+
+func TestKeysAreUnique(t *testing.T) {
+	q := Queue{Item{ID: "mick", Reason: "refactoring", Key: "a2y"}, John}.Delegate(Mick, Colin)
+	q = q.Add(Mick).Stamp(now, 1)
+	assert.NotEqual(t, "a2y", q[2].Key)
+	assert.Equal(t, 3, len(q[2].Key))
+}
+
+func TestIsComparesKeysIfSet(t *testing.T) {
+	assert.Equal(t, true, Item{ID: "mick", Reason: "refactoring", Key: "a2y"}.Is(Mick))
+	assert.Equal(t, false, Item{ID: "mick", Reason: "refactoring", Key: "a2y"}.Is(Item{ID: "mick", Reason: "refactoring", Key: "b00"}))
+	assert.Equal(t, true, Item{ID: "colin", Reason: "adding bugs", Key: "a2y"}.Is(Item{ID: "mick", Reason: "refactoring", Key: "a2y"}))
+}
+
+func TestRemoveByKey(t *testing.T) {
+	q := Queue{Item{ID: "mick", Reason: "refactoring", Key: "a2y"}, Item{ID: "mick", Reason: "refactoring", Key: "b00"}}
+	q = q.Remove(Item{Key: "b00"})
+	assert.Equal(t, Queue{Item{ID: "mick", Reason: "refactoring", Key: "a2y"}}, q)
+}
+
+func TestMarshalLeavesOutUnsetTimes(t *testing.T) {
+	j, err := json.Marshal(Queue{stamped(Mick, joined, acquired), John})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, `[{"ID":"mick","Reason":"refactoring","Key":"a2y","JoinedAt":"2017-03-14T09:00:00Z","ActiveSince":"2017-03-14T09:30:00Z"},{"ID":"john","Reason":"done some coding"}]`, string(j))
+}
+
+func TestUnmarshalTimes(t *testing.T) {
+	var q Queue
+	err := json.Unmarshal([]byte(`[{"ID":"mick","Reason":"refactoring","Key":"a2y","JoinedAt":"2017-03-14T09:00:00Z","ActiveSince":"2017-03-14T09:30:00Z"}]`), &q)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, Queue{stamped(Mick, joined, acquired)}, q)
+}
+
+func TestUnmarshalWithoutTimes(t *testing.T) {
+	var q Queue
+	err := json.Unmarshal([]byte(`[{"ID":"mick","Reason":"refactoring"}]`), &q)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, Queue{Mick}, q)
+}
+
+func TestHolders(t *testing.T) {
+	q := Queue{John, Jimmy, Mick}
+	assert.Equal(t, []Item{John, Jimmy}, q.Holders(2))
+	assert.Equal(t, []Item{Mick}, q.WaitingBehind(2))
+}
+
+func TestHoldersWhenFewerThanCapacity(t *testing.T) {
+	q := Queue{John}
+	assert.Equal(t, []Item{John}, q.Holders(3))
+	assert.Equal(t, []Item{}, q.WaitingBehind(3))
+}
+
+func TestHoldersTreatsZeroCapacityAsOne(t *testing.T) {
+	q := Queue{John, Jimmy}
+	assert.Equal(t, []Item{John}, q.Holders(0))
+}
+
+func TestHolds(t *testing.T) {
+	q := Queue{John, Jimmy, Mick}
+	assert.Equal(t, true, q.Holds(Jimmy, 2))
+	assert.Equal(t, false, q.Holds(Mick, 2))
+}
+
+func TestYieldHolder(t *testing.T) {
+	q := Queue{John, Jimmy, Mick, Colin}
+	q = q.YieldHolder(John, 2)
+	assert.Equal(t, Queue{Mick, Jimmy, John, Colin}, q)
+}
+
+func TestYieldHolderWithNobodyWaiting(t *testing.T) {
+	q := Queue{John, Jimmy}
+	q = q.YieldHolder(John, 2)
+	assert.Equal(t, Queue{John, Jimmy}, q)
+}
+
+func TestYieldHolderWhenNotHolding(t *testing.T) {
+	q := Queue{John, Jimmy, Mick}
+	q = q.YieldHolder(Mick, 2)
+	assert.Equal(t, Queue{John, Jimmy, Mick}, q)
+}
+
+func TestBargeBehindHolders(t *testing.T) {
+	q := Queue{John, Jimmy, Mick}
+	q = q.BargeBehind(Colin, 2)
+	assert.Equal(t, Queue{John, Jimmy, Colin, Mick}, q)
+}
+
+func TestBargeBehindWhenHolding(t *testing.T) {
+	q := Queue{John, Jimmy, Mick}
+	q = q.BargeBehind(Jimmy, 2)
+	assert.Equal(t, Queue{John, Jimmy, Mick}, q)
+}
+
+func TestBargeBehindWithFreePlace(t *testing.T) {
+	q := Queue{John}
+	q = q.BargeBehind(Colin, 2)
+	assert.Equal(t, Queue{John, Colin}, q)
+}
+
+func TestStampSetsActiveTimeOfEveryHolder(t *testing.T) {
+	q := Queue{stamped(Mick, joined, acquired), John, stamped(Jimmy, joined, acquired)}
+	q = q.Stamp(now, 2)
+	assert.Equal(t, Queue{stamped(Mick, joined, acquired), stamped(John, now, now), stamped(Jimmy, joined, time.Time{})}, q)
+}
@@ -0,0 +1,108 @@
+package queue
+
+import "time"
+
+// Request asks the owners of other entries to agree to an entry being moved past them, or swapped with theirs
+//
+// The entry moves in front of Target once everybody it would pass has agreed, or swaps places with Target once its
+// owner has agreed if Swap is set. Approved holds the users who have agreed so far. The request is dropped if it has
+// not been agreed by Expires.
+type Request struct {
+	ID       string
+	Item     Item
+	Target   Item
+	Swap     bool     `json:",omitempty"`
+	Approved []string `json:",omitempty"`
+	Expires  time.Time
+}
+
+// Is checks if two requests are to move the same entry
+func (r Request) Is(o Request) bool {
+	return r.Item.Is(o.Item)
+}
+
+// HasApproved checks if the user has agreed to the request
+func (r Request) HasApproved(id string) bool {
+	for _, a := range r.Approved {
+		if a == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Approve returns a copy of the request that the user has agreed to
+func (r Request) Approve(id string) Request {
+	if r.HasApproved(id) {
+		return r
+	}
+	r.Approved = append(append([]string{}, r.Approved...), id)
+	return r
+}
+
+// Requests is a list of Request objects in the order they were made
+type Requests []Request
+
+func (rs Requests) clone() Requests {
+	return append(Requests{}, rs...)
+}
+
+// Equal checks if two lists hold the same requests with the same approvals in the same order
+func (rs Requests) Equal(other Requests) bool {
+	if len(rs) != len(other) {
+		return false
+	}
+
+	for ix := range rs {
+		a, b := rs[ix], other[ix]
+		if a.ID != b.ID || a.Item != b.Item || a.Target != b.Target || a.Swap != b.Swap ||
+			!a.Expires.Equal(b.Expires) || len(a.Approved) != len(b.Approved) {
+			return false
+		}
+		for ax := range a.Approved {
+			if a.Approved[ax] != b.Approved[ax] {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// Add returns a copy with a new request, replacing any earlier request to move the same entry
+func (rs Requests) Add(r Request) Requests {
+	return append(rs.Remove(r), r)
+}
+
+// Expired returns the requests that have not been agreed in time
+func (rs Requests) Expired(now time.Time) Requests {
+	expired := Requests{}
+	for _, r := range rs {
+		if !now.Before(r.Expires) {
+			expired = append(expired, r)
+		}
+	}
+	return expired
+}
+
+// Remove returns a copy without the request to move the same entry
+func (rs Requests) Remove(r Request) Requests {
+	nrs := Requests{}
+	for _, e := range rs {
+		if !e.Is(r) {
+			nrs = append(nrs, e)
+		}
+	}
+	return nrs
+}
+
+// Replace returns a copy with the request to move the same entry replaced, keeping its place
+func (rs Requests) Replace(r Request) Requests {
+	nrs := rs.clone()
+	for ix := range nrs {
+		if nrs[ix].Is(r) {
+			nrs[ix] = r
+		}
+	}
+	return nrs
+}
@@ -0,0 +1,89 @@
+package queue_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	. "github.com/doozr/qbot/queue"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMove(t *testing.T) {
+	q := Queue{Mick, John, Jimmy, Colin}
+	assert.Equal(t, Queue{Mick, Colin, John, Jimmy}, q.Move(3, 1))
+	assert.Equal(t, Queue{Mick, Jimmy, Colin, John}, q.Move(1, 3))
+	assert.Equal(t, Queue{John, Mick, Jimmy, Colin}, q.Move(0, 1))
+	assert.Equal(t, q, q.Move(2, 2))
+	assert.Equal(t, Queue{Mick, John, Jimmy, Colin}, q, "original queue should not change")
+}
+
+func TestSwap(t *testing.T) {
+	q := Queue{Mick, John, Jimmy, Colin}
+	assert.Equal(t, Queue{Mick, Colin, Jimmy, John}, q.Swap(1, 3))
+	assert.Equal(t, Queue{Mick, Colin, Jimmy, John}, q.Swap(3, 1))
+	assert.Equal(t, q, q.Swap(2, 2))
+	assert.Equal(t, q, q.Swap(1, 4))
+	assert.Equal(t, Queue{Mick, John, Jimmy, Colin}, q, "original queue should not change")
+}
+
+func TestRequestsExpired(t *testing.T) {
+	rs := Requests{
+		{ID: "colin", Item: Colin, Target: John, Expires: now},
+		{ID: "jimmy", Item: Jimmy, Target: John, Expires: now.Add(time.Minute)},
+	}
+	assert.Equal(t, Requests{}, rs.Expired(now.Add(-time.Second)))
+	assert.Equal(t, Requests{rs[0]}, rs.Expired(now))
+	assert.Equal(t, rs, rs.Expired(now.Add(time.Minute)))
+}
+
+func TestMoveOutOfRangeDoesNothing(t *testing.T) {
+	q := Queue{Mick, John}
+	assert.Equal(t, q, q.Move(2, 0))
+	assert.Equal(t, q, q.Move(0, 2))
+	assert.Equal(t, q, q.Move(-1, 0))
+}
+
+func TestRequestApprove(t *testing.T) {
+	r := Request{ID: "colin", Item: Colin, Target: John}
+	approved := r.Approve("john").Approve("john")
+	assert.Equal(t, []string{"john"}, approved.Approved)
+	assert.Equal(t, true, approved.HasApproved("john"))
+	assert.Equal(t, false, r.HasApproved("john"))
+}
+
+func TestRequestsAddReplacesRequestForSameEntry(t *testing.T) {
+	rs := Requests{}.
+		Add(Request{ID: "colin", Item: Colin, Target: John}).
+		Add(Request{ID: "jimmy", Item: Jimmy, Target: John}).
+		Add(Request{ID: "colin", Item: Colin, Target: Mick})
+	assert.Equal(t, Requests{{ID: "jimmy", Item: Jimmy, Target: John}, {ID: "colin", Item: Colin, Target: Mick}}, rs)
+}
+
+func TestRequestsReplaceKeepsPlace(t *testing.T) {
+	rs := Requests{{ID: "colin", Item: Colin, Target: John}, {ID: "jimmy", Item: Jimmy, Target: John}}
+	r := rs[0].Approve("john")
+	assert.Equal(t, Requests{r, rs[1]}, rs.Replace(r))
+	assert.Equal(t, false, rs.Equal(rs.Replace(r)))
+}
+
+func TestSetKeepsEmptyDefaultTokenWithRequests(t *testing.T) {
+	rs := Requests{{ID: "colin", Item: Colin, Target: John}}
+	tokens := Tokens{}.SetRequests(DefaultToken, rs)
+	assert.Equal(t, rs, tokens.Requests(DefaultToken))
+	assert.Equal(t, Tokens{}, tokens.SetRequests(DefaultToken, Requests{}))
+}
+
+func TestMarshalTokenWithRequests(t *testing.T) {
+	tokens := Tokens{DefaultToken: {Queue: Queue{John, Colin}, Requests: Requests{{ID: "colin", Item: Colin, Target: John}}}}
+	j, err := json.Marshal(tokens)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, `{"default":{"Queue":[{"ID":"john","Reason":"done some coding"},{"ID":"colin","Reason":"adding bugs"}],`+
+		`"Requests":[{"ID":"colin","Item":{"ID":"colin","Reason":"adding bugs"},"Target":{"ID":"john","Reason":"done some coding"},`+
+		`"Expires":"0001-01-01T00:00:00Z"}]}}`, string(j))
+
+	var read Tokens
+	err = json.Unmarshal(j, &read)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, tokens.Equal(read))
+}
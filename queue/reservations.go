@@ -0,0 +1,127 @@
+package queue
+
+import (
+	"sort"
+	"time"
+)
+
+// Reservation books a token for a user between two times
+//
+// Started is set once the token has been handed to the user so that it is only handed over once.
+type Reservation struct {
+	ID      string
+	Reason  string
+	Start   time.Time
+	End     time.Time
+	Started bool `json:",omitempty"`
+}
+
+// Item returns the queue item that holds the token during the reservation
+func (r Reservation) Item() Item {
+	return Item{ID: r.ID, Reason: r.Reason}
+}
+
+// Is checks if two reservations are for the same user, reason and window
+func (r Reservation) Is(o Reservation) bool {
+	return r.ID == o.ID && r.Reason == o.Reason && r.Start.Equal(o.Start) && r.End.Equal(o.End)
+}
+
+// Overlaps checks if two reservations are for windows that share any time
+func (r Reservation) Overlaps(o Reservation) bool {
+	return r.Start.Before(o.End) && o.Start.Before(r.End)
+}
+
+// Reservations is a list of Reservation objects in order of start time
+type Reservations []Reservation
+
+func (rs Reservations) clone() Reservations {
+	return append(Reservations{}, rs...)
+}
+
+// Equal checks if two lists hold the same reservations in the same order
+func (rs Reservations) Equal(other Reservations) bool {
+	if len(rs) != len(other) {
+		return false
+	}
+
+	for ix := range rs {
+		if !rs[ix].Is(other[ix]) || rs[ix].Started != other[ix].Started {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Overlapping returns the reservations whose windows share any time with the given one
+func (rs Reservations) Overlapping(r Reservation) Reservations {
+	o := Reservations{}
+	for _, e := range rs {
+		if e.Overlaps(r) {
+			o = append(o, e)
+		}
+	}
+	return o
+}
+
+// MostAtOnce returns the most reservations whose windows share the same moment within the window of the given one
+//
+// Reservations that overlap the window but not each other are never counted together, and one that ends as another
+// starts does not overlap it.
+func (rs Reservations) MostAtOnce(r Reservation) int {
+	type change struct {
+		at    time.Time
+		delta int
+	}
+
+	changes := []change{}
+	for _, e := range rs.Overlapping(r) {
+		changes = append(changes, change{e.Start, 1}, change{e.End, -1})
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].at.Equal(changes[j].at) {
+			return changes[i].delta < changes[j].delta
+		}
+		return changes[i].at.Before(changes[j].at)
+	})
+
+	most, now := 0, 0
+	for _, c := range changes {
+		now += c.delta
+		if now > most {
+			most = now
+		}
+	}
+	return most
+}
+
+// Add returns a copy with a new reservation in order of start time
+func (rs Reservations) Add(r Reservation) Reservations {
+	nrs := append(rs.clone(), r)
+	sort.SliceStable(nrs, func(i, j int) bool {
+		return nrs[i].Start.Before(nrs[j].Start)
+	})
+	return nrs
+}
+
+// Remove returns a copy without the given reservation
+func (rs Reservations) Remove(r Reservation) Reservations {
+	nrs := Reservations{}
+	for _, e := range rs {
+		if !e.Is(r) {
+			nrs = append(nrs, e)
+		}
+	}
+	return nrs
+}
+
+// Start returns a copy with the given reservation marked as started
+func (rs Reservations) Start(r Reservation) Reservations {
+	nrs := rs.clone()
+	for ix := range nrs {
+		if nrs[ix].Is(r) {
+			nrs[ix].Started = true
+		}
+	}
+	return nrs
+}
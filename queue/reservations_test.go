@@ -0,0 +1,87 @@
+package queue_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	. "github.com/doozr/qbot/queue"
+	"github.com/stretchr/testify/assert"
+)
+
+func reservation(i Item, startHour, hours int) Reservation {
+	start := time.Date(2017, 3, 14, startHour, 0, 0, 0, time.UTC)
+	return Reservation{ID: i.ID, Reason: i.Reason, Start: start, End: start.Add(time.Duration(hours) * time.Hour)}
+}
+
+func TestReservationOverlaps(t *testing.T) {
+	r := reservation(John, 14, 2)
+	assert.Equal(t, true, r.Overlaps(reservation(Mick, 15, 1)))
+	assert.Equal(t, true, r.Overlaps(reservation(Mick, 13, 2)))
+	assert.Equal(t, false, r.Overlaps(reservation(Mick, 16, 1)))
+	assert.Equal(t, false, r.Overlaps(reservation(Mick, 12, 2)))
+}
+
+func TestAddReservationKeepsStartOrder(t *testing.T) {
+	rs := Reservations{reservation(John, 14, 1), reservation(Mick, 16, 1)}
+	rs = rs.Add(reservation(Jimmy, 15, 1))
+	assert.Equal(t, Reservations{reservation(John, 14, 1), reservation(Jimmy, 15, 1), reservation(Mick, 16, 1)}, rs)
+}
+
+func TestAddReservationImmutable(t *testing.T) {
+	rs := Reservations{reservation(John, 14, 1)}
+	rs.Add(reservation(Jimmy, 13, 1))
+	assert.Equal(t, Reservations{reservation(John, 14, 1)}, rs)
+}
+
+func TestOverlappingReservations(t *testing.T) {
+	rs := Reservations{reservation(John, 14, 1), reservation(Mick, 16, 1)}
+	assert.Equal(t, Reservations{reservation(Mick, 16, 1)}, rs.Overlapping(reservation(Jimmy, 15, 2)))
+	assert.Equal(t, Reservations{}, rs.Overlapping(reservation(Jimmy, 15, 1)))
+}
+
+func TestMostReservationsAtOnce(t *testing.T) {
+	rs := Reservations{reservation(John, 14, 1), reservation(Mick, 15, 1), reservation(Jimmy, 15, 2)}
+	assert.Equal(t, 2, rs.MostAtOnce(reservation(Colin, 14, 2)))
+	assert.Equal(t, 1, rs.MostAtOnce(reservation(Colin, 16, 1)))
+	assert.Equal(t, 0, rs.MostAtOnce(reservation(Colin, 17, 1)))
+	assert.Equal(t, 1, Reservations{reservation(John, 14, 1), reservation(Mick, 15, 1)}.MostAtOnce(reservation(Colin, 14, 2)))
+}
+
+func TestRemoveReservation(t *testing.T) {
+	rs := Reservations{reservation(John, 14, 1), reservation(Mick, 16, 1)}
+	rs = rs.Remove(reservation(John, 14, 1))
+	assert.Equal(t, Reservations{reservation(Mick, 16, 1)}, rs)
+}
+
+func TestStartReservation(t *testing.T) {
+	r := reservation(John, 14, 1)
+	rs := Reservations{r}.Start(r)
+	r.Started = true
+	assert.Equal(t, Reservations{r}, rs)
+}
+
+func TestPrepend(t *testing.T) {
+	q := Queue{John, Jimmy, Mick}
+	assert.Equal(t, Queue{Mick, John, Jimmy}, q.Prepend(Mick))
+	assert.Equal(t, Queue{Colin, John, Jimmy, Mick}, q.Prepend(Colin))
+}
+
+func TestSetKeepsEmptyDefaultTokenWithReservations(t *testing.T) {
+	tokens := Tokens{}.SetReservations(DefaultToken, Reservations{reservation(John, 14, 1)})
+	assert.Equal(t, Tokens{DefaultToken: {Queue: Queue{}, Reservations: Reservations{reservation(John, 14, 1)}}}, tokens)
+	tokens = tokens.SetReservations(DefaultToken, Reservations{})
+	assert.Equal(t, Tokens{}, tokens)
+}
+
+func TestMarshalTokenWithReservations(t *testing.T) {
+	j, err := json.Marshal(Tokens{DefaultToken: {Queue: Queue{}, Reservations: Reservations{reservation(John, 14, 1)}}})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, `{"default":{"Queue":[],"Reservations":[{"ID":"john","Reason":"done some coding",`+
+		`"Start":"2017-03-14T14:00:00Z","End":"2017-03-14T15:00:00Z"}]}}`, string(j))
+
+	var tokens Tokens
+	err = json.Unmarshal(j, &tokens)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, tokens.Equal(Tokens{DefaultToken: {Reservations: Reservations{reservation(John, 14, 1)}}}))
+}
@@ -0,0 +1,250 @@
+package queue
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+// DefaultToken is the name of the token used when no other is named
+const DefaultToken = "default"
+
+// Token is a queue for a token that can be held by up to Capacity items at once, along with any future bookings, any
+// requests to move entries that are waiting for agreement and any votes to oust the holders
+//
+// A Capacity of zero or one means the token can only be held by one item at a time.
+type Token struct {
+	Capacity     int
+	Queue        Queue
+	Reservations Reservations
+	Requests     Requests
+	Votes        Votes
+}
+
+// jsonToken is the full form of a Token written to JSON
+type jsonToken struct {
+	Capacity     int          `json:",omitempty"`
+	Queue        Queue        `json:"Queue"`
+	Reservations Reservations `json:",omitempty"`
+	Requests     Requests     `json:",omitempty"`
+	Votes        Votes        `json:",omitempty"`
+}
+
+// MarshalJSON writes a token that can only be held once and has no reservations, requests or votes as a bare queue
+func (t Token) MarshalJSON() ([]byte, error) {
+	q := t.Queue
+	if q == nil {
+		q = Queue{}
+	}
+
+	if t.Capacity <= 1 && len(t.Reservations) == 0 && len(t.Requests) == 0 && len(t.Votes) == 0 {
+		return json.Marshal(q)
+	}
+
+	return json.Marshal(jsonToken{t.Capacity, q, t.Reservations, t.Requests, t.Votes})
+}
+
+// UnmarshalJSON reads a token from either a bare queue or a queue with a capacity, reservations, requests and votes
+func (t *Token) UnmarshalJSON(b []byte) error {
+	var q Queue
+	if err := json.Unmarshal(b, &q); err == nil {
+		*t = Token{Queue: q}
+		return nil
+	}
+
+	var v jsonToken
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	*t = Token{Capacity: v.Capacity, Queue: v.Queue, Reservations: v.Reservations, Requests: v.Requests,
+		Votes: v.Votes}
+	return nil
+}
+
+// empty checks if a token has nothing worth keeping beyond its name
+func (t Token) empty() bool {
+	return len(t.Queue) == 0 && t.Capacity <= 1 && len(t.Reservations) == 0 && len(t.Requests) == 0 && len(t.Votes) == 0
+}
+
+// Tokens holds a separate Token for each named token in a channel, keyed on name
+type Tokens map[string]Token
+
+func (t Tokens) clone() Tokens {
+	nt := make(Tokens, len(t))
+	for k, v := range t {
+		nt[k] = v
+	}
+	return nt
+}
+
+// Exists returns true if a token with the given name exists
+//
+// The default token always exists.
+func (t Tokens) Exists(name string) bool {
+	if name == DefaultToken {
+		return true
+	}
+	_, ok := t[name]
+	return ok
+}
+
+// Get returns the queue for a token, or an empty queue if the token has none
+func (t Tokens) Get(name string) Queue {
+	if tok, ok := t[name]; ok && tok.Queue != nil {
+		return tok.Queue
+	}
+	return Queue{}
+}
+
+// Capacity returns the number of items that can hold a token at once
+func (t Tokens) Capacity(name string) int {
+	return slots(t[name].Capacity)
+}
+
+// SetCapacity returns a copy with the number of items that can hold a token at once replaced
+func (t Tokens) SetCapacity(name string, capacity int) Tokens {
+	nt := t.clone()
+	tok := nt[name]
+	tok.Capacity = slots(capacity)
+	nt[name] = tok
+	return nt.Set(name, t.Get(name))
+}
+
+// Set returns a copy with the queue for a token replaced, keeping its capacity, reservations and requests
+//
+// Named tokens are kept even when their queue is empty so that they are not forgotten. The default token is dropped
+// when its queue is empty unless it can be held by more than one item at once or has reservations or requests.
+func (t Tokens) Set(name string, q Queue) Tokens {
+	nt := t.clone()
+	tok := nt[name]
+	tok.Queue = q
+	if name == DefaultToken && tok.empty() {
+		delete(nt, name)
+		return nt
+	}
+	nt[name] = tok
+	return nt
+}
+
+// Reservations returns the bookings for a token in order of start time
+func (t Tokens) Reservations(name string) Reservations {
+	if rs := t[name].Reservations; rs != nil {
+		return rs
+	}
+	return Reservations{}
+}
+
+// SetReservations returns a copy with the bookings for a token replaced
+func (t Tokens) SetReservations(name string, rs Reservations) Tokens {
+	nt := t.clone()
+	tok := nt[name]
+	tok.Reservations = rs
+	nt[name] = tok
+	return nt.Set(name, t.Get(name))
+}
+
+// Requests returns the requests to move entries in a token queue in the order they were made
+func (t Tokens) Requests(name string) Requests {
+	if rs := t[name].Requests; rs != nil {
+		return rs
+	}
+	return Requests{}
+}
+
+// SetRequests returns a copy with the requests to move entries in a token queue replaced
+func (t Tokens) SetRequests(name string, rs Requests) Tokens {
+	nt := t.clone()
+	tok := nt[name]
+	tok.Requests = rs
+	nt[name] = tok
+	return nt.Set(name, t.Get(name))
+}
+
+// Votes returns the votes to oust the holders of a token in the order they were cast
+func (t Tokens) Votes(name string) Votes {
+	if vs := t[name].Votes; vs != nil {
+		return vs
+	}
+	return Votes{}
+}
+
+// SetVotes returns a copy with the votes to oust the holders of a token replaced
+func (t Tokens) SetVotes(name string, vs Votes) Tokens {
+	nt := t.clone()
+	tok := nt[name]
+	tok.Votes = vs
+	nt[name] = tok
+	return nt.Set(name, t.Get(name))
+}
+
+// Add returns a copy with a new named token with an empty queue, or the same tokens if it already exists
+func (t Tokens) Add(name string) Tokens {
+	if t.Exists(name) {
+		return t
+	}
+	return t.Set(name, Queue{})
+}
+
+// Remove returns a copy without the named token
+func (t Tokens) Remove(name string) Tokens {
+	nt := t.clone()
+	delete(nt, name)
+	return nt
+}
+
+// Names returns the names of all tokens in order, with the default token first
+func (t Tokens) Names() []string {
+	names := []string{}
+	for name := range t {
+		if name != DefaultToken {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return append([]string{DefaultToken}, names...)
+}
+
+// Equal checks if every token has the same queue, capacity, reservations, requests and votes as in another set of
+// tokens
+func (t Tokens) Equal(other Tokens) bool {
+	if len(t) != len(other) {
+		return false
+	}
+
+	for name, tok := range t {
+		o, ok := other[name]
+		if !ok || slots(tok.Capacity) != slots(o.Capacity) || !tok.Queue.Equal(o.Queue) ||
+			!tok.Reservations.Equal(o.Reservations) || !tok.Requests.Equal(o.Requests) ||
+			!tok.Votes.Equal(o.Votes) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// UnmarshalJSON reads a set of tokens, treating a bare queue as the default token
+func (t *Tokens) UnmarshalJSON(b []byte) error {
+	var q Queue
+	if err := json.Unmarshal(b, &q); err == nil {
+		*t = Tokens{}.Set(DefaultToken, q)
+		return nil
+	}
+
+	m := map[string]Token{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return err
+	}
+	*t = Tokens(m)
+	return nil
+}
+
+// Stamp sets the join and acquisition times of every token queue
+func (t Tokens) Stamp(now time.Time) Tokens {
+	nt := t.clone()
+	for name, tok := range t {
+		tok.Queue = tok.Queue.Stamp(now, tok.Capacity)
+		nt[name] = tok
+	}
+	return nt
+}
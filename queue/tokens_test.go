@@ -0,0 +1,116 @@
+package queue_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/doozr/qbot/queue"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultTokenAlwaysExists(t *testing.T) {
+	assert.Equal(t, true, Tokens{}.Exists(DefaultToken))
+}
+
+func TestNamedTokenExistsOnceAdded(t *testing.T) {
+	tokens := Tokens{}
+	assert.Equal(t, false, tokens.Exists("staging"))
+	tokens = tokens.Add("staging")
+	assert.Equal(t, true, tokens.Exists("staging"))
+}
+
+func TestAddKeepsExistingQueue(t *testing.T) {
+	tokens := Tokens{"staging": {Queue: Queue{John}}}
+	tokens = tokens.Add("staging")
+	assert.Equal(t, Queue{John}, tokens.Get("staging"))
+}
+
+func TestGetReturnsEmptyQueueForUnknownToken(t *testing.T) {
+	assert.Equal(t, Queue{}, Tokens{}.Get("staging"))
+}
+
+func TestSetTokenImmutable(t *testing.T) {
+	tokens := Tokens{DefaultToken: {Queue: Queue{John}}}
+	tokens.Set(DefaultToken, Queue{Jimmy})
+	assert.Equal(t, Tokens{DefaultToken: {Queue: Queue{John}}}, tokens)
+}
+
+func TestSetKeepsEmptyNamedToken(t *testing.T) {
+	tokens := Tokens{"staging": {Queue: Queue{John}}}
+	tokens = tokens.Set("staging", Queue{})
+	assert.Equal(t, Tokens{"staging": {Queue: Queue{}}}, tokens)
+}
+
+func TestSetDropsEmptyDefaultToken(t *testing.T) {
+	tokens := Tokens{DefaultToken: {Queue: Queue{John}}, "staging": {Queue: Queue{Mick}}}
+	tokens = tokens.Set(DefaultToken, Queue{})
+	assert.Equal(t, Tokens{"staging": {Queue: Queue{Mick}}}, tokens)
+}
+
+func TestRemoveToken(t *testing.T) {
+	tokens := Tokens{DefaultToken: {Queue: Queue{John}}, "staging": {Queue: Queue{}}}
+	tokens = tokens.Remove("staging")
+	assert.Equal(t, Tokens{DefaultToken: {Queue: Queue{John}}}, tokens)
+}
+
+func TestNamesPutsDefaultFirst(t *testing.T) {
+	tokens := Tokens{"prod": {Queue: Queue{}}, "perf": {Queue: Queue{John}}, "staging": {Queue: Queue{Mick}}}
+	assert.Equal(t, []string{DefaultToken, "perf", "prod", "staging"}, tokens.Names())
+}
+
+func TestUnmarshalNamedTokens(t *testing.T) {
+	var tokens Tokens
+	err := json.Unmarshal([]byte(`{"default":[{"ID":"john","Reason":"done some coding"}],"staging":[]}`), &tokens)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, Tokens{DefaultToken: {Queue: Queue{John}}, "staging": {Queue: Queue{}}}, tokens)
+}
+
+func TestUnmarshalBareQueueAsDefaultToken(t *testing.T) {
+	var tokens Tokens
+	err := json.Unmarshal([]byte(`[{"ID":"john","Reason":"done some coding"}]`), &tokens)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, Tokens{DefaultToken: {Queue: Queue{John}}}, tokens)
+}
+
+func TestCapacityDefaultsToOne(t *testing.T) {
+	assert.Equal(t, 1, Tokens{}.Capacity("staging"))
+	assert.Equal(t, 1, Tokens{"staging": {Queue: Queue{}}}.Capacity("staging"))
+}
+
+func TestSetKeepsCapacity(t *testing.T) {
+	tokens := Tokens{"envs": {Capacity: 3, Queue: Queue{}}}
+	tokens = tokens.Set("envs", Queue{John})
+	assert.Equal(t, Tokens{"envs": {Capacity: 3, Queue: Queue{John}}}, tokens)
+}
+
+func TestSetCapacityKeepsQueue(t *testing.T) {
+	tokens := Tokens{"envs": {Queue: Queue{John}}}
+	tokens = tokens.SetCapacity("envs", 3)
+	assert.Equal(t, Tokens{"envs": {Capacity: 3, Queue: Queue{John}}}, tokens)
+}
+
+func TestSetKeepsEmptyDefaultTokenWithCapacity(t *testing.T) {
+	tokens := Tokens{}.SetCapacity(DefaultToken, 2)
+	assert.Equal(t, Tokens{DefaultToken: {Capacity: 2, Queue: Queue{}}}, tokens)
+	tokens = tokens.SetCapacity(DefaultToken, 1)
+	assert.Equal(t, Tokens{}, tokens)
+}
+
+func TestUnequalIfDifferentCapacity(t *testing.T) {
+	tokens := Tokens{"envs": {Capacity: 3, Queue: Queue{John}}}
+	assert.Equal(t, false, tokens.Equal(Tokens{"envs": {Queue: Queue{John}}}))
+	assert.Equal(t, true, tokens.Equal(Tokens{"envs": {Capacity: 3, Queue: Queue{John}}}))
+}
+
+func TestMarshalTokenWithCapacity(t *testing.T) {
+	j, err := json.Marshal(Tokens{DefaultToken: {Queue: Queue{John}}, "envs": {Capacity: 3, Queue: Queue{}}})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, `{"default":[{"ID":"john","Reason":"done some coding"}],"envs":{"Capacity":3,"Queue":[]}}`, string(j))
+}
+
+func TestUnmarshalTokenWithCapacity(t *testing.T) {
+	var tokens Tokens
+	err := json.Unmarshal([]byte(`{"envs":{"Capacity":3,"Queue":[{"ID":"john","Reason":"done some coding"}]}}`), &tokens)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, Tokens{"envs": {Capacity: 3, Queue: Queue{John}}}, tokens)
+}
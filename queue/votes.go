@@ -0,0 +1,73 @@
+package queue
+
+import "time"
+
+// Vote is a user's vote to oust the entry holding a token
+type Vote struct {
+	ID   string
+	Item Item
+	At   time.Time
+}
+
+// Votes is a list of Vote objects in the order they were cast
+type Votes []Vote
+
+// Equal checks if two lists hold the same votes in the same order
+func (vs Votes) Equal(other Votes) bool {
+	if len(vs) != len(other) {
+		return false
+	}
+
+	for ix := range vs {
+		a, b := vs[ix], other[ix]
+		if a.ID != b.ID || a.Item != b.Item || !a.At.Equal(b.At) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// For returns the votes to oust an entry
+func (vs Votes) For(i Item) Votes {
+	votes := Votes{}
+	for _, v := range vs {
+		if v.Item.Is(i) {
+			votes = append(votes, v)
+		}
+	}
+	return votes
+}
+
+// Add returns a copy with a new vote, replacing any earlier vote by the same user to oust the same entry
+func (vs Votes) Add(v Vote) Votes {
+	nvs := Votes{}
+	for _, e := range vs {
+		if e.ID != v.ID || !e.Item.Is(v.Item) {
+			nvs = append(nvs, e)
+		}
+	}
+	return append(nvs, v)
+}
+
+// Clear returns a copy without the votes to oust an entry
+func (vs Votes) Clear(i Item) Votes {
+	nvs := Votes{}
+	for _, v := range vs {
+		if !v.Item.Is(i) {
+			nvs = append(nvs, v)
+		}
+	}
+	return nvs
+}
+
+// Since returns the votes cast at or after a time
+func (vs Votes) Since(t time.Time) Votes {
+	nvs := Votes{}
+	for _, v := range vs {
+		if !v.At.Before(t) {
+			nvs = append(nvs, v)
+		}
+	}
+	return nvs
+}
@@ -0,0 +1,53 @@
+package queue_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	. "github.com/doozr/qbot/queue"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVotesAddReplacesVoteBySameUserForSameEntry(t *testing.T) {
+	vs := Votes{}.
+		Add(Vote{ID: "colin", Item: John, At: now}).
+		Add(Vote{ID: "jimmy", Item: John, At: now}).
+		Add(Vote{ID: "colin", Item: John, At: now.Add(time.Minute)}).
+		Add(Vote{ID: "colin", Item: Mick, At: now})
+	assert.Equal(t, Votes{
+		{ID: "jimmy", Item: John, At: now},
+		{ID: "colin", Item: John, At: now.Add(time.Minute)},
+		{ID: "colin", Item: Mick, At: now},
+	}, vs)
+}
+
+func TestVotesFor(t *testing.T) {
+	vs := Votes{{ID: "colin", Item: John}, {ID: "jimmy", Item: Mick}, {ID: "mick", Item: John}}
+	assert.Equal(t, Votes{vs[0], vs[2]}, vs.For(John))
+	assert.Equal(t, Votes{}, vs.For(Colin))
+}
+
+func TestVotesClear(t *testing.T) {
+	vs := Votes{{ID: "colin", Item: John}, {ID: "jimmy", Item: Mick}, {ID: "mick", Item: John}}
+	assert.Equal(t, Votes{vs[1]}, vs.Clear(John))
+	assert.Equal(t, 3, len(vs), "original votes should not change")
+}
+
+func TestVotesSince(t *testing.T) {
+	vs := Votes{{ID: "colin", Item: John, At: now}, {ID: "jimmy", Item: John, At: now.Add(time.Minute)}}
+	assert.Equal(t, vs, vs.Since(now))
+	assert.Equal(t, Votes{vs[1]}, vs.Since(now.Add(time.Second)))
+}
+
+func TestMarshalTokenWithVotes(t *testing.T) {
+	tokens := Tokens{DefaultToken: {Queue: Queue{John, Colin}, Votes: Votes{{ID: "colin", Item: John, At: now}}}}
+	j, err := json.Marshal(tokens)
+	assert.Equal(t, nil, err)
+
+	var read Tokens
+	err = json.Unmarshal(j, &read)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, tokens.Equal(read))
+	assert.Equal(t, false, tokens.Equal(tokens.SetVotes(DefaultToken, Votes{})))
+}
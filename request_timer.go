@@ -0,0 +1,33 @@
+package qbot
+
+import (
+	"time"
+
+	"github.com/doozr/qbot/command"
+	"github.com/doozr/qbot/queue"
+)
+
+// CreateRequestTimer creates a TickHandler that drops requests to move or swap entries once they have not been
+// agreed in time.
+func CreateRequestTimer(commands command.QueueCommands, notify Notifier) TickHandler {
+	return func(oqs queue.Channels, now time.Time) (qs queue.Channels, err error) {
+		qs = oqs
+
+		for channel, t := range oqs {
+			nt := t
+			for _, name := range t.Names() {
+				for _, r := range t.Requests(name).Expired(now) {
+					var n command.Notification
+					nt, n = commands.RequestExpired(nt, channel, name, r)
+
+					err = notify(n)
+					if err != nil {
+						return
+					}
+				}
+			}
+			qs = qs.Set(channel, nt)
+		}
+		return
+	}
+}
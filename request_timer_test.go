@@ -0,0 +1,68 @@
+package qbot_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/doozr/qbot"
+	"github.com/doozr/qbot/command"
+	"github.com/doozr/qbot/queue"
+)
+
+var requestExpires = time.Date(2017, 3, 14, 14, 0, 0, 0, time.UTC)
+
+func getRequestQueue() queue.Channels {
+	potato := queue.Item{ID: "U456", Reason: "Potato", Key: "lqy"}
+	tomato := queue.Item{ID: "U123", Reason: "Tomato", Key: "jox"}
+	return queue.Channels{"C1234": queue.Tokens{queue.DefaultToken: {
+		Queue:    queue.Queue{{ID: "U123", Reason: "Release", Key: "ecf"}, potato, tomato},
+		Requests: queue.Requests{{ID: "U123", Item: tomato, Target: potato, Swap: true, Expires: requestExpires}},
+	}}}
+}
+
+func createTestRequestTimer() (TickHandler, *[]command.Notification) {
+	notifications := []command.Notification{}
+	notify := func(n command.Notification) error {
+		notifications = append(notifications, n)
+		return nil
+	}
+	return CreateRequestTimer(timerCommands, notify), &notifications
+}
+
+func TestRequestTimerDoesNothingBeforeExpiry(t *testing.T) {
+	handleTick, notifications := createTestRequestTimer()
+
+	qs, err := handleTick(getRequestQueue(), requestExpires.Add(-time.Second))
+	if err != nil {
+		t.Fatal("Unexpected error ", err)
+	}
+
+	if len(*notifications) != 0 {
+		t.Fatal("Unexpected notifications ", *notifications)
+	}
+
+	if !qs.Equal(getRequestQueue()) {
+		t.Fatal("Unexpected queue ", qs)
+	}
+}
+
+func TestRequestTimerDropsExpiredRequests(t *testing.T) {
+	handleTick, notifications := createTestRequestTimer()
+
+	qs, _ := handleTick(getRequestQueue(), requestExpires)
+
+	expectedQueue := getRequestQueue()
+	expectedQueue = expectedQueue.Set("C1234", expectedQueue.Get("C1234").SetRequests(queue.DefaultToken, queue.Requests{}))
+	if !qs.Equal(expectedQueue) {
+		t.Fatal("Unexpected queue ", qs)
+	}
+
+	if len(*notifications) != 1 {
+		t.Fatal("Expected 1 notification, got ", *notifications)
+	}
+
+	expected := "<@U123|craig> (Tomato) asked to swap places with <@U456|edward> (Potato), but it was not agreed in time"
+	if (*notifications)[0].Message != expected || (*notifications)[0].Channel != "C1234" {
+		t.Fatal("Unexpected notification ", (*notifications)[0])
+	}
+}
@@ -0,0 +1,45 @@
+package qbot
+
+import (
+	"time"
+
+	"github.com/doozr/qbot/command"
+	"github.com/doozr/qbot/queue"
+)
+
+// CreateReservationTimer creates a TickHandler that hands each reserved token over when its window opens and takes
+// it back when the window closes.
+//
+// Reservations are saved with the queues, so any window that opened or closed while the bot was down is acted on at
+// the first tick after a restart.
+func CreateReservationTimer(commands command.QueueCommands, notify Notifier) TickHandler {
+	return func(oqs queue.Channels, now time.Time) (qs queue.Channels, err error) {
+		qs = oqs
+
+		for channel, t := range oqs {
+			nt := t
+			for _, name := range t.Names() {
+				for _, r := range t.Reservations(name) {
+					var n command.Notification
+					switch {
+					case !now.Before(r.End):
+						nt, n = commands.ReservationEnded(nt, channel, name, r)
+					case !r.Started && !now.Before(r.Start):
+						nt, n = commands.ReservationStarted(nt, channel, name, r)
+					default:
+						continue
+					}
+
+					err = notify(n)
+					if err != nil {
+						return
+					}
+				}
+			}
+			qs = qs.Set(channel, nt)
+		}
+
+		qs = qs.Stamp(now)
+		return
+	}
+}
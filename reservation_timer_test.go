@@ -0,0 +1,153 @@
+package qbot_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	. "github.com/doozr/qbot"
+	"github.com/doozr/qbot/command"
+	"github.com/doozr/qbot/queue"
+)
+
+var windowStart = time.Date(2017, 3, 14, 14, 0, 0, 0, time.UTC)
+
+func getReservedQueue() queue.Channels {
+	return queue.Channels{"C1234": queue.Tokens{queue.DefaultToken: {
+		Queue: queue.Queue{{ID: "U456", Reason: "Potato", Key: "lqy", JoinedAt: acquired, ActiveSince: acquired}},
+		Reservations: queue.Reservations{
+			{ID: "U123", Reason: "Release", Start: windowStart, End: windowStart.Add(time.Hour)},
+		},
+	}}}
+}
+
+func createTestReservationTimer() (TickHandler, *[]command.Notification) {
+	notifications := []command.Notification{}
+	notify := func(n command.Notification) error {
+		notifications = append(notifications, n)
+		return nil
+	}
+	return CreateReservationTimer(timerCommands, notify), &notifications
+}
+
+func TestReservationTimerDoesNothingBeforeWindow(t *testing.T) {
+	handleTick, notifications := createTestReservationTimer()
+
+	qs, err := handleTick(getReservedQueue(), windowStart.Add(-time.Minute))
+	if err != nil {
+		t.Fatal("Unexpected error ", err)
+	}
+
+	if len(*notifications) != 0 {
+		t.Fatal("Unexpected notifications ", *notifications)
+	}
+
+	if !qs.Equal(getReservedQueue()) {
+		t.Fatal("Unexpected queue ", qs)
+	}
+}
+
+func TestReservationTimerHandsOverTokenWhenWindowOpens(t *testing.T) {
+	handleTick, notifications := createTestReservationTimer()
+	now := windowStart.Add(10 * time.Second)
+
+	qs, _ := handleTick(getReservedQueue(), now)
+
+	expectedQueue := queue.Channels{"C1234": queue.Tokens{queue.DefaultToken: {
+		Queue: queue.Queue{
+			{ID: "U123", Reason: "Release", Key: "ecf", JoinedAt: now, ActiveSince: now},
+			{ID: "U456", Reason: "Potato", Key: "lqy", JoinedAt: acquired},
+		},
+		Reservations: queue.Reservations{
+			{ID: "U123", Reason: "Release", Start: windowStart, End: windowStart.Add(time.Hour), Started: true},
+		},
+	}}}
+	if !qs.Equal(expectedQueue) {
+		t.Fatal("Unexpected queue ", expectedQueue, qs)
+	}
+
+	expected := []command.Notification{
+		{Channel: "C1234", Message: "<@U123|craig> (Release) has reserved the token for Tue 14 Mar 14:00-15:00\n" +
+			"*<@U123|craig> (Release) now has the token*\n<@U456|edward> (Potato) is now next in line"},
+	}
+	if !reflect.DeepEqual(expected, *notifications) {
+		t.Fatal("Unexpected notifications ", expected, *notifications)
+	}
+
+	handleTick(qs, now.Add(30*time.Second))
+	if len(*notifications) != 1 {
+		t.Fatal("Expected token to be handed over once ", *notifications)
+	}
+}
+
+func TestReservationTimerReleasesTokenWhenWindowCloses(t *testing.T) {
+	handleTick, notifications := createTestReservationTimer()
+	now := windowStart.Add(time.Hour)
+
+	qs, _ := handleTick(getReservedQueue(), windowStart)
+	qs, _ = handleTick(qs, now)
+
+	expectedQueue := queue.Channels{"C1234": queue.Tokens{queue.DefaultToken: {
+		Queue: queue.Queue{{ID: "U456", Reason: "Potato", Key: "lqy", JoinedAt: acquired, ActiveSince: now}},
+	}}}
+	if !qs.Equal(expectedQueue) {
+		t.Fatal("Unexpected queue ", expectedQueue, qs)
+	}
+
+	expected := command.Notification{Channel: "C1234", Message: "The reservation for Tue 14 Mar 14:00-15:00 has ended\n" +
+		"<@U123|craig> (Release) has finished with the token\n*<@U456|edward> (Potato) now has the token*"}
+	if len(*notifications) != 2 || !reflect.DeepEqual(expected, (*notifications)[1]) {
+		t.Fatal("Unexpected notifications ", expected, *notifications)
+	}
+}
+
+func TestReservationTimerForgetsWindowMissedWhileDown(t *testing.T) {
+	handleTick, notifications := createTestReservationTimer()
+
+	qs, _ := handleTick(getReservedQueue(), windowStart.Add(2*time.Hour))
+
+	expectedQueue := queue.Channels{"C1234": queue.Tokens{queue.DefaultToken: {
+		Queue: queue.Queue{{ID: "U456", Reason: "Potato", Key: "lqy", JoinedAt: acquired, ActiveSince: acquired}},
+	}}}
+	if !qs.Equal(expectedQueue) {
+		t.Fatal("Unexpected queue ", expectedQueue, qs)
+	}
+
+	for _, n := range *notifications {
+		if n.Message != "" {
+			t.Fatal("Unexpected notifications ", *notifications)
+		}
+	}
+}
+
+func TestReservationTimerReturnsErrorIfNotifyFails(t *testing.T) {
+	notify := func(n command.Notification) error {
+		return fmt.Errorf("Error!")
+	}
+	handleTick := CreateReservationTimer(timerCommands, notify)
+
+	_, err := handleTick(getReservedQueue(), windowStart)
+	if err == nil {
+		t.Fatal("Expected error")
+	}
+}
+
+func TestChainTickHandlers(t *testing.T) {
+	calls := []string{}
+	handler := func(name string) TickHandler {
+		return func(qs queue.Channels, now time.Time) (queue.Channels, error) {
+			calls = append(calls, name)
+			return qs.Set(name, queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{{ID: "U123", Reason: name}}}}), nil
+		}
+	}
+
+	qs, err := ChainTickHandlers(handler("C1"), handler("C2"))(queue.Channels{}, windowStart)
+	if err != nil {
+		t.Fatal("Unexpected error ", err)
+	}
+
+	if !reflect.DeepEqual([]string{"C1", "C2"}, calls) || len(qs) != 2 {
+		t.Fatal("Expected both handlers to run in order ", calls, qs)
+	}
+}
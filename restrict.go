@@ -0,0 +1,20 @@
+package qbot
+
+import "github.com/doozr/qbot/command"
+
+// RestrictCommands returns a copy of a CommandMap in which the named commands may only be used by admins.
+//
+// Names that are not in the map are ignored.
+func RestrictCommands(commandMap CommandMap, names []string, commands command.QueueCommands) CommandMap {
+	restricted := CommandMap{}
+	for cmd, fn := range commandMap {
+		restricted[cmd] = fn
+	}
+
+	for _, cmd := range names {
+		if fn, ok := commandMap[cmd]; ok {
+			restricted[cmd] = commands.AdminOnly(cmd, fn)
+		}
+	}
+	return restricted
+}
@@ -0,0 +1,40 @@
+package qbot_test
+
+import (
+	"testing"
+
+	"github.com/doozr/qbot/command"
+	"github.com/doozr/qbot/queue"
+
+	. "github.com/doozr/qbot"
+)
+
+func TestRestrictCommandsOnlyLetsAdminsUseNamedCommands(t *testing.T) {
+	calls := 0
+	fn := func(t queue.Tokens, ch, id, args string) (queue.Tokens, command.Notification) {
+		calls++
+		return t, command.Notification{Channel: ch, Message: "Done"}
+	}
+
+	commands := timerCommands.WithAdmins([]string{"U123"})
+	restricted := RestrictCommands(CommandMap{"boot": fn, "list": fn}, []string{"boot", "clear"}, commands)
+
+	if _, ok := restricted["clear"]; ok {
+		t.Fatal("Expected unknown command to be ignored")
+	}
+
+	_, n := restricted["boot"](queue.Tokens{}, "C1234", "U456", "")
+	if calls != 0 || n.Message != "<@U456|edward> Sorry, only admins may use `boot` in this channel" {
+		t.Fatal("Expected refusal, got ", n)
+	}
+
+	_, n = restricted["boot"](queue.Tokens{}, "C1234", "U123", "")
+	if calls != 1 || n.Message != "Done" {
+		t.Fatal("Expected admin to use command, got ", n)
+	}
+
+	_, n = restricted["list"](queue.Tokens{}, "C1234", "U456", "")
+	if calls != 2 || n.Message != "Done" {
+		t.Fatal("Expected anybody to use unrestricted command, got ", n)
+	}
+}
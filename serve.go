@@ -0,0 +1,28 @@
+package qbot
+
+import (
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/doozr/jot"
+)
+
+// StartServer runs an HTTP server until done.
+func StartServer(server *http.Server, done DoneChan, waitGroup *sync.WaitGroup) {
+	jot.Print("qbot.server starting up")
+	waitGroup.Add(1)
+	go func() {
+		err := server.ListenAndServe()
+		if err != nil && err != http.ErrServerClosed {
+			log.Printf("Error while serving HTTP on %s: %s", server.Addr, err)
+		}
+		jot.Print("qbot.server done")
+		waitGroup.Done()
+	}()
+
+	go func() {
+		<-done
+		server.Close()
+	}()
+}
@@ -0,0 +1,22 @@
+package qbot
+
+import (
+	"time"
+
+	"github.com/doozr/qbot/journal"
+	"github.com/doozr/qbot/queue"
+)
+
+// CreateJournaledTickHandler creates a tick handler that calls another and records what changed against the given
+// actor and command.
+func CreateJournaledTickHandler(fn TickHandler, actor, command string, record Journal) TickHandler {
+	return func(oqs queue.Channels, now time.Time) (qs queue.Channels, err error) {
+		qs, err = fn(oqs, now)
+		if err != nil {
+			return
+		}
+
+		err = record(journal.Diff(oqs, qs, actor, command, now))
+		return
+	}
+}
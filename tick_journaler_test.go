@@ -0,0 +1,61 @@
+package qbot_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/doozr/qbot"
+	"github.com/doozr/qbot/journal"
+	"github.com/doozr/qbot/queue"
+)
+
+func TestJournaledTickHandlerRecordsChangesAgainstActor(t *testing.T) {
+	now := time.Date(2017, 3, 14, 12, 0, 0, 0, time.UTC)
+	before := queue.Channels{"C1234": queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{{ID: "U1234", Reason: "Tomato"}}}}}
+
+	fn := func(qs queue.Channels, now time.Time) (queue.Channels, error) {
+		return queue.Channels{}, nil
+	}
+
+	var recorded []journal.Event
+	record := func(events []journal.Event) error {
+		recorded = events
+		return nil
+	}
+
+	handler := CreateJournaledTickHandler(fn, "UBOT", "hold", record)
+	handler(before, now)
+
+	if len(recorded) != 1 {
+		t.Fatal("Expected 1 event, got ", recorded)
+	}
+
+	e := recorded[0]
+	if e.Actor != "UBOT" || e.Command != "hold" || !e.Time.Equal(now) || e.Before != 1 || e.After != 0 {
+		t.Fatal("Unexpected event", e)
+	}
+}
+
+func TestJournaledTickHandlerDoesNotRecordOnError(t *testing.T) {
+	fn := func(qs queue.Channels, now time.Time) (queue.Channels, error) {
+		return nil, fmt.Errorf("Error!")
+	}
+
+	calls := 0
+	record := func(events []journal.Event) error {
+		calls++
+		return nil
+	}
+
+	handler := CreateJournaledTickHandler(fn, "UBOT", "hold", record)
+	_, err := handler(queue.Channels{}, time.Now())
+
+	if err == nil {
+		t.Fatal("Expected error")
+	}
+
+	if calls != 0 {
+		t.Fatal("Expected no calls to record, got ", calls)
+	}
+}
@@ -0,0 +1,20 @@
+package qbot
+
+import (
+	"time"
+
+	"github.com/doozr/qbot/queue"
+)
+
+// CreatePersistedTickHandler creates a tick handler that calls another and persists the result
+func CreatePersistedTickHandler(fn TickHandler, persist Persister) TickHandler {
+	return func(oqs queue.Channels, now time.Time) (qs queue.Channels, err error) {
+		qs, err = fn(oqs, now)
+		if err != nil {
+			return
+		}
+
+		err = persist(qs)
+		return
+	}
+}
@@ -0,0 +1,58 @@
+package qbot_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/doozr/qbot"
+	"github.com/doozr/qbot/queue"
+)
+
+func TestPersistsQueueAfterTick(t *testing.T) {
+	expectedQueue := queue.Channels{"C1A2B3C": queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{{ID: "U123", Reason: "Tomato"}}}}}
+
+	fn := func(qs queue.Channels, now time.Time) (queue.Channels, error) {
+		return expectedQueue, nil
+	}
+
+	var receivedQueue queue.Channels
+	persist := func(qs queue.Channels) error {
+		receivedQueue = qs
+		return nil
+	}
+
+	handler := CreatePersistedTickHandler(fn, persist)
+	returnedQueue, _ := handler(queue.Channels{}, time.Now())
+
+	if !expectedQueue.Equal(receivedQueue) {
+		t.Fatal("Unexpected queue persisted", expectedQueue, receivedQueue)
+	}
+
+	if !expectedQueue.Equal(returnedQueue) {
+		t.Fatal("Unexpected queue returned", expectedQueue, returnedQueue)
+	}
+}
+
+func TestDoesNotPersistOnTickError(t *testing.T) {
+	fn := func(qs queue.Channels, now time.Time) (queue.Channels, error) {
+		return nil, fmt.Errorf("Error!")
+	}
+
+	calls := 0
+	persist := func(qs queue.Channels) error {
+		calls++
+		return nil
+	}
+
+	handler := CreatePersistedTickHandler(fn, persist)
+	_, err := handler(queue.Channels{}, time.Now())
+
+	if calls != 0 {
+		t.Fatal("Expected 0 calls, received ", calls)
+	}
+
+	if err == nil {
+		t.Fatal("Expected error")
+	}
+}
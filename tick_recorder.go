@@ -0,0 +1,38 @@
+package qbot
+
+import (
+	"time"
+
+	"github.com/doozr/qbot/history"
+	"github.com/doozr/qbot/queue"
+)
+
+// CreateRecordedTickHandler creates a tick handler that calls another and remembers any change it made to the tokens
+// of each channel against the given actor and command.
+//
+// Nobody asked for these changes, but remembering them means that undo never rolls back over them and can say who
+// made them when it has to refuse.
+func CreateRecordedTickHandler(fn TickHandler, actor, command string, h history.History) TickHandler {
+	return func(oqs queue.Channels, now time.Time) (qs queue.Channels, err error) {
+		qs, err = fn(oqs, now)
+		if err != nil {
+			return
+		}
+
+		for _, channel := range allChannels(oqs, qs) {
+			before, after := oqs.Get(channel), qs.Get(channel)
+			if before.Equal(after) {
+				continue
+			}
+
+			h.Record(channel, history.Change{
+				Actor:   actor,
+				Command: command,
+				Time:    now,
+				Before:  before,
+				After:   after,
+			})
+		}
+		return
+	}
+}
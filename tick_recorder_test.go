@@ -0,0 +1,55 @@
+package qbot_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/doozr/qbot"
+	"github.com/doozr/qbot/history"
+	"github.com/doozr/qbot/queue"
+)
+
+func TestRecordsTickChangeToEachChannel(t *testing.T) {
+	now := time.Date(2017, 3, 14, 12, 0, 0, 0, time.UTC)
+	tomato := queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{{ID: "U1234", Reason: "Tomato"}}}}
+	before := queue.Channels{"C1234": tomato, "C5678": tomato}
+	after := queue.Channels{"C1234": queue.Tokens{}, "C5678": tomato}
+
+	fn := func(qs queue.Channels, now time.Time) (queue.Channels, error) {
+		return after, nil
+	}
+
+	h := history.New(10)
+	handler := CreateRecordedTickHandler(fn, "U999", "hold", h)
+	handler(before, now)
+
+	changes := h.Changes("C1234")
+	if len(changes) != 1 {
+		t.Fatal("Expected 1 change, got ", changes)
+	}
+
+	c := changes[0]
+	if c.Actor != "U999" || c.Command != "hold" || !c.Time.Equal(now) ||
+		!c.Before.Equal(tomato) || !c.After.Equal(queue.Tokens{}) {
+		t.Fatal("Unexpected change ", c)
+	}
+
+	if len(h.Changes("C5678")) != 0 {
+		t.Fatal("Expected no changes to channel that did not change, got ", h.Changes("C5678"))
+	}
+}
+
+func TestDoesNotRecordTickOnError(t *testing.T) {
+	fn := func(qs queue.Channels, now time.Time) (queue.Channels, error) {
+		return queue.Channels{"C1234": queue.Tokens{}}, fmt.Errorf("Error!")
+	}
+
+	h := history.New(10)
+	handler := CreateRecordedTickHandler(fn, "U999", "hold", h)
+	handler(queue.Channels{}, time.Now())
+
+	if len(h.Changes("C1234")) != 0 {
+		t.Fatal("Expected no changes, got ", h.Changes("C1234"))
+	}
+}
@@ -0,0 +1,21 @@
+package qbot
+
+import (
+	"time"
+
+	"github.com/doozr/qbot/queue"
+)
+
+// CreateWatchedTickHandler creates a tick handler that calls another and tells a watcher what changed and with which
+// command, with no actor as nobody made the change.
+func CreateWatchedTickHandler(fn TickHandler, command string, watch Watcher) TickHandler {
+	return func(oqs queue.Channels, now time.Time) (qs queue.Channels, err error) {
+		qs, err = fn(oqs, now)
+		if err != nil {
+			return
+		}
+
+		err = watch(oqs, qs, "", command)
+		return
+	}
+}
@@ -0,0 +1,152 @@
+package qbot
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/doozr/qbot/command"
+	"github.com/doozr/qbot/queue"
+)
+
+// TickHandler handles the regular passing of time.
+type TickHandler func(queue.Channels, time.Time) (queue.Channels, error)
+
+// ChainTickHandlers creates a TickHandler that passes the queues through each handler in turn, stopping at the first
+// error.
+func ChainTickHandlers(handlers ...TickHandler) TickHandler {
+	return func(qs queue.Channels, now time.Time) (_ queue.Channels, err error) {
+		for _, handleTick := range handlers {
+			qs, err = handleTick(qs, now)
+			if err != nil {
+				break
+			}
+		}
+		return qs, err
+	}
+}
+
+// DefaultHoldGrace is how long a token holder has to say `done` after a reminder when no grace period is given.
+const DefaultHoldGrace = 15 * time.Minute
+
+// HoldLimits configures how long somebody may hold a token before they are chased.
+//
+// The holder is sent a reminder once they have had the token for Hold. The channel is told after a further Escalate,
+// or at the same time if Escalate is zero, and the holder is ousted once Grace has passed since the reminder, or
+// DefaultHoldGrace if Grace is zero. A zero Hold turns the limits off.
+type HoldLimits struct {
+	Hold     time.Duration
+	Escalate time.Duration
+	Grace    time.Duration
+}
+
+type holdStage int
+
+const (
+	holding holdStage = iota
+	reminded
+	escalated
+	expired
+)
+
+// holdChase is how far a holder has been chased, and the time their hold is counted from.
+type holdChase struct {
+	stage holdStage
+	since time.Time
+}
+
+// GracePeriod is how long the holder has to say `done` after a reminder, which is DefaultHoldGrace if Grace is zero so
+// that a holder is always reminded before being ousted.
+func (l HoldLimits) GracePeriod() time.Duration {
+	if l.Grace <= 0 {
+		return DefaultHoldGrace
+	}
+	return l.Grace
+}
+
+func (l HoldLimits) stage(held time.Duration) holdStage {
+	switch {
+	case l.Hold <= 0 || held < l.Hold:
+		return holding
+	case held >= l.Hold+l.GracePeriod():
+		return expired
+	case l.Escalate > 0 && held >= l.Hold+l.Escalate:
+		return escalated
+	default:
+		return reminded
+	}
+}
+
+func (l HoldLimits) remaining(held time.Duration) time.Duration {
+	return l.Hold + l.GracePeriod() - held
+}
+
+// CreateHoldTimer creates a TickHandler that chases and eventually ousts each holder of a token who holds it too long.
+//
+// How far each holder has been chased is worked out from when they acquired the token, so the timers carry on where
+// they left off after a restart. A holder who has not been reminded since the bot started, such as after a restart or
+// when the limits are first turned on, is always reminded before anything else and given the whole grace period from
+// then.
+func CreateHoldTimer(limits HoldLimits, commands command.QueueCommands, notify Notifier) TickHandler {
+	notified := map[string]holdChase{}
+
+	return func(oqs queue.Channels, now time.Time) (qs queue.Channels, err error) {
+		qs = oqs
+		seen := map[string]holdChase{}
+		defer func() { notified = seen }()
+
+		for channel, t := range oqs {
+			nt := t
+			for name, tok := range t {
+				for _, i := range tok.Queue.Holders(tok.Capacity) {
+					if i.ActiveSince.IsZero() {
+						continue
+					}
+
+					key := fmt.Sprintf("%s/%s/%s/%d", channel, name, i.ID, i.ActiveSince.UnixNano())
+					chase, ok := notified[key]
+					if !ok {
+						chase.since = i.ActiveSince
+					}
+
+					stage := limits.stage(now.Sub(chase.since))
+					if stage > reminded && chase.stage < reminded {
+						chase.since = now.Add(-limits.Hold)
+						stage = reminded
+					}
+					if stage <= chase.stage {
+						seen[key] = chase
+						continue
+					}
+					seen[key] = holdChase{stage, chase.since}
+
+					held, remaining := now.Sub(i.ActiveSince), limits.remaining(now.Sub(chase.since))
+					var ns []command.Notification
+					switch stage {
+					case reminded:
+						ns = append(ns, commands.HoldReminder(nt, channel, name, i, held, remaining))
+						if limits.Escalate <= 0 {
+							ns = append(ns, commands.HoldEscalation(nt, channel, name, i, held, remaining))
+						}
+					case escalated:
+						ns = append(ns, commands.HoldEscalation(nt, channel, name, i, held, remaining))
+					case expired:
+						var n command.Notification
+						nt, n = commands.HoldExpired(nt, channel, name, i)
+						ns = append(ns, n)
+					}
+
+					for _, n := range ns {
+						err = notify(n)
+						if err != nil {
+							return
+						}
+					}
+				}
+			}
+			qs = qs.Set(channel, nt)
+		}
+
+		qs = qs.Stamp(now)
+		return
+	}
+}
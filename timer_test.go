@@ -0,0 +1,234 @@
+package qbot_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/doozr/guac"
+	. "github.com/doozr/qbot"
+	"github.com/doozr/qbot/command"
+	"github.com/doozr/qbot/queue"
+	"github.com/doozr/qbot/usercache"
+)
+
+var timerCommands = command.New("U999", "qbot", usercache.New([]guac.UserInfo{
+	{ID: "U123", Name: "craig"},
+	{ID: "U456", Name: "edward"},
+	{ID: "U999", Name: "qbot"},
+}))
+
+var acquired = time.Date(2017, 3, 14, 9, 0, 0, 0, time.UTC)
+
+func getTimerQueue() queue.Channels {
+	return queue.Channels{"C1234": queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{
+		{ID: "U123", Reason: "Tomato", Key: "jox", JoinedAt: acquired, ActiveSince: acquired},
+		{ID: "U456", Reason: "Potato", Key: "lqy", JoinedAt: acquired},
+	}}}}
+}
+
+func createTestHoldTimer(limits HoldLimits) (TickHandler, *[]command.Notification) {
+	notifications := []command.Notification{}
+	notify := func(n command.Notification) error {
+		notifications = append(notifications, n)
+		return nil
+	}
+	return CreateHoldTimer(limits, timerCommands, notify), &notifications
+}
+
+func TestTimerDoesNothingWithoutLimits(t *testing.T) {
+	handleTick, notifications := createTestHoldTimer(HoldLimits{})
+
+	qs, err := handleTick(getTimerQueue(), acquired.Add(24*time.Hour))
+	if err != nil {
+		t.Fatal("Unexpected error ", err)
+	}
+
+	if len(*notifications) != 0 {
+		t.Fatal("Unexpected notifications ", *notifications)
+	}
+
+	if !qs.Equal(getTimerQueue()) {
+		t.Fatal("Unexpected queue ", qs)
+	}
+}
+
+func TestTimerDoesNothingBeforeHoldTime(t *testing.T) {
+	handleTick, notifications := createTestHoldTimer(HoldLimits{Hold: time.Hour, Grace: 30 * time.Minute})
+
+	handleTick(getTimerQueue(), acquired.Add(59*time.Minute))
+
+	if len(*notifications) != 0 {
+		t.Fatal("Unexpected notifications ", *notifications)
+	}
+}
+
+func TestTimerRemindsHolderAndEscalatesOnce(t *testing.T) {
+	handleTick, notifications := createTestHoldTimer(HoldLimits{Hold: time.Hour, Grace: 30 * time.Minute})
+
+	handleTick(getTimerQueue(), acquired.Add(61*time.Minute))
+	handleTick(getTimerQueue(), acquired.Add(62*time.Minute))
+
+	expected := []command.Notification{
+		{Channel: "U123", Message: "You have had the token for 1h 1m (Tomato). " +
+			"Please say `done` if you have finished with it, otherwise you will be ousted in 29m"},
+		{Channel: "C1234", Message: "<@U123|craig> (Tomato) has had the token for 1h 1m and will be ousted in 29m"},
+	}
+	if !reflect.DeepEqual(expected, *notifications) {
+		t.Fatal("Unexpected notifications ", expected, *notifications)
+	}
+}
+
+func TestTimerRemindsBeforeOustingWithoutGracePeriod(t *testing.T) {
+	handleTick, notifications := createTestHoldTimer(HoldLimits{Hold: time.Hour})
+
+	qs, _ := handleTick(getTimerQueue(), acquired.Add(time.Hour))
+	if !qs.Equal(getTimerQueue()) {
+		t.Fatal("Unexpected queue ", qs)
+	}
+
+	expected := []command.Notification{
+		{Channel: "U123", Message: "You have had the token for 1h (Tomato). " +
+			"Please say `done` if you have finished with it, otherwise you will be ousted in 15m"},
+		{Channel: "C1234", Message: "<@U123|craig> (Tomato) has had the token for 1h and will be ousted in 15m"},
+	}
+	if !reflect.DeepEqual(expected, *notifications) {
+		t.Fatal("Unexpected notifications ", expected, *notifications)
+	}
+
+	qs, _ = handleTick(getTimerQueue(), acquired.Add(time.Hour+DefaultHoldGrace))
+	if qs.Get("C1234").Get(queue.DefaultToken).Active().ID != "U456" {
+		t.Fatal("Expected holder to be ousted after the default grace period ", qs)
+	}
+}
+
+func TestTimerEscalatesAfterDelay(t *testing.T) {
+	handleTick, notifications := createTestHoldTimer(HoldLimits{Hold: time.Hour, Escalate: 15 * time.Minute, Grace: 30 * time.Minute})
+
+	handleTick(getTimerQueue(), acquired.Add(61*time.Minute))
+	if len(*notifications) != 1 || (*notifications)[0].Channel != "U123" {
+		t.Fatal("Expected only a reminder ", *notifications)
+	}
+
+	handleTick(getTimerQueue(), acquired.Add(76*time.Minute))
+	if len(*notifications) != 2 || (*notifications)[1].Channel != "C1234" {
+		t.Fatal("Expected an escalation ", *notifications)
+	}
+}
+
+func TestTimerOustsHolderAfterGracePeriod(t *testing.T) {
+	handleTick, notifications := createTestHoldTimer(HoldLimits{Hold: time.Hour, Grace: 30 * time.Minute})
+	handleTick(getTimerQueue(), acquired.Add(time.Hour))
+	*notifications = (*notifications)[:0]
+	now := acquired.Add(90 * time.Minute)
+
+	qs, err := handleTick(getTimerQueue(), now)
+	if err != nil {
+		t.Fatal("Unexpected error ", err)
+	}
+
+	expectedQueue := queue.Channels{"C1234": queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{
+		{ID: "U456", Reason: "Potato", Key: "lqy", JoinedAt: acquired, ActiveSince: now},
+		{ID: "U123", Reason: "Tomato", Key: "jox", JoinedAt: acquired},
+	}}}}
+	if !qs.Equal(expectedQueue) {
+		t.Fatal("Unexpected queue ", expectedQueue, qs)
+	}
+
+	expected := []command.Notification{
+		{Channel: "C1234", Message: "<@U999|qbot> ousted <@U123|craig> (Tomato)\n*<@U456|edward> (Potato) now has the token*"},
+	}
+	if !reflect.DeepEqual(expected, *notifications) {
+		t.Fatal("Unexpected notifications ", expected, *notifications)
+	}
+}
+
+func TestTimerRemindsHolderBeforeOustingAfterRestart(t *testing.T) {
+	handleTick, notifications := createTestHoldTimer(HoldLimits{Hold: time.Hour, Grace: 30 * time.Minute})
+
+	qs, _ := handleTick(getTimerQueue(), acquired.Add(2*time.Hour))
+	if !qs.Equal(getTimerQueue()) {
+		t.Fatal("Expected holder to keep the token until reminded, got ", qs)
+	}
+
+	expected := []command.Notification{
+		{Channel: "U123", Message: "You have had the token for 2h (Tomato). " +
+			"Please say `done` if you have finished with it, otherwise you will be ousted in 30m"},
+		{Channel: "C1234", Message: "<@U123|craig> (Tomato) has had the token for 2h and will be ousted in 30m"},
+	}
+	if !reflect.DeepEqual(expected, *notifications) {
+		t.Fatal("Unexpected notifications ", expected, *notifications)
+	}
+
+	qs, _ = handleTick(getTimerQueue(), acquired.Add(2*time.Hour+29*time.Minute))
+	if !qs.Equal(getTimerQueue()) || len(*notifications) != 2 {
+		t.Fatal("Expected the whole grace period from the reminder, got ", qs, *notifications)
+	}
+
+	qs, _ = handleTick(getTimerQueue(), acquired.Add(2*time.Hour+30*time.Minute))
+	if qs.Get("C1234").Get(queue.DefaultToken).Active().ID != "U456" {
+		t.Fatal("Expected holder to be ousted after the grace period ", qs)
+	}
+}
+
+func TestTimerOustsHolderOfNamedToken(t *testing.T) {
+	handleTick, notifications := createTestHoldTimer(HoldLimits{Hold: time.Hour, Grace: 30 * time.Minute})
+	start := queue.Channels{"C1234": queue.Tokens{"staging": {Queue: queue.Queue{
+		{ID: "U123", Reason: "Tomato", Key: "jox", JoinedAt: acquired, ActiveSince: acquired},
+	}}}}
+	handleTick(start, acquired.Add(time.Hour))
+	*notifications = (*notifications)[:0]
+
+	qs, _ := handleTick(start, acquired.Add(90*time.Minute))
+
+	expectedQueue := queue.Channels{"C1234": queue.Tokens{"staging": {Queue: queue.Queue{}}}}
+	if !qs.Equal(expectedQueue) {
+		t.Fatal("Unexpected queue ", expectedQueue, qs)
+	}
+
+	expected := []command.Notification{
+		{Channel: "C1234", Message: "<@U999|qbot> ousted <@U123|craig> (Tomato)\nThe `staging` token is up for grabs"},
+	}
+	if !reflect.DeepEqual(expected, *notifications) {
+		t.Fatal("Unexpected notifications ", expected, *notifications)
+	}
+}
+
+func TestTimerChasesEveryHolderOfSharedToken(t *testing.T) {
+	handleTick, notifications := createTestHoldTimer(HoldLimits{Hold: time.Hour, Grace: 30 * time.Minute})
+	start := queue.Channels{"C1234": queue.Tokens{"envs": {Capacity: 2, Queue: queue.Queue{
+		{ID: "U123", Reason: "Tomato", Key: "jox", JoinedAt: acquired, ActiveSince: acquired},
+		{ID: "U456", Reason: "Potato", Key: "lqy", JoinedAt: acquired, ActiveSince: acquired.Add(time.Hour)},
+	}}}}
+	handleTick(start, acquired.Add(time.Hour))
+	*notifications = (*notifications)[:0]
+
+	qs, _ := handleTick(start, acquired.Add(90*time.Minute))
+
+	expectedQueue := queue.Channels{"C1234": queue.Tokens{"envs": {Capacity: 2, Queue: queue.Queue{
+		{ID: "U456", Reason: "Potato", Key: "lqy", JoinedAt: acquired, ActiveSince: acquired.Add(time.Hour)},
+	}}}}
+	if !qs.Equal(expectedQueue) {
+		t.Fatal("Unexpected queue ", expectedQueue, qs)
+	}
+
+	expected := []command.Notification{
+		{Channel: "C1234", Message: "<@U999|qbot> ousted <@U123|craig> (Tomato)\nThe `envs` token is up for grabs"},
+	}
+	if !reflect.DeepEqual(expected, *notifications) {
+		t.Fatal("Unexpected notifications ", expected, *notifications)
+	}
+}
+
+func TestTimerReturnsErrorIfNotifyFails(t *testing.T) {
+	notify := func(n command.Notification) error {
+		return fmt.Errorf("Error!")
+	}
+	handleTick := CreateHoldTimer(HoldLimits{Hold: time.Hour}, timerCommands, notify)
+
+	_, err := handleTick(getTimerQueue(), acquired.Add(2*time.Hour))
+	if err == nil {
+		t.Fatal("Expected error")
+	}
+}
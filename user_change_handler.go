@@ -14,7 +14,7 @@ type UserChangeHandler func(guac.UserInfo)
 func CreateUserChangeHandler(userCache usercache.UserCache) UserChangeHandler {
 	return func(userChange guac.UserInfo) {
 		oldName := userCache.GetUserName(userChange.ID)
-		userCache.UpdateUserName(userChange.ID, userChange.Name)
+		userCache.UpdateUser(userChange)
 		if oldName == "" {
 			log.Printf("New user %s cached", userChange.Name)
 		} else if oldName != userChange.Name {
@@ -1,40 +1,112 @@
 package usercache
 
 import (
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/doozr/guac"
 )
 
-// UserCache is a simple cache of usernames and their IDs
+// UserCache is a simple cache of users and their IDs
 type UserCache interface {
 	GetUserName(string) string
 	GetUserID(string) string
+	FindUsers(string) []string
 	UpdateUserName(string, string)
+	UpdateUser(guac.UserInfo)
 	Count() int
 }
 
-// userCache contains a mutex controlled list of user info objects keyed on ID
+// User is what the cache knows about a user
+type User struct {
+	ID          string
+	Name        string
+	DisplayName string
+	RealName    string
+	Email       string
+}
+
+// index finds the IDs of users by a value, ignoring case
+type index map[string]map[string]bool
+
+func (x index) add(value, id string) {
+	if value == "" {
+		return
+	}
+	value = strings.ToLower(value)
+	if x[value] == nil {
+		x[value] = map[string]bool{}
+	}
+	x[value][id] = true
+}
+
+func (x index) remove(value, id string) {
+	value = strings.ToLower(value)
+	delete(x[value], id)
+	if len(x[value]) == 0 {
+		delete(x, value)
+	}
+}
+
+func (x index) find(value string) (ids []string) {
+	for id := range x[strings.ToLower(value)] {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return
+}
+
+// userCache contains a mutex controlled list of users keyed on ID, indexed on their user names and on their display
+// names, real names and emails
 type userCache struct {
-	Mux       sync.Mutex
-	UserNames map[string]string
+	Mux    sync.Mutex
+	Users  map[string]User
+	names  index
+	others index
 }
 
 // New creates an instance of UserCache
 func New(users []guac.UserInfo) UserCache {
-	uc := &userCache{}
-	uc.UserNames = make(map[string]string)
+	uc := &userCache{Users: map[string]User{}, names: index{}, others: index{}}
 	for _, user := range users {
-		uc.UserNames[user.ID] = user.Name
+		uc.update(fromInfo(user))
 	}
 	return uc
 }
 
+// fromInfo takes what the cache needs to know from a Slack user
+func fromInfo(info guac.UserInfo) User {
+	return User{
+		ID:          info.ID,
+		Name:        info.Name,
+		DisplayName: info.DisplayName,
+		RealName:    info.RealName,
+		Email:       info.Email,
+	}
+}
+
+// update replaces a user and their entries in the indexes; the caller must hold the lock
+func (u *userCache) update(user User) {
+	if old, ok := u.Users[user.ID]; ok {
+		u.names.remove(old.Name, old.ID)
+		u.others.remove(old.DisplayName, old.ID)
+		u.others.remove(old.RealName, old.ID)
+		u.others.remove(old.Email, old.ID)
+	}
+
+	u.Users[user.ID] = user
+	u.names.add(user.Name, user.ID)
+	u.others.add(user.DisplayName, user.ID)
+	u.others.add(user.RealName, user.ID)
+	u.others.add(user.Email, user.ID)
+}
+
 // GetUserName looks up the username associated with an ID
 func (u *userCache) GetUserName(id string) (username string) {
 	u.Mux.Lock()
-	if val, ok := u.UserNames[id]; ok {
-		username = val
+	if val, ok := u.Users[id]; ok {
+		username = val.Name
 	}
 	u.Mux.Unlock()
 	return
@@ -43,18 +115,37 @@ func (u *userCache) GetUserName(id string) (username string) {
 // UpdateUserName updates the username associated with an ID
 func (u *userCache) UpdateUserName(id string, name string) {
 	u.Mux.Lock()
-	u.UserNames[id] = name
+	user := u.Users[id]
+	user.ID = id
+	user.Name = name
+	u.update(user)
+	u.Mux.Unlock()
+}
+
+// UpdateUser updates everything known about a user
+func (u *userCache) UpdateUser(info guac.UserInfo) {
+	u.Mux.Lock()
+	u.update(fromInfo(info))
 	u.Mux.Unlock()
 }
 
-// GetUserID gets the ID associated with a username
+// GetUserID gets the ID of the only user matching a name, or an empty string if there is not exactly one
 func (u *userCache) GetUserID(name string) (id string) {
+	if ids := u.FindUsers(name); len(ids) == 1 {
+		id = ids[0]
+	}
+	return
+}
+
+// FindUsers gets the IDs of the users matching a name, ignoring case
+//
+// A user name belongs to only one user, so a match on it is the only match. Otherwise every user with a display name,
+// real name or email matching the name is returned, in order of ID.
+func (u *userCache) FindUsers(name string) (ids []string) {
 	u.Mux.Lock()
-	for k, v := range u.UserNames {
-		if v == name {
-			id = k
-			break
-		}
+	ids = u.names.find(name)
+	if len(ids) == 0 {
+		ids = u.others.find(name)
 	}
 	u.Mux.Unlock()
 	return
@@ -62,5 +153,5 @@ func (u *userCache) GetUserID(name string) (id string) {
 
 // Count the number of users in the cache
 func (u *userCache) Count() int {
-	return len(u.UserNames)
+	return len(u.Users)
 }
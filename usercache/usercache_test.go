@@ -48,3 +48,69 @@ func GetsEmptyIDIfNameNotFound(t *testing.T) {
 		t.Fatal("Expected empty ID, got ", id)
 	}
 }
+
+var people = []guac.UserInfo{
+	{ID: "U1", Name: "jane", DisplayName: "Jane", RealName: "Jane Smith", Email: "jane@corp.com"},
+	{ID: "U2", Name: "jsmith", DisplayName: "JS", RealName: "John Smith", Email: "john@corp.com"},
+	{ID: "U3", Name: "janet", DisplayName: "Jane", RealName: "Janet Jones", Email: "janet@corp.com"},
+}
+
+func TestFindsUsersByAnyNameIgnoringCase(t *testing.T) {
+	cache := New(people)
+	for _, name := range []string{"JANE SMITH", "jane@CORP.com", "Jsmith", "JS"} {
+		if ids := cache.FindUsers(name); len(ids) != 1 {
+			t.Fatal("Expected one user for ", name, ", got ", ids)
+		}
+	}
+	if id := cache.GetUserID("John Smith"); id != "U2" {
+		t.Fatal("Incorrect ID ", id)
+	}
+}
+
+func TestUserNameIsPreferredToOtherNames(t *testing.T) {
+	cache := New(people)
+	ids := cache.FindUsers("jane")
+	if len(ids) != 1 || ids[0] != "U1" {
+		t.Fatal("Expected user name to match, got ", ids)
+	}
+}
+
+func TestFindsEveryUserWithAmbiguousName(t *testing.T) {
+	cache := New(people[1:])
+	ids := cache.FindUsers("jane")
+	if len(ids) != 1 || ids[0] != "U3" {
+		t.Fatal("Expected display name to match, got ", ids)
+	}
+
+	cache = New(people)
+	cache.UpdateUser(guac.UserInfo{ID: "U1", Name: "jane.s", DisplayName: "Jane", RealName: "Jane Smith"})
+	ids = cache.FindUsers("jane")
+	if len(ids) != 2 || ids[0] != "U1" || ids[1] != "U3" {
+		t.Fatal("Expected both users called Jane, got ", ids)
+	}
+	if id := cache.GetUserID("jane"); id != "" {
+		t.Fatal("Expected no single ID for an ambiguous name, got ", id)
+	}
+}
+
+func TestUpdateForgetsOldNames(t *testing.T) {
+	cache := New(people)
+	cache.UpdateUser(guac.UserInfo{ID: "U2", Name: "jsmith", RealName: "John Smyth"})
+	if ids := cache.FindUsers("john smith"); len(ids) != 0 {
+		t.Fatal("Expected old real name to be forgotten, got ", ids)
+	}
+	if ids := cache.FindUsers("john@corp.com"); len(ids) != 0 {
+		t.Fatal("Expected old email to be forgotten, got ", ids)
+	}
+	if id := cache.GetUserID("john smyth"); id != "U2" {
+		t.Fatal("Incorrect ID ", id)
+	}
+
+	cache.UpdateUserName("U2", "johnny")
+	if id := cache.GetUserID("jsmith"); id != "" {
+		t.Fatal("Expected old user name to be forgotten, got ", id)
+	}
+	if id := cache.GetUserID("John Smyth"); id != "U2" {
+		t.Fatal("Expected real name to be kept, got ", id)
+	}
+}
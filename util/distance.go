@@ -0,0 +1,41 @@
+package util
+
+// Distance counts the single letter insertions, deletions, substitutions and swaps of neighbouring letters needed to
+// turn one string into another
+func Distance(a, b string) int {
+	s, t := []rune(a), []rune(b)
+
+	d := make([][]int, len(s)+1)
+	for i := range d {
+		d[i] = make([]int, len(t)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(s); i++ {
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+
+			d[i][j] = least(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] {
+				d[i][j] = least(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(s)][len(t)]
+}
+
+func least(ns ...int) int {
+	l := ns[0]
+	for _, n := range ns[1:] {
+		if n < l {
+			l = n
+		}
+	}
+	return l
+}
@@ -0,0 +1,31 @@
+package util_test
+
+import (
+	"testing"
+
+	. "github.com/doozr/qbot/util"
+)
+
+var distanceTests = []struct {
+	desc     string
+	a        string
+	b        string
+	distance int
+}{
+	{"is zero for the same string", "done", "done", 0},
+	{"counts a missing letter", "dne", "done", 1},
+	{"counts an extra letter", "joinn", "join", 1},
+	{"counts a wrong letter", "jain", "join", 1},
+	{"counts swapped letters once", "yeild", "yield", 1},
+	{"counts every change", "lsit", "leave", 4},
+	{"counts every letter of an empty string", "", "list", 4},
+}
+
+func TestDistance(t *testing.T) {
+	for _, tt := range distanceTests {
+		distance := Distance(tt.a, tt.b)
+		if distance != tt.distance {
+			t.Errorf("It %s; expected %d, received %d", tt.desc, tt.distance, distance)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Duration formats a duration to the nearest minute in a human friendly way, e.g. `1d 2h 5m`
+func Duration(d time.Duration) string {
+	minutes := int((d + 30*time.Second) / time.Minute)
+	if minutes < 1 {
+		return "less than a minute"
+	}
+
+	days := minutes / (24 * 60)
+	hours := (minutes / 60) % 24
+	minutes = minutes % 60
+
+	parts := []string{}
+	if days > 0 {
+		parts = append(parts, fmt.Sprintf("%dd", days))
+	}
+	if hours > 0 {
+		parts = append(parts, fmt.Sprintf("%dh", hours))
+	}
+	if minutes > 0 {
+		parts = append(parts, fmt.Sprintf("%dm", minutes))
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,31 @@
+package util_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/doozr/qbot/util"
+)
+
+var durationTests = []struct {
+	in  time.Duration
+	out string
+}{
+	{0, "less than a minute"},
+	{29 * time.Second, "less than a minute"},
+	{30 * time.Second, "1m"},
+	{45 * time.Minute, "45m"},
+	{2 * time.Hour, "2h"},
+	{2*time.Hour + 5*time.Minute + 10*time.Second, "2h 5m"},
+	{26*time.Hour + 5*time.Minute, "1d 2h 5m"},
+	{48 * time.Hour, "2d"},
+}
+
+func TestDuration(t *testing.T) {
+	for _, tt := range durationTests {
+		out := Duration(tt.in)
+		if out != tt.out {
+			t.Errorf("Expected '%s' for duration '%s', received '%s'", tt.out, tt.in, out)
+		}
+	}
+}
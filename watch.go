@@ -0,0 +1,78 @@
+package qbot
+
+import (
+	"sort"
+
+	"github.com/doozr/qbot/command"
+	"github.com/doozr/qbot/queue"
+)
+
+// Watcher is told about every change to the queues, along with who made it and with which command.
+type Watcher func(before, after queue.Channels, actor, command string) error
+
+// ChainWatchers creates a Watcher that tells each of the given watchers in turn, stopping at the first error.
+func ChainWatchers(watchers ...Watcher) Watcher {
+	return func(before, after queue.Channels, actor, command string) (err error) {
+		for _, watch := range watchers {
+			err = watch(before, after, actor, command)
+			if err != nil {
+				break
+			}
+		}
+		return
+	}
+}
+
+// sortedChannels returns the channels in order
+func sortedChannels(qs queue.Channels) []string {
+	channels := []string{}
+	for channel := range qs {
+		channels = append(channels, channel)
+	}
+	sort.Strings(channels)
+	return channels
+}
+
+// CreatePositionWatcher creates a Watcher that sends users a direct message when their place in a queue changes, if
+// they want to know.
+//
+// Users are not told about changes they made themselves.
+func CreatePositionWatcher(commands command.QueueCommands, notify Notifier) Watcher {
+	return func(before, after queue.Channels, actor, command string) (err error) {
+		for _, channel := range sortedChannels(after) {
+			bt, at := before.Get(channel), after.Get(channel)
+			for _, name := range at.Names() {
+				for _, i := range at.Get(name) {
+					if i.ID == actor {
+						continue
+					}
+
+					err = notify(commands.PositionChanged(channel, name, bt, at, i))
+					if err != nil {
+						return
+					}
+				}
+			}
+		}
+		return
+	}
+}
+
+// CreateOustVoteWatcher creates a Watcher that sends token holders a direct message when the first vote to oust them
+// is cast.
+func CreateOustVoteWatcher(commands command.QueueCommands, notify Notifier) Watcher {
+	return func(before, after queue.Channels, actor, command string) (err error) {
+		for _, channel := range sortedChannels(after) {
+			bt, at := before.Get(channel), after.Get(channel)
+			for _, name := range at.Names() {
+				for _, i := range at.Get(name).Holders(at.Capacity(name)) {
+					err = notify(commands.OustVoteStarted(channel, name, bt, at, i))
+					if err != nil {
+						return
+					}
+				}
+			}
+		}
+		return
+	}
+}
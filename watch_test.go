@@ -0,0 +1,170 @@
+package qbot_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/doozr/guac"
+	. "github.com/doozr/qbot"
+	"github.com/doozr/qbot/command"
+	"github.com/doozr/qbot/preferences"
+	"github.com/doozr/qbot/queue"
+	"github.com/doozr/qbot/usercache"
+)
+
+func createTestPositionWatcher() (Watcher, *[]command.Notification) {
+	prefs := preferences.New(map[string]preferences.User{
+		"U123": {Notify: preferences.NotifyAll},
+		"U456": {Notify: preferences.NotifyAll},
+	}, func(map[string]preferences.User) error { return nil })
+	commands := command.New("U999", "qbot", usercache.New([]guac.UserInfo{
+		{ID: "U123", Name: "craig"},
+		{ID: "U456", Name: "edward"},
+		{ID: "U999", Name: "qbot"},
+	})).WithPreferences(prefs)
+
+	notifications := []command.Notification{}
+	notify := func(n command.Notification) error {
+		notifications = append(notifications, n)
+		return nil
+	}
+	return CreatePositionWatcher(commands, notify), &notifications
+}
+
+func TestPositionWatcherNotifiesUsersWhoseEntriesMoved(t *testing.T) {
+	watch, notifications := createTestPositionWatcher()
+
+	tomato := queue.Item{ID: "U123", Reason: "Tomato", Key: "jox"}
+	potato := queue.Item{ID: "U456", Reason: "Potato", Key: "lqy"}
+	before := queue.Channels{"C1234": queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{tomato, potato}}}}
+	after := queue.Channels{"C1234": queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{potato}}}}
+
+	err := watch(before, after, "U123", "done")
+	if err != nil {
+		t.Fatal("Unexpected error ", err)
+	}
+
+	expected := []command.Notification{{Channel: "U456", Message: "*You now have the token in <#C1234>* (Potato)"}}
+	if len(*notifications) != 1 || (*notifications)[0] != expected[0] {
+		t.Fatal("Unexpected notifications ", *notifications)
+	}
+}
+
+func TestPositionWatcherDoesNotNotifyTheActor(t *testing.T) {
+	watch, notifications := createTestPositionWatcher()
+
+	tomato := queue.Item{ID: "U123", Reason: "Tomato", Key: "jox"}
+	before := queue.Channels{}
+	after := queue.Channels{"C1234": queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{tomato}}}}
+
+	err := watch(before, after, "U123", "done")
+	if err != nil {
+		t.Fatal("Unexpected error ", err)
+	}
+
+	if len(*notifications) != 0 {
+		t.Fatal("Unexpected notifications ", *notifications)
+	}
+}
+
+func TestWatchedMessageHandlerTellsWatcherWhoMadeTheChange(t *testing.T) {
+	after := queue.Channels{"C1234": queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{{ID: "U1234", Reason: "Tomato"}}}}}
+	fn := func(qs queue.Channels, m guac.MessageEvent) (queue.Channels, error) {
+		return after, nil
+	}
+
+	var actor, cmd string
+	var watched queue.Channels
+	watch := func(before, after queue.Channels, a, c string) error {
+		watched, actor, cmd = after, a, c
+		return nil
+	}
+
+	handler := CreateWatchedMessageHandler(fn, watch)
+	handler(queue.Channels{}, makeTestEvent("join Tomato"))
+
+	if actor != "U1234" || cmd != "join" || !watched.Equal(after) {
+		t.Fatal("Unexpected watch ", actor, cmd, watched)
+	}
+}
+
+func TestWatchedMessageHandlerDoesNotWatchOnError(t *testing.T) {
+	fn := func(qs queue.Channels, m guac.MessageEvent) (queue.Channels, error) {
+		return nil, fmt.Errorf("Error!")
+	}
+
+	calls := 0
+	watch := func(before, after queue.Channels, a, c string) error {
+		calls++
+		return nil
+	}
+
+	handler := CreateWatchedMessageHandler(fn, watch)
+	_, err := handler(queue.Channels{}, makeTestEvent("join Tomato"))
+
+	if err == nil {
+		t.Fatal("Expected error")
+	}
+
+	if calls != 0 {
+		t.Fatal("Expected no calls to watcher")
+	}
+}
+
+func TestWatchedTickHandlerHasNoActor(t *testing.T) {
+	fn := func(qs queue.Channels, now time.Time) (queue.Channels, error) {
+		return queue.Channels{}, nil
+	}
+
+	actor, cmd := "unset", ""
+	watch := func(before, after queue.Channels, a, c string) error {
+		actor, cmd = a, c
+		return nil
+	}
+
+	handler := CreateWatchedTickHandler(fn, "hold", watch)
+	handler(queue.Channels{}, time.Now())
+
+	if actor != "" || cmd != "hold" {
+		t.Fatal("Expected no actor and the hold command, got ", actor, cmd)
+	}
+}
+
+func TestChainWatchersStopsAtFirstError(t *testing.T) {
+	calls := 0
+	watch := func(before, after queue.Channels, actor, command string) error {
+		calls++
+		return fmt.Errorf("Error!")
+	}
+
+	err := ChainWatchers(watch, watch)(queue.Channels{}, queue.Channels{}, "U1234", "join")
+	if err == nil || calls != 1 {
+		t.Fatal("Expected one call and an error, got ", calls, err)
+	}
+}
+
+func TestOustVoteWatcherWarnsHolderOnFirstVote(t *testing.T) {
+	now := time.Now()
+	notifications := []command.Notification{}
+	notify := func(n command.Notification) error {
+		notifications = append(notifications, n)
+		return nil
+	}
+	watch := CreateOustVoteWatcher(timerCommands.WithOustVotes(2, time.Hour), notify)
+
+	tomato := queue.Item{ID: "U123", Reason: "Tomato", Key: "jox"}
+	potato := queue.Item{ID: "U456", Reason: "Potato", Key: "lqy"}
+	before := queue.Channels{"C1234": queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{tomato, potato}}}}
+	after := queue.Channels{"C1234": queue.Tokens{queue.DefaultToken: {Queue: queue.Queue{tomato, potato},
+		Votes: queue.Votes{{ID: "U456", Item: tomato, At: now}}}}}
+
+	err := watch(before, after, "U456", "oust")
+	if err != nil {
+		t.Fatal("Unexpected error ", err)
+	}
+
+	if len(notifications) != 1 || notifications[0].Channel != "U123" || notifications[0].Message == "" {
+		t.Fatal("Unexpected notifications ", notifications)
+	}
+}
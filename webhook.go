@@ -0,0 +1,236 @@
+package qbot
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/doozr/guac"
+	"github.com/doozr/qbot/queue"
+	"github.com/doozr/qbot/usercache"
+)
+
+// SignatureHeader is the header that carries the signature of a webhook, as `sha256=` followed by the hex HMAC of the
+// timestamp, a full stop and the body, made with the shared secret.
+const SignatureHeader = "X-Qbot-Signature"
+
+// TimestampHeader is the header that carries when a webhook was sent, in seconds since the Unix epoch.
+const TimestampHeader = "X-Qbot-Timestamp"
+
+// WebhookTolerance is how far from now the time a webhook was sent may be before it is refused as stale.
+const WebhookTolerance = 5 * time.Minute
+
+// maxBodySize is the largest body of a webhook or API request that is read
+const maxBodySize = 64 * 1024
+
+// Webhook is a notification from an automated system such as CI.
+//
+// Event is one of success, failure, join or leave. User is who joins or leaves, given as an ID, user name or email.
+// Key picks out the token holder for success and failure, or the entry key to leave. Token names a token other than
+// the default.
+type Webhook struct {
+	Event   string `json:"event"`
+	User    string `json:"user"`
+	Token   string `json:"token"`
+	Key     string `json:"key"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+// quoteEscaper escapes the quote marks and backslashes in quoted text, so that the quotes are not ended early.
+var quoteEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `“`, `\“`, `”`, `\”`)
+
+// quoted wraps text in double quotes so that it is always read as one argument, exactly as given.
+func quoted(s string) string {
+	return `"` + quoteEscaper.Replace(s) + `"`
+}
+
+// quote wraps a value in double quotes if it would not otherwise be read as one argument.
+func quote(s string) string {
+	if strings.ContainsAny(s, " \t\r\n\"“”") || strings.HasPrefix(s, "--") {
+		return quoted(s)
+	}
+	return s
+}
+
+// command writes the webhook as the command that a person would give in the channel.
+//
+// Free text is always quoted so that it cannot be taken for the name of a token.
+func (w Webhook) command() (text string, err error) {
+	words := []string{w.Event}
+	if w.Token != "" {
+		words = append(words, "--token", quote(w.Token))
+	}
+
+	switch w.Event {
+	case "success":
+		if w.Key != "" {
+			words = append(words, "--key", quote(w.Key))
+		}
+
+	case "failure":
+		if w.Key != "" {
+			words = append(words, "--key", quote(w.Key))
+		}
+		if w.Message == "" {
+			return "", fmt.Errorf("failure needs a message")
+		}
+		words = append(words, quoted(w.Message))
+
+	case "join":
+		if w.Reason == "" {
+			return "", fmt.Errorf("join needs a reason")
+		}
+		words = append(words, quoted(w.Reason))
+
+	case "leave":
+		if w.Key != "" {
+			words = append(words, "#"+strings.TrimPrefix(w.Key, "#"))
+		}
+
+	default:
+		return "", fmt.Errorf("unknown event %q", w.Event)
+	}
+	return strings.Join(words, " "), nil
+}
+
+// findUser finds the ID of a user given as an ID, user name or email, or an empty string if there is no such user.
+func findUser(userCache usercache.UserCache, user string) string {
+	if userCache.GetUserName(user) != "" {
+		return user
+	}
+	return userCache.GetUserID(user)
+}
+
+// sign gives the signature of a body sent at a time, as it goes in SignatureHeader.
+func sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// validSignature checks that the timestamp and body were signed with the secret.
+func validSignature(secret []byte, timestamp string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(signature), []byte(sign(secret, timestamp, body)))
+}
+
+// freshTimestamp checks that a webhook was sent within the tolerance of now.
+func freshTimestamp(timestamp string, now time.Time) bool {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	sent := time.Unix(seconds, 0)
+	return !sent.Before(now.Add(-WebhookTolerance)) && !sent.After(now.Add(WebhookTolerance))
+}
+
+// replays remembers the signatures of webhooks that are still fresh, so that each one is only accepted once.
+type replays struct {
+	sync.Mutex
+	seen map[string]time.Time
+}
+
+// first records a signature, and is true if it has not been seen while fresh before.
+func (r *replays) first(signature string, now time.Time) bool {
+	r.Lock()
+	defer r.Unlock()
+
+	for s, at := range r.seen {
+		if now.Sub(at) > 2*WebhookTolerance {
+			delete(r.seen, s)
+		}
+	}
+	if _, ok := r.seen[signature]; ok {
+		return false
+	}
+	r.seen[signature] = now
+	return true
+}
+
+// CreateWebhookHandler creates an HTTP handler that accepts signed webhooks and runs them as commands in the channel.
+//
+// Each webhook is handed to the dispatcher as a job, so that it is handled in turn with messages from Slack and goes
+// through the same message handler, and the response is posted to the channel like any other. Success and failure are
+// sent by the given sender, and join and leave by the user the webhook names. The webhook is accepted once the
+// dispatcher has taken it.
+//
+// Webhooks must be signed along with the time they were sent, which must be within WebhookTolerance of now, and each
+// is only accepted once, so that one cannot be sent again later by somebody who has seen it.
+func CreateWebhookHandler(secret []byte, channel, sender string, userCache usercache.UserCache,
+	handleMessage MessageHandler, now Clock, jobs chan<- Job, done DoneChan) http.Handler {
+
+	accepted := &replays{seen: map[string]time.Time{}}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "webhooks must be posted", http.StatusMethodNotAllowed)
+			return
+		}
+
+		body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+		if err != nil {
+			http.Error(w, "could not read webhook", http.StatusBadRequest)
+			return
+		}
+		timestamp, signature := r.Header.Get(TimestampHeader), r.Header.Get(SignatureHeader)
+		if !validSignature(secret, timestamp, body, signature) {
+			http.Error(w, "bad signature", http.StatusUnauthorized)
+			return
+		}
+		t := now()
+		if !freshTimestamp(timestamp, t) {
+			http.Error(w, "stale timestamp", http.StatusUnauthorized)
+			return
+		}
+		if !accepted.first(signature, t) {
+			http.Error(w, "webhook already received", http.StatusConflict)
+			return
+		}
+
+		var hook Webhook
+		if err = json.Unmarshal(body, &hook); err != nil {
+			http.Error(w, "could not read webhook: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		hook.Event = strings.ToLower(hook.Event)
+
+		text, err := hook.command()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		user := sender
+		if hook.Event == "join" || hook.Event == "leave" {
+			user = findUser(userCache, hook.User)
+			if user == "" {
+				http.Error(w, fmt.Sprintf("no such user %q", hook.User), http.StatusBadRequest)
+				return
+			}
+		}
+
+		m := guac.MessageEvent{Type: "message", Channel: channel, User: user, Text: text}
+		job := func(qs queue.Channels) (queue.Channels, error) {
+			log.Printf("Webhook in %s from %s: %s", channel, user, text)
+			return handleMessage(qs, m)
+		}
+
+		select {
+		case jobs <- job:
+			w.WriteHeader(http.StatusAccepted)
+		case <-done:
+			http.Error(w, "shutting down", http.StatusServiceUnavailable)
+		case <-r.Context().Done():
+		}
+	})
+}
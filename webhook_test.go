@@ -0,0 +1,213 @@
+package qbot_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/doozr/guac"
+	. "github.com/doozr/qbot"
+	"github.com/doozr/qbot/command"
+	"github.com/doozr/qbot/queue"
+	"github.com/doozr/qbot/usercache"
+)
+
+var webhookSecret = []byte("s3cret")
+
+var webhookUsers = usercache.New([]guac.UserInfo{
+	{ID: "U123", Name: "craig", Email: "craig@corp.com"},
+	{ID: "U999", Name: "qbot"},
+})
+
+var webhookTime = time.Date(2017, 3, 14, 12, 0, 0, 0, time.UTC)
+
+var webhookTimestamp = strconv.FormatInt(webhookTime.Unix(), 10)
+
+func signAt(timestamp, body string) string {
+	mac := hmac.New(sha256.New, webhookSecret)
+	mac.Write([]byte(timestamp + "." + body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func sign(body string) string {
+	return signAt(webhookTimestamp, body)
+}
+
+func createWebhookHandler(handleMessage MessageHandler) (http.Handler, chan Job) {
+	jobs := make(chan Job, 1)
+	now := func() time.Time { return webhookTime }
+	return CreateWebhookHandler(webhookSecret, "C1234", "U999", webhookUsers, handleMessage, now, jobs, make(DoneChan)), jobs
+}
+
+func sendWebhook(handler http.Handler, method, body, timestamp, signature string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, "/webhook", strings.NewReader(body))
+	r.Header.Set(TimestampHeader, timestamp)
+	r.Header.Set(SignatureHeader, signature)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w
+}
+
+func postWebhook(handleMessage MessageHandler, method, body, signature string) (*httptest.ResponseRecorder, chan Job) {
+	handler, jobs := createWebhookHandler(handleMessage)
+	return sendWebhook(handler, method, body, webhookTimestamp, signature), jobs
+}
+
+func TestWebhookRefusesBadRequests(t *testing.T) {
+	for _, tt := range []struct {
+		test      string
+		method    string
+		body      string
+		signature string
+		status    int
+	}{
+		{"not posted", http.MethodGet, "", sign(""), http.StatusMethodNotAllowed},
+		{"no signature", http.MethodPost, `{"event":"success"}`, "", http.StatusUnauthorized},
+		{"wrong signature", http.MethodPost, `{"event":"success"}`, sign(`{"event":"failure"}`), http.StatusUnauthorized},
+		{"not JSON", http.MethodPost, `success`, sign(`success`), http.StatusBadRequest},
+		{"unknown event", http.MethodPost, `{"event":"oust"}`, sign(`{"event":"oust"}`), http.StatusBadRequest},
+		{"join without reason", http.MethodPost, `{"event":"join","user":"craig"}`,
+			sign(`{"event":"join","user":"craig"}`), http.StatusBadRequest},
+		{"unknown user", http.MethodPost, `{"event":"leave","user":"nobody"}`,
+			sign(`{"event":"leave","user":"nobody"}`), http.StatusBadRequest},
+	} {
+		w, jobs := postWebhook(nil, tt.method, tt.body, tt.signature)
+		if w.Code != tt.status {
+			t.Fatalf("%s: expected status %d, got %d", tt.test, tt.status, w.Code)
+		}
+		if len(jobs) != 0 {
+			t.Fatalf("%s: expected no job", tt.test)
+		}
+	}
+}
+
+func TestWebhookRefusesStaleAndReplayedRequests(t *testing.T) {
+	body := `{"event":"success"}`
+	stale := strconv.FormatInt(webhookTime.Add(-WebhookTolerance-time.Second).Unix(), 10)
+	early := strconv.FormatInt(webhookTime.Add(WebhookTolerance+time.Second).Unix(), 10)
+	recent := strconv.FormatInt(webhookTime.Add(-time.Minute).Unix(), 10)
+
+	for _, tt := range []struct {
+		test      string
+		timestamp string
+		signature string
+		status    int
+	}{
+		{"no timestamp", "", signAt("", body), http.StatusUnauthorized},
+		{"timestamp not signed", recent, sign(body), http.StatusUnauthorized},
+		{"stale", stale, signAt(stale, body), http.StatusUnauthorized},
+		{"from the future", early, signAt(early, body), http.StatusUnauthorized},
+		{"recent", recent, signAt(recent, body), http.StatusAccepted},
+	} {
+		handler, _ := createWebhookHandler(nil)
+		w := sendWebhook(handler, http.MethodPost, body, tt.timestamp, tt.signature)
+		if w.Code != tt.status {
+			t.Fatalf("%s: expected status %d, got %d", tt.test, tt.status, w.Code)
+		}
+	}
+
+	handler, jobs := createWebhookHandler(nil)
+	sendWebhook(handler, http.MethodPost, body, webhookTimestamp, sign(body))
+	<-jobs
+	w := sendWebhook(handler, http.MethodPost, body, webhookTimestamp, sign(body))
+	if w.Code != http.StatusConflict || len(jobs) != 0 {
+		t.Fatalf("Expected replayed webhook to be refused, got %d", w.Code)
+	}
+}
+
+func TestWebhookRunsCommandInChannel(t *testing.T) {
+	var handled []guac.MessageEvent
+	handleMessage := func(qs queue.Channels, m guac.MessageEvent) (queue.Channels, error) {
+		handled = append(handled, m)
+		return qs, nil
+	}
+
+	for _, tt := range []struct {
+		body string
+		user string
+		text string
+	}{
+		{`{"event":"success"}`, "U999", "success"},
+		{`{"event":"Success","token":"staging","key":"feature/login"}`, "U999", "success --token staging --key feature/login"},
+		{`{"event":"failure","key":"PR 123","message":"tests failed"}`, "U999", `failure --key "PR 123" "tests failed"`},
+		{`{"event":"join","user":"craig@corp.com","reason":"staging"}`, "U123", `join "staging"`},
+		{`{"event":"leave","user":"U123","key":"a2y"}`, "U123", "leave #a2y"},
+		{`{"event":"join","user":"craig","reason":"fix \"the\" build"}`, "U123", `join "fix \"the\" build"`},
+	} {
+		w, jobs := postWebhook(handleMessage, http.MethodPost, tt.body, sign(tt.body))
+		if w.Code != http.StatusAccepted || len(jobs) != 1 {
+			t.Fatalf("%s: expected webhook to be accepted, got %d", tt.body, w.Code)
+		}
+		handled = nil
+		(<-jobs)(queue.Channels{})
+		if len(handled) != 1 || handled[0].Channel != "C1234" || handled[0].User != tt.user || handled[0].Text != tt.text {
+			t.Fatalf("%s: expected %s from %s, got %v", tt.body, tt.text, tt.user, handled)
+		}
+	}
+}
+
+func TestWebhookGoesThroughCommands(t *testing.T) {
+	var notifications []command.Notification
+	notify := func(n command.Notification) error {
+		notifications = append(notifications, n)
+		return nil
+	}
+	commands := command.New("U999", "qbot", webhookUsers)
+	handleMessage := CreateMessageHandler(PublicCommands(commands), notify)
+
+	body := `{"event":"join","user":"craig","token":"staging","reason":"release 1.2"}`
+	_, jobs := postWebhook(handleMessage, http.MethodPost, body, sign(body))
+	if len(jobs) != 1 {
+		t.Fatal("Expected webhook to be accepted")
+	}
+
+	qs := queue.Channels{"C1234": {queue.DefaultToken: {}, "staging": {}}}
+	qs, err := (<-jobs)(qs)
+	if err != nil {
+		t.Fatal("Unexpected error ", err)
+	}
+	if q := qs.Get("C1234").Get("staging"); len(q) != 1 || q[0].ID != "U123" || q[0].Reason != "release 1.2" {
+		t.Fatal("Expected craig to join the staging queue, got ", qs)
+	}
+	if len(notifications) != 1 || notifications[0].Channel != "C1234" {
+		t.Fatal("Expected response in the channel, got ", notifications)
+	}
+}
+
+func TestWebhookKeepsQuotesInFreeText(t *testing.T) {
+	var notifications []command.Notification
+	notify := func(n command.Notification) error {
+		notifications = append(notifications, n)
+		return nil
+	}
+	commands := command.New("U999", "qbot", webhookUsers)
+	handleMessage := CreateMessageHandler(PublicCommands(commands), notify)
+	qs := queue.Channels{"C1234": {queue.DefaultToken: {}, "staging": {}}}
+
+	for _, body := range []string{
+		`{"event":"join","user":"craig","token":"staging","reason":"fix \"the\" build"}`,
+		`{"event":"failure","token":"staging","message":"\"make test\" failed in C:\\src"}`,
+	} {
+		_, jobs := postWebhook(handleMessage, http.MethodPost, body, sign(body))
+		if len(jobs) != 1 {
+			t.Fatal("Expected webhook to be accepted: ", body)
+		}
+		var err error
+		if qs, err = (<-jobs)(qs); err != nil {
+			t.Fatal("Unexpected error ", err)
+		}
+	}
+
+	if q := qs.Get("C1234").Get("staging"); len(q) != 1 || q[0].Reason != `fix "the" build` {
+		t.Fatal("Expected reason with quotes, got ", qs)
+	}
+	if len(notifications) != 2 || !strings.Contains(notifications[1].Message, `"make test" failed in C:\src`) {
+		t.Fatal("Expected failure message with quotes, got ", notifications)
+	}
+}